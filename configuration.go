@@ -183,6 +183,8 @@ type EntryPoint struct {
 	Redirect *Redirect
 	Auth     *types.Auth
 	Compress bool
+	IPFilter *types.IPFilter
+	Errors   map[string]*types.ErrorPage
 }
 
 // Redirect configures a redirection of an entry point to another, or to an URL
@@ -307,7 +309,46 @@ type Certificate struct {
 
 // Retry contains request retry config
 type Retry struct {
-	Attempts int `description:"Number of attempts"`
+	Attempts         int            `description:"Number of attempts"`
+	Methods          RetryMethods   `description:"Methods of the requests that can be retried (Default: GET,HEAD,OPTIONS,PUT,DELETE,TRACE)"`
+	InitialInterval  types.Duration `description:"Maximum wait before the first retry, doubled at each retry (Default: 100ms)"`
+	MaxInterval      types.Duration `description:"Maximum wait before a retry (Default: 1s)"`
+	BudgetRatio      float64        `description:"Ratio of retries to requests allowed for a backend over 10 seconds (Default: 0.2)"`
+	BudgetMinRetries int            `description:"Number of retries always allowed for a backend over 10 seconds (Default: 10)"`
+}
+
+// RetryMethods holds the methods of the requests that can be retried
+type RetryMethods []string
+
+// String is the method to format the flag's value, part of the flag.Value interface.
+// The String method's output will be used in diagnostics.
+func (methods *RetryMethods) String() string {
+	return strings.Join(*methods, ",")
+}
+
+// Set is the method to set the flag value, part of the flag.Value interface.
+// Set's argument is a string to be parsed to set the flag.
+// It's a comma-separated list, so we split it.
+func (methods *RetryMethods) Set(value string) error {
+	for _, method := range strings.Split(value, ",") {
+		*methods = append(*methods, strings.ToUpper(strings.TrimSpace(method)))
+	}
+	return nil
+}
+
+// Get return the methods
+func (methods *RetryMethods) Get() interface{} {
+	return RetryMethods(*methods)
+}
+
+// SetValue sets the methods with val
+func (methods *RetryMethods) SetValue(val interface{}) {
+	*methods = RetryMethods(val.(RetryMethods))
+}
+
+// Type is type of the struct
+func (methods *RetryMethods) Type() string {
+	return fmt.Sprint("retrymethods")
 }
 
 // NewTraefikDefaultPointersConfiguration creates a TraefikConfiguration with pointers default values
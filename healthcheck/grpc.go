@@ -0,0 +1,158 @@
+package healthcheck
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// grpcServing is the grpc.health.v1.HealthCheckResponse.ServingStatus of a healthy service
+const grpcServing = 1
+
+var grpcServingStatuses = map[uint64]string{
+	0: "UNKNOWN",
+	1: "SERVING",
+	2: "NOT_SERVING",
+	3: "SERVICE_UNKNOWN",
+}
+
+// checkGRPC returns an error if the server does not answer SERVING to a grpc.health.v1.Health/Check call.
+// The call is made with HTTP/2, without TLS unless the scheme of the checks is https.
+func (backend *BackendHealthCheck) checkGRPC(serverURL *url.URL) error {
+	checkURL := backend.checkURL(serverURL)
+	transport := backend.h2cTransport
+	if checkURL.Scheme == "https" {
+		transport = backend.h2Transport
+	}
+	checkURL.Path = "/grpc.health.v1.Health/Check"
+	req, err := http.NewRequest(http.MethodPost, checkURL.String(), bytes.NewReader(encodeGRPCHealthCheckRequest(backend.Service)))
+	if err != nil {
+		return err
+	}
+	for name, value := range backend.Headers {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("Te", "trailers")
+	if backend.Hostname != "" {
+		req.Host = backend.Hostname
+	}
+	client := &http.Client{Timeout: backend.Timeout, Transport: transport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unhealthy status %d", resp.StatusCode)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	// the grpc status is sent in the headers of the responses without message
+	status := resp.Trailer.Get("Grpc-Status")
+	message := resp.Trailer.Get("Grpc-Message")
+	if status == "" {
+		status = resp.Header.Get("Grpc-Status")
+		message = resp.Header.Get("Grpc-Message")
+	}
+	if status != "0" {
+		return fmt.Errorf("grpc status %s: %s", status, message)
+	}
+	servingStatus, err := decodeGRPCHealthCheckResponse(body)
+	if err != nil {
+		return err
+	}
+	if servingStatus != grpcServing {
+		name, ok := grpcServingStatuses[servingStatus]
+		if !ok {
+			name = strconv.FormatUint(servingStatus, 10)
+		}
+		return fmt.Errorf("grpc health status %s", name)
+	}
+	return nil
+}
+
+// encodeGRPCHealthCheckRequest returns the gRPC message of a grpc.health.v1.HealthCheckRequest
+func encodeGRPCHealthCheckRequest(service string) []byte {
+	var request []byte
+	if service != "" {
+		// field 1, length delimited
+		request = append(request, 0x0a)
+		request = appendUvarint(request, uint64(len(service)))
+		request = append(request, service...)
+	}
+	// not compressed, and the length of the request
+	message := make([]byte, 5, 5+len(request))
+	binary.BigEndian.PutUint32(message[1:], uint32(len(request)))
+	return append(message, request...)
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	buf := make([]byte, binary.MaxVarintLen64)
+	return append(b, buf[:binary.PutUvarint(buf, v)]...)
+}
+
+// decodeGRPCHealthCheckResponse returns the serving status of the gRPC message of a grpc.health.v1.HealthCheckResponse
+func decodeGRPCHealthCheckResponse(message []byte) (uint64, error) {
+	if len(message) < 5 {
+		return 0, errors.New("invalid grpc health check response")
+	}
+	if message[0] != 0 {
+		return 0, errors.New("compressed grpc health check response")
+	}
+	length := binary.BigEndian.Uint32(message[1:5])
+	if uint64(len(message)-5) < uint64(length) {
+		return 0, errors.New("truncated grpc health check response")
+	}
+	response := message[5 : 5+length]
+
+	// a missing status is UNKNOWN
+	var status uint64
+	for len(response) > 0 {
+		key, n := binary.Uvarint(response)
+		if n <= 0 {
+			return 0, errors.New("invalid grpc health check response")
+		}
+		response = response[n:]
+		field, wireType := key>>3, key&7
+		var size uint64
+		switch wireType {
+		case 0:
+			value, n := binary.Uvarint(response)
+			if n <= 0 {
+				return 0, errors.New("invalid grpc health check response")
+			}
+			response = response[n:]
+			if field == 1 {
+				status = value
+			}
+			continue
+		case 1:
+			size = 8
+		case 2:
+			value, n := binary.Uvarint(response)
+			if n <= 0 {
+				return 0, errors.New("invalid grpc health check response")
+			}
+			response = response[n:]
+			size = value
+		case 5:
+			size = 4
+		default:
+			return 0, fmt.Errorf("invalid wire type %d in grpc health check response", wireType)
+		}
+		// the other fields are skipped
+		if uint64(len(response)) < size {
+			return 0, errors.New("truncated grpc health check response")
+		}
+		response = response[size:]
+	}
+	return status, nil
+}
@@ -2,14 +2,62 @@ package healthcheck
 
 import (
 	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/containous/traefik/log"
 	"github.com/containous/traefik/safe"
+	"github.com/containous/traefik/types"
+	"github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
 	"github.com/vulcand/oxy/roundrobin"
+	"golang.org/x/net/http2"
+)
+
+const (
+	defaultInterval = 30 * time.Second
+	defaultTimeout  = 5 * time.Second
+
+	// TypeHTTP checks the servers with a GET request
+	TypeHTTP = "http"
+	// TypeTCP checks the servers with a TCP connection
+	TypeTCP = "tcp"
+	// TypeGRPC checks the servers with the gRPC health checking protocol
+	TypeGRPC = "grpc"
+
+	serverUpName                  = "traefik_backend_server_up"
+	serverConsecutiveFailuresName = "traefik_backend_server_consecutive_failures"
+
+	// ServerUp is the status of a server in the load balancer of its backend
+	ServerUp = "up"
+	// ServerDown is the status of a server removed from the load balancer of its backend by the health check
+	ServerDown = "down"
+)
+
+var (
+	serverUpGauge = prometheus.NewGaugeFrom(
+		stdprometheus.GaugeOpts{
+			Name: serverUpName,
+			Help: "Whether a server is up (1) or removed from its backend by the health check (0).",
+		},
+		[]string{"backend", "url"},
+	)
+	serverConsecutiveFailuresGauge = prometheus.NewGaugeFrom(
+		stdprometheus.GaugeOpts{
+			Name: serverConsecutiveFailuresName,
+			Help: "How many health checks of a server failed in a row.",
+		},
+		[]string{"backend", "url"},
+	)
 )
 
 var singleton *HealthCheck
@@ -23,11 +71,41 @@ func GetHealthCheck() *HealthCheck {
 	return singleton
 }
 
+// ServerHealth holds the health state of a server
+type ServerHealth struct {
+	Status              string    `json:"status"`
+	LastCheck           time.Time `json:"lastCheck"`
+	LastError           string    `json:"lastError,omitempty"`
+	ConsecutiveFailures int       `json:"consecutiveFailures"`
+	// successful checks in a row, counted towards Rise
+	consecutiveSuccesses int
+}
+
 // BackendHealthCheck HealthCheck configuration for a backend
 type BackendHealthCheck struct {
+	Type         string
 	URL          string
+	Service      string
+	Interval     time.Duration
+	Timeout      time.Duration
+	Headers      map[string]string
+	Hostname     string
+	Port         int
+	Scheme       string
+	Rise         int
+	Fall         int
 	DisabledURLs []*url.URL
-	lb           loadBalancer
+	statusRanges [][2]int
+	name         string
+	// configured weights of the servers, by URL, restored when they are up again
+	weights map[string]int
+	lock    sync.RWMutex
+	servers map[string]*ServerHealth
+	lb      loadBalancer
+	client  *http.Client
+	// HTTP/2 transports of the grpc checks, with and without TLS
+	h2Transport  *http2.Transport
+	h2cTransport *http2.Transport
 }
 
 var launch = false
@@ -35,6 +113,7 @@ var launch = false
 //HealthCheck struct
 type HealthCheck struct {
 	Backends map[string]*BackendHealthCheck
+	lock     sync.RWMutex
 	cancel   context.CancelFunc
 }
 
@@ -45,17 +124,111 @@ type loadBalancer interface {
 }
 
 func newHealthCheck() *HealthCheck {
-	return &HealthCheck{make(map[string]*BackendHealthCheck), nil}
+	return &HealthCheck{Backends: make(map[string]*BackendHealthCheck)}
+}
+
+// NewBackendHealthCheck Instantiate a new BackendHealthCheck of the servers of lb, given their configured weights by URL,
+// connecting to the https servers with the TLS configuration of the forwarder of the backend, the default one if nil
+func NewBackendHealthCheck(backendName string, config *types.HealthCheck, tlsConfig *tls.Config, lb loadBalancer, weights map[string]int) (*BackendHealthCheck, error) {
+	backend := &BackendHealthCheck{
+		Type:     config.Type,
+		URL:      config.URL,
+		Service:  config.Service,
+		Interval: time.Duration(config.Interval),
+		Timeout:  time.Duration(config.Timeout),
+		Headers:  config.Headers,
+		Hostname: config.Hostname,
+		Port:     config.Port,
+		Scheme:   config.Scheme,
+		Rise:     config.Rise,
+		Fall:     config.Fall,
+		name:     backendName,
+		weights:  weights,
+		servers:  make(map[string]*ServerHealth),
+		lb:       lb,
+	}
+	switch backend.Type {
+	case "":
+		backend.Type = TypeHTTP
+	case TypeHTTP, TypeTCP, TypeGRPC:
+	default:
+		return nil, fmt.Errorf("invalid health check type %s", backend.Type)
+	}
+	if backend.Interval <= 0 {
+		backend.Interval = defaultInterval
+	}
+	if backend.Timeout <= 0 {
+		backend.Timeout = defaultTimeout
+	}
+	if backend.Rise <= 0 {
+		backend.Rise = 1
+	}
+	if backend.Fall <= 0 {
+		backend.Fall = 1
+	}
+	if backend.Port < 0 || backend.Port > 65535 {
+		return nil, fmt.Errorf("invalid health check port %d", backend.Port)
+	}
+	for _, status := range config.Status {
+		statusRange, err := parseStatusRange(status)
+		if err != nil {
+			return nil, err
+		}
+		backend.statusRanges = append(backend.statusRanges, statusRange)
+	}
+	if len(backend.statusRanges) == 0 {
+		backend.statusRanges = [][2]int{{http.StatusOK, http.StatusOK}}
+	}
+	for _, serverURL := range lb.Servers() {
+		backend.servers[serverURL.String()] = &ServerHealth{Status: ServerUp}
+		serverUpGauge.With("backend", backendName, "url", serverURL.String()).Set(1)
+	}
+	backend.client = &http.Client{
+		Timeout: backend.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	if tlsConfig != nil {
+		backend.client.Transport = &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		}
+	}
+	if backend.Type == TypeGRPC {
+		backend.h2Transport = &http2.Transport{TLSClientConfig: tlsConfig}
+		backend.h2cTransport = &http2.Transport{
+			AllowHTTP: true,
+			DialTLS: func(network, addr string, cfg *tls.Config) (net.Conn, error) {
+				return net.DialTimeout(network, addr, backend.Timeout)
+			},
+		}
+	}
+	return backend, nil
 }
 
-// NewBackendHealthCheck Instantiate a new BackendHealthCheck
-func NewBackendHealthCheck(URL string, lb loadBalancer) *BackendHealthCheck {
-	return &BackendHealthCheck{URL, nil, lb}
+// parseStatusRange parses a status code, e.g. 200, or a range of status codes, e.g. 200-399
+func parseStatusRange(status string) ([2]int, error) {
+	bounds := strings.SplitN(status, "-", 2)
+	from, err := strconv.Atoi(strings.TrimSpace(bounds[0]))
+	if err != nil {
+		return [2]int{}, fmt.Errorf("invalid health check status %s", status)
+	}
+	to := from
+	if len(bounds) == 2 {
+		to, err = strconv.Atoi(strings.TrimSpace(bounds[1]))
+		if err != nil || to < from {
+			return [2]int{}, fmt.Errorf("invalid health check status range %s", status)
+		}
+	}
+	return [2]int{from, to}, nil
 }
 
 //SetBackendsConfiguration set backends configuration
 func (hc *HealthCheck) SetBackendsConfiguration(parentCtx context.Context, backends map[string]*BackendHealthCheck) {
+	hc.lock.Lock()
 	hc.Backends = backends
+	hc.lock.Unlock()
 	if hc.cancel != nil {
 		hc.cancel()
 	}
@@ -64,53 +237,204 @@ func (hc *HealthCheck) SetBackendsConfiguration(parentCtx context.Context, backe
 	hc.execute(ctx)
 }
 
+// GetServerHealth returns the health state of a server of a backend, or nil if the backend has no health check
+func (hc *HealthCheck) GetServerHealth(backendID string, serverURL string) *ServerHealth {
+	hc.lock.RLock()
+	backend, ok := hc.Backends[backendID]
+	hc.lock.RUnlock()
+	if !ok {
+		return nil
+	}
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return nil
+	}
+	return backend.ServerHealth(u)
+}
+
+// ServerHealth returns the health state of a server, or nil if it is unknown
+func (backend *BackendHealthCheck) ServerHealth(serverURL *url.URL) *ServerHealth {
+	backend.lock.RLock()
+	defer backend.lock.RUnlock()
+	health, ok := backend.servers[serverURL.String()]
+	if !ok {
+		return nil
+	}
+	healthCopy := *health
+	return &healthCopy
+}
+
 func (hc *HealthCheck) execute(ctx context.Context) {
 	for backendID, backend := range hc.Backends {
 		currentBackend := backend
 		currentBackendID := backendID
 		safe.Go(func() {
+			ticker := time.NewTicker(currentBackend.Interval)
+			defer ticker.Stop()
 			for {
-				ticker := time.NewTicker(time.Second * 30)
 				select {
 				case <-ctx.Done():
 					log.Debugf("Stopping all current Healthcheck goroutines")
 					return
 				case <-ticker.C:
 					log.Debugf("Refreshing Healthcheck for currentBackend %s ", currentBackendID)
-					enabledURLs := currentBackend.lb.Servers()
-					var newDisabledURLs []*url.URL
-					for _, url := range currentBackend.DisabledURLs {
-						if checkHealth(url, currentBackend.URL) {
-							log.Debugf("HealthCheck is up [%s]: Upsert in server list", url.String())
-							currentBackend.lb.UpsertServer(url, roundrobin.Weight(1))
-						} else {
-							newDisabledURLs = append(newDisabledURLs, url)
-						}
-					}
-					currentBackend.DisabledURLs = newDisabledURLs
-
-					for _, url := range enabledURLs {
-						if !checkHealth(url, currentBackend.URL) {
-							log.Debugf("HealthCheck has failed [%s]: Remove from server list", url.String())
-							currentBackend.lb.RemoveServer(url)
-							currentBackend.DisabledURLs = append(currentBackend.DisabledURLs, url)
-						}
-					}
-
+					currentBackend.checkServers()
 				}
 			}
 		})
 	}
 }
 
-func checkHealth(serverURL *url.URL, checkURL string) bool {
-	timeout := time.Duration(5 * time.Second)
-	client := http.Client{
-		Timeout: timeout,
+// checkServers checks every server of the backend once, removing from the load balancer the servers failing
+// Fall checks in a row, and upserting with their configured weight the removed servers passing Rise checks in a row
+func (backend *BackendHealthCheck) checkServers() {
+	enabledURLs := backend.lb.Servers()
+	var newDisabledURLs []*url.URL
+	for _, url := range backend.DisabledURLs {
+		health := backend.check(url)
+		if health.consecutiveSuccesses < backend.Rise {
+			newDisabledURLs = append(newDisabledURLs, url)
+			continue
+		}
+		weight, ok := backend.weights[url.String()]
+		if !ok {
+			weight = 1
+		}
+		log.Infof("Server %s of backend %s passed %d health checks: adding it back to the load balancer with weight %d", url, backend.name, health.consecutiveSuccesses, weight)
+		backend.lb.UpsertServer(url, roundrobin.Weight(weight))
+		backend.setStatus(url, ServerUp)
+	}
+	backend.DisabledURLs = newDisabledURLs
+
+	for _, url := range enabledURLs {
+		health := backend.check(url)
+		if health.ConsecutiveFailures < backend.Fall {
+			continue
+		}
+		log.Warnf("Server %s of backend %s failed %d health checks: removing it from the load balancer: %s", url, backend.name, health.ConsecutiveFailures, health.LastError)
+		backend.lb.RemoveServer(url)
+		backend.DisabledURLs = append(backend.DisabledURLs, url)
+		backend.setStatus(url, ServerDown)
+	}
+}
+
+// check checks the health of a server and returns its updated state
+func (backend *BackendHealthCheck) check(serverURL *url.URL) ServerHealth {
+	err := backend.checkHealth(serverURL)
+
+	backend.lock.Lock()
+	defer backend.lock.Unlock()
+	health, ok := backend.servers[serverURL.String()]
+	if !ok {
+		health = &ServerHealth{Status: ServerUp}
+		backend.servers[serverURL.String()] = health
+	}
+	health.LastCheck = time.Now()
+	if err != nil {
+		health.LastError = err.Error()
+		health.ConsecutiveFailures++
+		health.consecutiveSuccesses = 0
+	} else {
+		health.LastError = ""
+		health.ConsecutiveFailures = 0
+		health.consecutiveSuccesses++
+	}
+	serverConsecutiveFailuresGauge.With("backend", backend.name, "url", serverURL.String()).Set(float64(health.ConsecutiveFailures))
+	return *health
+}
+
+// setStatus records a new status of a server
+func (backend *BackendHealthCheck) setStatus(serverURL *url.URL, status string) {
+	backend.lock.Lock()
+	backend.servers[serverURL.String()].Status = status
+	backend.lock.Unlock()
+	if status == ServerUp {
+		serverUpGauge.With("backend", backend.name, "url", serverURL.String()).Set(1)
+	} else {
+		serverUpGauge.With("backend", backend.name, "url", serverURL.String()).Set(0)
+	}
+}
+
+// checkURL returns the URL of a server with the scheme and port of the checks
+func (backend *BackendHealthCheck) checkURL(serverURL *url.URL) *url.URL {
+	checkURL := *serverURL
+	if backend.Scheme != "" {
+		checkURL.Scheme = backend.Scheme
+	}
+	if backend.Port != 0 {
+		host, _, err := net.SplitHostPort(checkURL.Host)
+		if err != nil {
+			host = checkURL.Host
+		}
+		checkURL.Host = net.JoinHostPort(strings.Trim(host, "[]"), strconv.Itoa(backend.Port))
+	}
+	return &checkURL
+}
+
+// newRequest returns the check request of a server
+func (backend *BackendHealthCheck) newRequest(serverURL *url.URL) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodGet, backend.checkURL(serverURL).String()+backend.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range backend.Headers {
+		req.Header.Set(name, value)
+	}
+	if backend.Hostname != "" {
+		req.Host = backend.Hostname
+	}
+	return req, nil
+}
+
+// checkHealth returns an error if the server is unhealthy
+func (backend *BackendHealthCheck) checkHealth(serverURL *url.URL) error {
+	switch backend.Type {
+	case TypeTCP:
+		return backend.checkTCP(serverURL)
+	case TypeGRPC:
+		return backend.checkGRPC(serverURL)
+	default:
+		return backend.checkHTTP(serverURL)
+	}
+}
+
+// checkTCP returns an error if a connection to the server cannot be opened
+func (backend *BackendHealthCheck) checkTCP(serverURL *url.URL) error {
+	conn, err := net.DialTimeout("tcp", hostPort(backend.checkURL(serverURL)), backend.Timeout)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// hostPort returns the host and port of a URL, the port being the default one of its scheme if it has none
+func hostPort(u *url.URL) string {
+	if _, _, err := net.SplitHostPort(u.Host); err == nil {
+		return u.Host
+	}
+	if u.Scheme == "https" {
+		return net.JoinHostPort(strings.Trim(u.Host, "[]"), "443")
+	}
+	return net.JoinHostPort(strings.Trim(u.Host, "[]"), "80")
+}
+
+// checkHTTP returns an error if the server does not answer a healthy status to the check request
+func (backend *BackendHealthCheck) checkHTTP(serverURL *url.URL) error {
+	req, err := backend.newRequest(serverURL)
+	if err != nil {
+		return err
+	}
+	resp, err := backend.client.Do(req)
+	if err != nil {
+		return err
 	}
-	resp, err := client.Get(serverURL.String() + checkURL)
-	if err != nil || resp.StatusCode != 200 {
-		return false
+	// the body is drained so that the connection can be reused by the next check
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+	for _, statusRange := range backend.statusRanges {
+		if resp.StatusCode >= statusRange[0] && resp.StatusCode <= statusRange[1] {
+			return nil
+		}
 	}
-	return true
+	return fmt.Errorf("unhealthy status %d", resp.StatusCode)
 }
@@ -0,0 +1,311 @@
+package healthcheck
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/containous/traefik/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/vulcand/oxy/roundrobin"
+	"golang.org/x/net/http2"
+)
+
+type testLoadBalancer struct {
+	servers []*url.URL
+}
+
+func (lb *testLoadBalancer) RemoveServer(u *url.URL) error {
+	for i, server := range lb.servers {
+		if server == u {
+			lb.servers = append(lb.servers[:i], lb.servers[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (lb *testLoadBalancer) UpsertServer(u *url.URL, options ...roundrobin.ServerOption) error {
+	lb.servers = append(lb.servers, u)
+	return nil
+}
+
+func (lb *testLoadBalancer) Servers() []*url.URL {
+	return append([]*url.URL{}, lb.servers...)
+}
+
+func TestCheckServersRiseFall(t *testing.T) {
+	status := http.StatusOK
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer ts.Close()
+	serverURL, _ := url.Parse(ts.URL)
+	lb := &testLoadBalancer{servers: []*url.URL{serverURL}}
+	backend, err := NewBackendHealthCheck("backend", &types.HealthCheck{URL: "/health", Rise: 2, Fall: 3}, nil, lb, nil)
+	assert.NoError(t, err, "there should be no error")
+
+	status = http.StatusServiceUnavailable
+	for i := 1; i <= 3; i++ {
+		backend.checkServers()
+		if i < 3 {
+			assert.Len(t, lb.servers, 1, "the server should stay up after %d failed checks", i)
+		}
+	}
+	assert.Len(t, lb.servers, 0, "the server should be down after 3 failed checks")
+	assert.Equal(t, []*url.URL{serverURL}, backend.DisabledURLs)
+
+	status = http.StatusOK
+	backend.checkServers()
+	assert.Len(t, lb.servers, 0, "the server should stay down after 1 successful check")
+	backend.checkServers()
+	assert.Equal(t, []*url.URL{serverURL}, lb.servers, "the server should be up after 2 successful checks")
+	assert.Empty(t, backend.DisabledURLs)
+}
+
+func TestCheckServersWeights(t *testing.T) {
+	status := http.StatusOK
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer failing.Close()
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer healthy.Close()
+	failingURL, _ := url.Parse(failing.URL)
+	healthyURL, _ := url.Parse(healthy.URL)
+	lb, _ := roundrobin.New(http.NotFoundHandler())
+	lb.UpsertServer(failingURL, roundrobin.Weight(10))
+	lb.UpsertServer(healthyURL, roundrobin.Weight(1))
+	backend, err := NewBackendHealthCheck("backend", &types.HealthCheck{}, nil, lb, map[string]int{failingURL.String(): 10, healthyURL.String(): 1})
+	assert.NoError(t, err, "there should be no error")
+
+	status = http.StatusInternalServerError
+	backend.checkServers()
+	assert.Equal(t, []*url.URL{healthyURL}, lb.Servers())
+	status = http.StatusOK
+	backend.checkServers()
+
+	picks := map[string]int{}
+	for i := 0; i < 11; i++ {
+		next, err := lb.NextServer()
+		assert.NoError(t, err)
+		picks[next.String()]++
+	}
+	assert.Equal(t, map[string]int{failingURL.String(): 10, healthyURL.String(): 1}, picks, "the server should get its weight back")
+}
+
+func TestServerHealth(t *testing.T) {
+	status := http.StatusOK
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer ts.Close()
+	serverURL, _ := url.Parse(ts.URL)
+	lb := &testLoadBalancer{servers: []*url.URL{serverURL}}
+	backend, err := NewBackendHealthCheck("backend", &types.HealthCheck{Fall: 2}, nil, lb, nil)
+	assert.NoError(t, err, "there should be no error")
+	hc := newHealthCheck()
+	hc.Backends = map[string]*BackendHealthCheck{"backend": backend}
+
+	health := hc.GetServerHealth("backend", ts.URL)
+	assert.Equal(t, &ServerHealth{Status: ServerUp}, health, "the server should be up before the first check")
+
+	status = http.StatusBadGateway
+	backend.checkServers()
+	health = hc.GetServerHealth("backend", ts.URL)
+	assert.Equal(t, ServerUp, health.Status)
+	assert.Equal(t, 1, health.ConsecutiveFailures)
+	assert.Equal(t, "unhealthy status 502", health.LastError)
+	assert.False(t, health.LastCheck.IsZero())
+
+	backend.checkServers()
+	assert.Equal(t, ServerDown, hc.GetServerHealth("backend", ts.URL).Status)
+
+	status = http.StatusOK
+	backend.checkServers()
+	assert.Equal(t, &ServerHealth{Status: ServerUp, LastCheck: hc.GetServerHealth("backend", ts.URL).LastCheck, consecutiveSuccesses: 1}, hc.GetServerHealth("backend", ts.URL))
+
+	assert.Nil(t, hc.GetServerHealth("other", ts.URL))
+}
+
+func TestCheckServersStreakReset(t *testing.T) {
+	statuses := []int{http.StatusInternalServerError, http.StatusOK, http.StatusInternalServerError}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statuses[0])
+		statuses = statuses[1:]
+	}))
+	defer ts.Close()
+	serverURL, _ := url.Parse(ts.URL)
+	lb := &testLoadBalancer{servers: []*url.URL{serverURL}}
+	backend, err := NewBackendHealthCheck("backend", &types.HealthCheck{Fall: 2}, nil, lb, nil)
+	assert.NoError(t, err, "there should be no error")
+
+	for i := 0; i < 3; i++ {
+		backend.checkServers()
+	}
+	assert.Len(t, lb.servers, 1, "a successful check should reset the failed checks")
+}
+
+func TestCheckHealthRequest(t *testing.T) {
+	var request *http.Request
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request = r
+		http.Redirect(w, r, "/elsewhere", http.StatusFound)
+	}))
+	defer ts.Close()
+	checkURL, _ := url.Parse(ts.URL)
+	_, port, _ := net.SplitHostPort(checkURL.Host)
+	serverURL, _ := url.Parse("http://127.0.0.1:1")
+
+	backend, err := NewBackendHealthCheck("backend", &types.HealthCheck{
+		URL:      "/health?full=1",
+		Status:   []string{"200-399"},
+		Headers:  map[string]string{"X-Check": "traefik"},
+		Hostname: "app.example.com",
+		Port:     mustAtoi(t, port),
+	}, nil, &testLoadBalancer{}, nil)
+	assert.NoError(t, err, "there should be no error")
+	assert.NoError(t, backend.checkHealth(serverURL), "a 302 should be healthy, without following the redirect")
+	assert.Equal(t, "/health?full=1", request.URL.RequestURI())
+	assert.Equal(t, "app.example.com", request.Host)
+	assert.Equal(t, "traefik", request.Header.Get("X-Check"))
+
+	backend, err = NewBackendHealthCheck("backend", &types.HealthCheck{}, nil, &testLoadBalancer{}, nil)
+	assert.NoError(t, err, "there should be no error")
+	assert.EqualError(t, backend.checkHealth(checkURL), "unhealthy status 302", "only a 200 should be healthy by default")
+}
+
+func TestNewBackendHealthCheckInvalidConfig(t *testing.T) {
+	for _, config := range []*types.HealthCheck{
+		{Status: []string{"2xx"}},
+		{Status: []string{"399-200"}},
+		{Port: 70000},
+		{Type: "udp"},
+	} {
+		_, err := NewBackendHealthCheck("backend", config, nil, &testLoadBalancer{}, nil)
+		assert.Error(t, err, "%+v should be invalid", config)
+	}
+}
+
+func TestCheckHealthTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	serverURL, _ := url.Parse("http://" + listener.Addr().String())
+	backend, err := NewBackendHealthCheck("backend", &types.HealthCheck{Type: TypeTCP}, nil, &testLoadBalancer{}, nil)
+	assert.NoError(t, err, "there should be no error")
+
+	assert.NoError(t, backend.checkHealth(serverURL), "a server accepting connections should be healthy")
+	listener.Close()
+	assert.Error(t, backend.checkHealth(serverURL), "a server refusing connections should be unhealthy")
+}
+
+func TestCheckHealthGRPC(t *testing.T) {
+	var services []string
+	servingStatuses := map[string]byte{"": 1, "app.Foo": 2}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		// the length of the request, and its service field if any
+		service := ""
+		if len(body) > 7 {
+			service = string(body[7:])
+		}
+		services = append(services, service)
+		w.Header().Set("Content-Type", "application/grpc")
+		servingStatus, ok := servingStatuses[service]
+		if !ok {
+			// a response without message
+			w.Header().Set("Grpc-Status", "5")
+			w.Header().Set("Grpc-Message", "unknown service")
+			return
+		}
+		w.Header().Set("Trailer", "Grpc-Status")
+		w.Write([]byte{0, 0, 0, 0, 2, 0x08, servingStatus})
+		w.Header().Set("Grpc-Status", "0")
+	})
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go (&http2.Server{}).ServeConn(conn, &http2.ServeConnOpts{Handler: handler})
+		}
+	}()
+	serverURL, _ := url.Parse("http://" + listener.Addr().String())
+
+	for service, expected := range map[string]string{
+		"":        "",
+		"app.Foo": "grpc health status NOT_SERVING",
+		"app.Bar": "grpc status 5: unknown service",
+	} {
+		backend, err := NewBackendHealthCheck("backend", &types.HealthCheck{Type: TypeGRPC, Service: service}, nil, &testLoadBalancer{}, nil)
+		assert.NoError(t, err, "there should be no error")
+		err = backend.checkHealth(serverURL)
+		if expected == "" {
+			assert.NoError(t, err, "the server should be serving")
+		} else {
+			assert.EqualError(t, err, expected)
+		}
+	}
+	assert.Len(t, services, 3)
+}
+
+func TestCheckHealthGRPCTLS(t *testing.T) {
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/grpc")
+		w.Header().Set("Trailer", "Grpc-Status")
+		w.Write([]byte{0, 0, 0, 0, 2, 0x08, 1})
+		w.Header().Set("Grpc-Status", "0")
+	}))
+	http2.ConfigureServer(ts.Config, nil)
+	ts.TLS = ts.Config.TLSConfig
+	ts.StartTLS()
+	defer ts.Close()
+	serverURL, _ := url.Parse(ts.URL)
+
+	backend, err := NewBackendHealthCheck("backend", &types.HealthCheck{Type: TypeGRPC}, nil, &testLoadBalancer{}, nil)
+	assert.NoError(t, err, "there should be no error")
+	assert.Error(t, backend.checkHealth(serverURL), "the certificate of the server should be verified by default")
+
+	backend, err = NewBackendHealthCheck("backend", &types.HealthCheck{Type: TypeGRPC}, &tls.Config{InsecureSkipVerify: true}, &testLoadBalancer{}, nil)
+	assert.NoError(t, err, "there should be no error")
+	assert.NoError(t, backend.checkHealth(serverURL), "the TLS configuration of the backend should be used")
+}
+
+func TestDecodeGRPCHealthCheckResponse(t *testing.T) {
+	cases := []struct {
+		message  []byte
+		expected uint64
+		err      bool
+	}{
+		{message: []byte{0, 0, 0, 0, 0}, expected: 0},
+		{message: []byte{0, 0, 0, 0, 2, 0x08, 1}, expected: 1},
+		// an unknown field before the status
+		{message: []byte{0, 0, 0, 0, 5, 0x12, 1, 'a', 0x08, 2}, expected: 2},
+		{message: []byte{0, 0, 0, 0, 3, 0x08, 1}, err: true},
+		{message: []byte{1, 0, 0, 0, 2, 0x08, 1}, err: true},
+	}
+	for _, c := range cases {
+		status, err := decodeGRPCHealthCheckResponse(c.message)
+		if c.err {
+			assert.Error(t, err, "%v should be invalid", c.message)
+		} else {
+			assert.NoError(t, err, "%v should be valid", c.message)
+			assert.Equal(t, c.expected, status)
+		}
+	}
+}
+
+func mustAtoi(t *testing.T, s string) int {
+	n, err := strconv.Atoi(s)
+	assert.NoError(t, err)
+	return n
+}
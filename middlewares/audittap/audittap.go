@@ -1,6 +1,7 @@
 package audittap
 
 import (
+	"github.com/containous/traefik/middlewares"
 	"github.com/containous/traefik/types"
 	"net/http"
 	"time"
@@ -16,6 +17,7 @@ type RequestSummary struct {
 	Path       string                 `json:"path"`
 	Query      string                 `json:"query"`
 	RemoteAddr string                 `json:"remoteAddr"`
+	RequestID  string                 `json:"requestID,omitempty"`
 	Header     map[string]interface{} `json:"header"` // contains strings or string slices
 	BeganAt    time.Time              `json:"beganAt"`
 }
@@ -120,6 +122,7 @@ func (s *AuditTap) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.
 		Path:       r.URL.Path,
 		Query:      r.URL.RawQuery,
 		RemoteAddr: r.RemoteAddr,
+		RequestID:  middlewares.GetRequestID(r),
 		Header:     flattenHeaders(r.Header),
 		BeganAt:    clock.Now(),
 	}
@@ -42,6 +42,7 @@ func TestAuditTap_noop(t *testing.T) {
 				"/a/b/c",
 				"d=1&e=2",
 				"101.102.103.104:1234",
+				"",
 				map[string]interface{}{"requestId": "R123", "sessionId": "S123"},
 				clock.Now(),
 			},
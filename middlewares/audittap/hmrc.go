@@ -57,7 +57,7 @@ func newTags(summary Summary) Tags {
 		ClientPort:       "-",
 		Path:             summary.Request.Path,
 		SessionID:        textOrDash(summary.Request.Header["clientIP"]),
-		RequestID:        textOrDash(summary.Request.Header["clientIP"]),
+		RequestID:        textOrDash(summary.Request.RequestID),
 		AkamaiReputation: textOrDash(summary.Request.Header["clientIP"]),
 		TransactionName:  "",
 	}
@@ -1,6 +1,7 @@
 package middlewares
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strings"
@@ -11,10 +12,13 @@ import (
 	"github.com/containous/traefik/types"
 )
 
-// Authenticator is a middleware that provides HTTP basic and digest authentication
+type authUserKey struct{}
+
+// Authenticator is a middleware that provides HTTP basic, digest, forward and JWT authentication
 type Authenticator struct {
-	handler negroni.Handler
-	users   map[string]string
+	handler   negroni.Handler
+	users     map[string]string
+	usersFile *usersFile
 }
 
 // NewAuthenticator builds a new Autenticator given a config
@@ -29,6 +33,12 @@ func NewAuthenticator(authConfig *types.Auth) (*Authenticator, error) {
 		if err != nil {
 			return nil, err
 		}
+		if authConfig.Basic.UsersFile != "" {
+			authenticator.usersFile, err = watchUsersFile("basic", authConfig.Basic.UsersFile, parserBasicUsers)
+			if err != nil {
+				return nil, err
+			}
+		}
 		basicAuth := auth.NewBasicAuthenticator("traefik", authenticator.secretBasic)
 		authenticator.handler = negroni.HandlerFunc(func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
 			if username := basicAuth.CheckAuth(r); username == "" {
@@ -39,7 +49,7 @@ func NewAuthenticator(authConfig *types.Auth) (*Authenticator, error) {
 				if authConfig.HeaderField != "" {
 					r.Header[authConfig.HeaderField] = []string{username}
 				}
-				next.ServeHTTP(w, r)
+				next.ServeHTTP(w, withAuthUser(r, username))
 			}
 		})
 	} else if authConfig.Digest != nil {
@@ -47,6 +57,12 @@ func NewAuthenticator(authConfig *types.Auth) (*Authenticator, error) {
 		if err != nil {
 			return nil, err
 		}
+		if authConfig.Digest.UsersFile != "" {
+			authenticator.usersFile, err = watchUsersFile("digest", authConfig.Digest.UsersFile, parserDigestUsers)
+			if err != nil {
+				return nil, err
+			}
+		}
 		digestAuth := auth.NewDigestAuthenticator("traefik", authenticator.secretDigest)
 		authenticator.handler = negroni.HandlerFunc(func(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
 			if username, _ := digestAuth.CheckAuth(r); username == "" {
@@ -57,13 +73,35 @@ func NewAuthenticator(authConfig *types.Auth) (*Authenticator, error) {
 				if authConfig.HeaderField != "" {
 					r.Header[authConfig.HeaderField] = []string{username}
 				}
-				next.ServeHTTP(w, r)
+				next.ServeHTTP(w, withAuthUser(r, username))
 			}
 		})
+	} else if authConfig.Forward != nil {
+		authenticator.handler, err = newForwardAuth(authConfig.Forward)
+		if err != nil {
+			return nil, err
+		}
+	} else if authConfig.JWT != nil {
+		authenticator.handler, err = newJWTAuth(authConfig.JWT, authConfig.HeaderField)
+		if err != nil {
+			return nil, err
+		}
 	}
 	return &authenticator, nil
 }
 
+// GetAuthUser returns the name of the user authenticated for the request, if any.
+func GetAuthUser(r *http.Request) string {
+	if username, ok := r.Context().Value(authUserKey{}).(string); ok {
+		return username
+	}
+	return ""
+}
+
+func withAuthUser(r *http.Request, username string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), authUserKey{}, username))
+}
+
 func parserBasicUsers(users types.Users) (map[string]string, error) {
 	userMap := make(map[string]string)
 	for _, user := range users {
@@ -89,7 +127,7 @@ func parserDigestUsers(users types.Users) (map[string]string, error) {
 }
 
 func (a *Authenticator) secretBasic(user, realm string) string {
-	if secret, ok := a.users[user]; ok {
+	if secret, ok := a.secret(user); ok {
 		return secret
 	}
 	log.Debugf("User not found: %s", user)
@@ -97,13 +135,31 @@ func (a *Authenticator) secretBasic(user, realm string) string {
 }
 
 func (a *Authenticator) secretDigest(user, realm string) string {
-	if secret, ok := a.users[user+":"+realm]; ok {
+	if secret, ok := a.secret(user + ":" + realm); ok {
 		return secret
 	}
 	log.Debugf("User not found: %s:%s", user, realm)
 	return ""
 }
 
+// secret returns the secret of a user, the users file taking precedence over the users of the configuration
+func (a *Authenticator) secret(user string) (string, bool) {
+	if a.usersFile != nil {
+		if secret, ok := a.usersFile.get(user); ok {
+			return secret, true
+		}
+	}
+	secret, ok := a.users[user]
+	return secret, ok
+}
+
+// Close releases the users file of the authenticator, once it is not used anymore
+func (a *Authenticator) Close() {
+	if a.usersFile != nil {
+		a.usersFile.release()
+	}
+}
+
 func (a *Authenticator) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
 	a.handler.ServeHTTP(rw, r, next)
 }
@@ -5,7 +5,10 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/codegangsta/negroni"
 	"github.com/containous/traefik/types"
@@ -132,3 +135,117 @@ func TestBasicAuthUserHeader(t *testing.T) {
 	assert.NoError(t, err, "there should be no error")
 	assert.Equal(t, "traefik\n", string(body), "they should be equal")
 }
+
+func basicAuthStatus(t *testing.T, handler http.Handler, user, password string) int {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.SetBasicAuth(user, password)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	return recorder.Code
+}
+
+func TestBasicAuthUsersFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "traefik-users")
+	assert.NoError(t, err, "there should be no error")
+	defer os.RemoveAll(dir)
+	usersFile := filepath.Join(dir, ".htpasswd")
+	err = ioutil.WriteFile(usersFile, []byte(`# htpasswd -B, -s and -m
+bcrypt:$2y$05$18aVFJzUGBIwxw2.o.njPO8YAweEUeFbcheDmhJoMUhJIYKWtOGiS
+sha:{SHA}z0jT3TdveclVlHs5WCpg5cPeIe8=
+
+apr1:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/
+`), 0600)
+	assert.NoError(t, err, "there should be no error")
+
+	authMiddleware, err := NewAuthenticator(&types.Auth{
+		Basic: &types.Basic{
+			Users:     []string{"inline:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/"},
+			UsersFile: usersFile,
+		},
+	})
+	assert.NoError(t, err, "there should be no error")
+	n := negroni.New(authMiddleware)
+	n.UseHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	assert.Equal(t, http.StatusOK, basicAuthStatus(t, n, "bcrypt", "bcryptpass"), "bcrypt password should be accepted")
+	assert.Equal(t, http.StatusOK, basicAuthStatus(t, n, "sha", "shapass"), "SHA password should be accepted")
+	assert.Equal(t, http.StatusOK, basicAuthStatus(t, n, "apr1", "test"), "APR1 password should be accepted")
+	assert.Equal(t, http.StatusOK, basicAuthStatus(t, n, "inline", "test"), "users of the configuration should be kept")
+	assert.Equal(t, http.StatusUnauthorized, basicAuthStatus(t, n, "bcrypt", "wrong"), "wrong password should be rejected")
+
+	// an invalid file is not loaded
+	err = ioutil.WriteFile(usersFile, []byte("invalid\n"), 0600)
+	assert.NoError(t, err, "there should be no error")
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, http.StatusOK, basicAuthStatus(t, n, "sha", "shapass"), "previous users should be kept")
+
+	// the file is replaced, as editors do
+	tmpFile := filepath.Join(dir, "htpasswd.tmp")
+	err = ioutil.WriteFile(tmpFile, []byte("sha:{SHA}z0jT3TdveclVlHs5WCpg5cPeIe8=\n"), 0600)
+	assert.NoError(t, err, "there should be no error")
+	assert.NoError(t, os.Rename(tmpFile, usersFile), "there should be no error")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for basicAuthStatus(t, n, "apr1", "test") == http.StatusOK && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, http.StatusUnauthorized, basicAuthStatus(t, n, "apr1", "test"), "removed user should be rejected")
+	assert.Equal(t, http.StatusOK, basicAuthStatus(t, n, "sha", "shapass"), "remaining user should be accepted")
+
+	// authenticators created by a configuration reload share the watched file
+	reloaded, err := NewAuthenticator(&types.Auth{Basic: &types.Basic{UsersFile: usersFile}})
+	assert.NoError(t, err, "there should be no error")
+	assert.Equal(t, authMiddleware.usersFile, reloaded.usersFile, "the users file should be shared")
+
+	// the file is watched until the last authenticator using it is closed
+	key := reloaded.usersFile.key
+	authMiddleware.Close()
+	usersFilesLock.Lock()
+	_, watched := usersFiles[key]
+	usersFilesLock.Unlock()
+	assert.True(t, watched, "the users file should still be watched")
+	reloaded.Close()
+	usersFilesLock.Lock()
+	_, watched = usersFiles[key]
+	usersFilesLock.Unlock()
+	assert.False(t, watched, "the users file should not be watched anymore")
+}
+
+func TestDigestAuthUsersFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "traefik-users")
+	assert.NoError(t, err, "there should be no error")
+	defer os.RemoveAll(dir)
+	usersFile := filepath.Join(dir, ".htdigest")
+	err = ioutil.WriteFile(usersFile, []byte("test:traefik:a2688e031edb4be6a3797f3882655c05\n"), 0600)
+	assert.NoError(t, err, "there should be no error")
+
+	authMiddleware, err := NewAuthenticator(&types.Auth{
+		Digest: &types.Digest{
+			UsersFile: usersFile,
+		},
+	})
+	assert.NoError(t, err, "there should be no error")
+	assert.Equal(t, "a2688e031edb4be6a3797f3882655c05", authMiddleware.secretDigest("test", "traefik"), "they should be equal")
+	assert.Equal(t, "", authMiddleware.secretDigest("test", "other"), "they should be equal")
+}
+
+func TestAuthUsersFileFail(t *testing.T) {
+	_, err := NewAuthenticator(&types.Auth{
+		Basic: &types.Basic{
+			UsersFile: "/does/not/exist",
+		},
+	})
+	assert.Error(t, err, "a missing users file should be rejected")
+
+	file, err := ioutil.TempFile("", "traefik-users")
+	assert.NoError(t, err, "there should be no error")
+	defer os.Remove(file.Name())
+	file.WriteString("test\n")
+	file.Close()
+	_, err = NewAuthenticator(&types.Auth{
+		Digest: &types.Digest{
+			UsersFile: file.Name(),
+		},
+	})
+	assert.Contains(t, err.Error(), "Error parsing Authenticator user", "should contains")
+}
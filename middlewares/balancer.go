@@ -0,0 +1,225 @@
+package middlewares
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+// balancerLatencyDecay is how long the latency of a server takes to decay to 1/e of its peak
+const balancerLatencyDecay = 10 * time.Second
+
+// Balancer is a load balancer sending each request to the server with the fewest requests in progress,
+// to the best of two random servers given their latency and requests in progress, or to the server of its key
+// on a consistent hash ring. Like the oxy round robin, it sends the requests to next with the URL of their server.
+type Balancer struct {
+	next    http.Handler
+	pick    func(b *Balancer, r *http.Request) *balancedServer
+	lock    sync.Mutex
+	servers []*balancedServer
+	// hash ring of the servers, rebuilt when they change, if the requests are balanced by hash
+	ring *hashRing
+	rand *rand.Rand
+	now  func() time.Time
+}
+
+type balancedServer struct {
+	url    *url.URL
+	weight int
+	// requests in progress
+	active int
+	// smooth weighted round robin among the servers with the fewest requests in progress
+	current int
+	// peak exponentially weighted moving average of the latency, in nanoseconds
+	latency       float64
+	latencyUpdate time.Time
+}
+
+// NewLeastConnBalancer returns a new Balancer sending each request to the server with the fewest requests in progress
+// relatively to its weight, the servers with as few requests getting them in weighted round robin
+func NewLeastConnBalancer(next http.Handler) *Balancer {
+	return newBalancer(next, leastConn)
+}
+
+// NewP2CBalancer returns a new Balancer sending each request to the best of two random servers,
+// given their latency, requests in progress and weight
+func NewP2CBalancer(next http.Handler) *Balancer {
+	return newBalancer(next, powerOfTwoChoices)
+}
+
+func newBalancer(next http.Handler, pick func(b *Balancer, r *http.Request) *balancedServer) *Balancer {
+	return &Balancer{
+		next: next,
+		pick: pick,
+		rand: rand.New(rand.NewSource(time.Now().UnixNano())),
+		now:  time.Now,
+	}
+}
+
+func (b *Balancer) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	newReq := *r
+	b.lock.Lock()
+	server := b.pick(b, &newReq)
+	if server == nil {
+		b.lock.Unlock()
+		http.Error(rw, "no servers in the pool", http.StatusServiceUnavailable)
+		return
+	}
+	server.active++
+	b.lock.Unlock()
+
+	newReq.URL = server.url
+	start := b.now()
+	// the request is done even if the next handler panics
+	defer func() {
+		b.done(server, b.now().Sub(start))
+	}()
+	b.next.ServeHTTP(rw, &newReq)
+}
+
+// server returns the server of a URL, or nil if there is none
+func (b *Balancer) server(u *url.URL) *balancedServer {
+	if u == nil {
+		return nil
+	}
+	for _, server := range b.servers {
+		if server.url.String() == u.String() {
+			return server
+		}
+	}
+	return nil
+}
+
+// done records the end of a request sent to a server
+func (b *Balancer) done(server *balancedServer, latency time.Duration) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	server.active--
+	now := b.now()
+	// the latency rises at once and decays slowly, so that a server slowing down gets less requests right away
+	if float64(latency) > server.latency {
+		server.latency = float64(latency)
+	} else {
+		decay := math.Exp(-float64(now.Sub(server.latencyUpdate)) / float64(balancerLatencyDecay))
+		server.latency = server.latency*decay + float64(latency)*(1-decay)
+	}
+	server.latencyUpdate = now
+}
+
+// leastConn returns the server with the fewest requests in progress relatively to its weight
+func leastConn(b *Balancer, r *http.Request) *balancedServer {
+	var least *balancedServer
+	for _, server := range b.servers {
+		if least == nil || server.active*least.weight < least.active*server.weight {
+			least = server
+		}
+	}
+	if least == nil {
+		return nil
+	}
+	// smooth weighted round robin among the servers with as few requests in progress
+	var chosen *balancedServer
+	total := 0
+	for _, server := range b.servers {
+		if server.active*least.weight != least.active*server.weight {
+			continue
+		}
+		server.current += server.weight
+		total += server.weight
+		if chosen == nil || server.current > chosen.current {
+			chosen = server
+		}
+	}
+	chosen.current -= total
+	return chosen
+}
+
+// powerOfTwoChoices returns the best of two random servers
+func powerOfTwoChoices(b *Balancer, r *http.Request) *balancedServer {
+	switch len(b.servers) {
+	case 0:
+		return nil
+	case 1:
+		return b.servers[0]
+	}
+	i := b.rand.Intn(len(b.servers))
+	j := b.rand.Intn(len(b.servers) - 1)
+	if j >= i {
+		j++
+	}
+	first, second := b.servers[i], b.servers[j]
+	if second.cost() < first.cost() {
+		return second
+	}
+	return first
+}
+
+// cost is the expected latency of a new request sent to the server, a server without latency yet being tried first
+func (s *balancedServer) cost() float64 {
+	return s.latency * float64(s.active+1) / float64(s.weight)
+}
+
+// Servers returns the URLs of the servers
+func (b *Balancer) Servers() []*url.URL {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	urls := make([]*url.URL, len(b.servers))
+	for i, server := range b.servers {
+		urls[i] = server.url
+	}
+	return urls
+}
+
+// UpsertServer adds a server, or updates its weight if it is already there
+func (b *Balancer) UpsertServer(u *url.URL, options ...roundrobin.ServerOption) error {
+	weight, err := serverWeight(u, options...)
+	if err != nil {
+		return err
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if server := b.server(u); server != nil {
+		server.weight = weight
+	} else {
+		b.servers = append(b.servers, &balancedServer{url: u, weight: weight})
+	}
+	if b.ring != nil {
+		b.ring.build(b.servers)
+	}
+	return nil
+}
+
+// RemoveServer removes a server
+func (b *Balancer) RemoveServer(u *url.URL) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for i, server := range b.servers {
+		if server.url.String() == u.String() {
+			b.servers = append(b.servers[:i], b.servers[i+1:]...)
+			if b.ring != nil {
+				b.ring.build(b.servers)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("server %s not found", u)
+}
+
+// serverWeight returns the weight set by the oxy options of a server, 1 by default
+func serverWeight(u *url.URL, options ...roundrobin.ServerOption) (int, error) {
+	rr, err := roundrobin.New(nil)
+	if err != nil {
+		return 0, err
+	}
+	if err := rr.UpsertServer(u, options...); err != nil {
+		return 0, err
+	}
+	weight, _ := rr.ServerWeight(u)
+	return weight, nil
+}
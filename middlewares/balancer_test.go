@@ -0,0 +1,157 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+// requestCounter is a forwarder counting the requests of every server, answering them after their latency
+type requestCounter struct {
+	lock      sync.Mutex
+	requests  map[string]int
+	latencies map[string]time.Duration
+}
+
+func newRequestCounter(latencies map[string]time.Duration) *requestCounter {
+	return &requestCounter{requests: map[string]int{}, latencies: latencies}
+}
+
+func (c *requestCounter) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	c.lock.Lock()
+	c.requests[r.URL.Host]++
+	c.lock.Unlock()
+	time.Sleep(c.latencies[r.URL.Host])
+}
+
+func upsertServers(t *testing.T, b *Balancer, weights map[string]int) {
+	for host, weight := range weights {
+		assert.NoError(t, b.UpsertServer(&url.URL{Scheme: "http", Host: host}, roundrobin.Weight(weight)))
+	}
+}
+
+// sendConcurrently sends requests from concurrent clients
+func sendConcurrently(b *Balancer, clients int, requests int) {
+	var wg sync.WaitGroup
+	for i := 0; i < clients; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < requests; j++ {
+				b.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "http://traefik/", nil))
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestLeastConnBalancerWeights(t *testing.T) {
+	counter := newRequestCounter(nil)
+	b := NewLeastConnBalancer(counter)
+	upsertServers(t, b, map[string]int{"10.0.0.1:80": 3, "10.0.0.2:80": 1})
+
+	sendConcurrently(b, 1, 8)
+	assert.Equal(t, map[string]int{"10.0.0.1:80": 6, "10.0.0.2:80": 2}, counter.requests, "idle servers should get the requests in weighted round robin")
+}
+
+func TestBalancerSkewedLatency(t *testing.T) {
+	for name, constructor := range map[string]func(http.Handler) *Balancer{
+		"leastconn": NewLeastConnBalancer,
+		"p2c":       NewP2CBalancer,
+	} {
+		counter := newRequestCounter(map[string]time.Duration{
+			"10.0.0.1:80": time.Millisecond,
+			"10.0.0.2:80": time.Millisecond,
+			"10.0.0.3:80": 20 * time.Millisecond,
+		})
+		b := constructor(counter)
+		upsertServers(t, b, map[string]int{"10.0.0.1:80": 1, "10.0.0.2:80": 1, "10.0.0.3:80": 1})
+
+		sendConcurrently(b, 10, 30)
+		total := 0
+		for _, requests := range counter.requests {
+			total += requests
+		}
+		assert.Equal(t, 300, total, name)
+		// round robin would send a third of the requests to the slow server
+		assert.True(t, counter.requests["10.0.0.3:80"] < total/5, "%s should spare the slow server: %v", name, counter.requests)
+		assert.True(t, counter.requests["10.0.0.1:80"] > total/4, "%s should use every fast server: %v", name, counter.requests)
+		assert.True(t, counter.requests["10.0.0.2:80"] > total/4, "%s should use every fast server: %v", name, counter.requests)
+	}
+}
+
+func TestP2CBalancerLatencyDecay(t *testing.T) {
+	b := NewP2CBalancer(http.NotFoundHandler())
+	now := time.Now()
+	b.now = func() time.Time {
+		return now
+	}
+	server := &balancedServer{weight: 1}
+
+	b.done(server, time.Second)
+	assert.Equal(t, float64(time.Second), server.latency, "the latency should rise at once")
+	now = now.Add(balancerLatencyDecay)
+	b.done(server, 0)
+	assert.InDelta(t, float64(time.Second)/2.718, server.latency, float64(time.Millisecond), "the latency should decay slowly")
+}
+
+func TestBalancerServers(t *testing.T) {
+	b := NewLeastConnBalancer(http.NotFoundHandler())
+	recorder := httptest.NewRecorder()
+	b.ServeHTTP(recorder, httptest.NewRequest("GET", "http://traefik/", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code, "a balancer without servers should be unavailable")
+
+	server1 := &url.URL{Scheme: "http", Host: "10.0.0.1:80"}
+	server2 := &url.URL{Scheme: "http", Host: "10.0.0.2:80"}
+	assert.NoError(t, b.UpsertServer(server1))
+	assert.NoError(t, b.UpsertServer(server2, roundrobin.Weight(2)))
+	assert.NoError(t, b.UpsertServer(server1, roundrobin.Weight(5)))
+	assert.Equal(t, []*url.URL{server1, server2}, b.Servers())
+	assert.Equal(t, 5, b.servers[0].weight, "the weight of an existing server should be updated")
+	assert.Equal(t, 2, b.servers[1].weight)
+
+	assert.NoError(t, b.RemoveServer(&url.URL{Scheme: "http", Host: "10.0.0.1:80"}))
+	assert.Equal(t, []*url.URL{server2}, b.Servers())
+	assert.Error(t, b.RemoveServer(server1), "removing a missing server should fail")
+}
+
+func TestBalancerPanickingRequest(t *testing.T) {
+	b := NewLeastConnBalancer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		panic("aborted")
+	}))
+	upsertServers(t, b, map[string]int{"10.0.0.1:80": 1})
+
+	assert.Panics(t, func() {
+		b.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "http://traefik/", nil))
+	})
+	assert.Equal(t, 0, b.servers[0].active, "a panicking request should not stay active")
+}
+
+func benchmarkBalancer(b *testing.B, balancer *Balancer) {
+	for i := 1; i <= 10; i++ {
+		balancer.UpsertServer(&url.URL{Scheme: "http", Host: "10.0.0." + strconv.Itoa(i) + ":80"}, roundrobin.Weight(i))
+	}
+	req := httptest.NewRequest("GET", "http://traefik/", nil)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		rw := httptest.NewRecorder()
+		for pb.Next() {
+			balancer.ServeHTTP(rw, req)
+		}
+	})
+}
+
+func BenchmarkLeastConnBalancer(b *testing.B) {
+	benchmarkBalancer(b, NewLeastConnBalancer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})))
+}
+
+func BenchmarkP2CBalancer(b *testing.B) {
+	benchmarkBalancer(b, NewP2CBalancer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})))
+}
@@ -0,0 +1,225 @@
+package middlewares
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/types"
+	"github.com/vulcand/oxy/utils"
+)
+
+const defaultMemBodyBytes = 1024 * 1024
+
+var errResponseTooLarge = errors.New("response body too large")
+
+// Buffering is a middleware reading the whole body of the requests before passing them on,
+// so that slow uploads do not hold a connection to the backend and that requests can be retried.
+// Bodies are kept in memory up to a size, and in a temporary file beyond.
+type Buffering struct {
+	config *types.Buffering
+}
+
+// NewBuffering returns a new Buffering instance
+func NewBuffering(config *types.Buffering) *Buffering {
+	return &Buffering{config: config}
+}
+
+func (b *Buffering) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	maxRequestBodyBytes := b.config.MaxRequestBodyBytes
+	if maxRequestBodyBytes > 0 && r.ContentLength > maxRequestBodyBytes {
+		log.Debugf("Request body of %d bytes is larger than %d bytes", r.ContentLength, maxRequestBodyBytes)
+		http.Error(rw, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if r.Body != nil && r.ContentLength != 0 {
+		buffer := newBodyBuffer(b.config.MemRequestBodyBytes)
+		defer buffer.Close()
+		var body io.Reader = r.Body
+		if maxRequestBodyBytes > 0 {
+			body = io.LimitReader(r.Body, maxRequestBodyBytes+1)
+		}
+		size, err := io.Copy(buffer, body)
+		if buffer.err != nil {
+			log.Errorf("Error buffering request body: %v", buffer.err)
+			http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		if err != nil {
+			log.Debugf("Error reading request body: %v", err)
+			http.Error(rw, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+		if maxRequestBodyBytes > 0 && size > maxRequestBodyBytes {
+			log.Debugf("Request body is larger than %d bytes", maxRequestBodyBytes)
+			http.Error(rw, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return
+		}
+		r.Body = &bufferedBody{buffer: buffer, Reader: buffer.reader()}
+		r.ContentLength = size
+		r.TransferEncoding = nil
+	}
+
+	if !b.config.BufferResponses && b.config.MaxResponseBodyBytes <= 0 {
+		next.ServeHTTP(rw, r)
+		return
+	}
+	recorder := &bufferedResponseWriter{
+		responseWriter: rw,
+		header:         make(http.Header),
+		buffer:         newBodyBuffer(b.config.MemResponseBodyBytes),
+		max:            b.config.MaxResponseBodyBytes,
+	}
+	defer recorder.buffer.Close()
+	next.ServeHTTP(recorder, r)
+	if recorder.hijacked {
+		return
+	}
+	if recorder.tooLarge || recorder.buffer.err != nil {
+		log.Errorf("Error buffering response of %s: %v", r.URL, recorder.err())
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	utils.CopyHeaders(rw.Header(), recorder.header)
+	rw.Header().Set("Content-Length", strconv.FormatInt(recorder.buffer.size, 10))
+	rw.WriteHeader(recorder.code())
+	if _, err := io.Copy(rw, recorder.buffer.reader()); err != nil {
+		log.Debugf("Error writing buffered response of %s: %v", r.URL, err)
+	}
+}
+
+// bodyBuffer holds a body in memory up to memBytes, and in a temporary file beyond
+type bodyBuffer struct {
+	memBytes int64
+	mem      bytes.Buffer
+	file     *os.File
+	size     int64
+	err      error
+}
+
+func newBodyBuffer(memBytes int64) *bodyBuffer {
+	if memBytes <= 0 {
+		memBytes = defaultMemBodyBytes
+	}
+	return &bodyBuffer{memBytes: memBytes}
+}
+
+func (b *bodyBuffer) Write(p []byte) (int, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+	if b.file == nil && int64(b.mem.Len()+len(p)) > b.memBytes {
+		b.file, b.err = ioutil.TempFile("", "traefik-buffer")
+		if b.err != nil {
+			return 0, b.err
+		}
+	}
+	var n int
+	if b.file != nil {
+		n, b.err = b.file.Write(p)
+	} else {
+		n, b.err = b.mem.Write(p)
+	}
+	b.size += int64(n)
+	return n, b.err
+}
+
+// reader returns a new reader of the whole body
+func (b *bodyBuffer) reader() io.Reader {
+	if b.file == nil {
+		return bytes.NewReader(b.mem.Bytes())
+	}
+	return io.MultiReader(bytes.NewReader(b.mem.Bytes()), io.NewSectionReader(b.file, 0, b.size-int64(b.mem.Len())))
+}
+
+// Close removes the temporary file of the body, if any
+func (b *bodyBuffer) Close() error {
+	if b.file == nil {
+		return nil
+	}
+	b.file.Close()
+	return os.Remove(b.file.Name())
+}
+
+// bufferedBody is a request body which can be read again, e.g. by Retry
+type bufferedBody struct {
+	io.Reader
+	buffer *bodyBuffer
+}
+
+// Close does nothing, the buffer being released by the Buffering middleware once the request is served
+func (b *bufferedBody) Close() error {
+	return nil
+}
+
+// reopen returns a new body reading the buffer from the beginning
+func (b *bufferedBody) reopen() *bufferedBody {
+	return &bufferedBody{buffer: b.buffer, Reader: b.buffer.reader()}
+}
+
+// bufferedResponseWriter holds a response until it has been entirely written
+type bufferedResponseWriter struct {
+	responseWriter http.ResponseWriter
+	header         http.Header
+	status         int
+	buffer         *bodyBuffer
+	max            int64
+	tooLarge       bool
+	hijacked       bool
+}
+
+func (w *bufferedResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *bufferedResponseWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *bufferedResponseWriter) Write(p []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	if w.max > 0 && w.buffer.size+int64(len(p)) > w.max {
+		w.tooLarge = true
+		return 0, errResponseTooLarge
+	}
+	return w.buffer.Write(p)
+}
+
+func (w *bufferedResponseWriter) code() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
+
+func (w *bufferedResponseWriter) err() error {
+	if w.tooLarge {
+		return errResponseTooLarge
+	}
+	return w.buffer.err
+}
+
+// Flush does nothing, the response being sent once entirely written
+func (w *bufferedResponseWriter) Flush() {}
+
+// CloseNotify returns a channel that receives at most a
+// single value (true) when the client connection has gone
+// away.
+func (w *bufferedResponseWriter) CloseNotify() <-chan bool {
+	return w.responseWriter.(http.CloseNotifier).CloseNotify()
+}
+
+// Hijack hijacks the connection, e.g. for websockets, which are not buffered
+func (w *bufferedResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.hijacked = true
+	return w.responseWriter.(http.Hijacker).Hijack()
+}
@@ -0,0 +1,103 @@
+package middlewares
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/codegangsta/negroni"
+	"github.com/containous/traefik/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// chunkedReader hides the length of a body, as a chunked upload does
+type chunkedReader struct {
+	*strings.Reader
+}
+
+var echoHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	w.Header().Set("X-Content-Length", strconv.FormatInt(r.ContentLength, 10))
+	w.Write(body)
+})
+
+func TestBufferingRequestBody(t *testing.T) {
+	handler := negroni.New(NewBuffering(&types.Buffering{MemRequestBodyBytes: 10}))
+	handler.UseHandler(echoHandler)
+
+	for _, body := range []string{"small", strings.Repeat("spilled to disk ", 100)} {
+		req := httptest.NewRequest("POST", "/", chunkedReader{strings.NewReader(body)})
+		req.ContentLength = -1
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, body, recorder.Body.String(), "the whole body should reach the backend")
+		assert.Equal(t, strconv.Itoa(len(body)), recorder.Header().Get("X-Content-Length"), "the length of the body should be set")
+	}
+}
+
+func TestBufferingMaxRequestBodyBytes(t *testing.T) {
+	handler := negroni.New(NewBuffering(&types.Buffering{MaxRequestBodyBytes: 10}))
+	handler.UseHandler(echoHandler)
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader("0123456789"))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusOK, recorder.Code, "a body of the maximum size should be accepted")
+
+	req = httptest.NewRequest("POST", "/", strings.NewReader("0123456789a"))
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code, "a too large Content-Length should be rejected")
+
+	req = httptest.NewRequest("POST", "/", chunkedReader{strings.NewReader("0123456789a")})
+	req.ContentLength = -1
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code, "a too large chunked body should be rejected")
+}
+
+func TestBufferingResponseBody(t *testing.T) {
+	handler := negroni.New(NewBuffering(&types.Buffering{MaxResponseBodyBytes: 20, MemResponseBodyBytes: 5}))
+	handler.UseHandler(echoHandler)
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader("spilled to disk"))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "spilled to disk", recorder.Body.String())
+	assert.Equal(t, "15", recorder.Header().Get("Content-Length"))
+
+	req = httptest.NewRequest("POST", "/", strings.NewReader(strings.Repeat("x", 21)))
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code, "a too large response should not be sent")
+}
+
+func TestBufferingRetry(t *testing.T) {
+	attempts := 0
+	backend := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		body, _ := ioutil.ReadAll(r.Body)
+		r.Body.Close()
+		if attempts == 1 {
+			NetworkErrorHandler.ServeHTTP(w, r, io.ErrUnexpectedEOF)
+			return
+		}
+		w.Write(body)
+	})
+	body := strings.Repeat("replayed body ", 10)
+	handler := negroni.New(NewBuffering(&types.Buffering{MemRequestBodyBytes: 10}))
+	handler.UseHandler(NewRetry(2, nil, backend))
+
+	req := httptest.NewRequest("PUT", "/", strings.NewReader(body))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, body, recorder.Body.String(), "the body should be sent again on retry")
+}
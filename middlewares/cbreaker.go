@@ -2,24 +2,140 @@ package middlewares
 
 import (
 	"net/http"
+	"sync"
+	"time"
 
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/types"
+	"github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
 	"github.com/vulcand/oxy/cbreaker"
 )
 
+const (
+	circuitBreakerTransitionsName = "traefik_circuit_breaker_transitions_total"
+	circuitBreakerOpenName        = "traefik_circuit_breaker_open"
+
+	// circuitBreakerFallbackDuration is how long a tripped circuit breaker sends the requests to its fallback before recovering
+	circuitBreakerFallbackDuration = 10 * time.Second
+
+	// CircuitBreakerStandby is the state of a circuit breaker sending the requests to its backend
+	CircuitBreakerStandby = "standby"
+	// CircuitBreakerTripped is the state of a circuit breaker sending the requests to its fallback
+	CircuitBreakerTripped = "tripped"
+	// CircuitBreakerRecovering is the state of a circuit breaker sending more and more requests to its backend again
+	CircuitBreakerRecovering = "recovering"
+)
+
+var (
+	circuitBreakerTransitionsCounter = prometheus.NewCounterFrom(
+		stdprometheus.CounterOpts{
+			Name: circuitBreakerTransitionsName,
+			Help: "How many times the circuit breakers changed state, partitioned by backend and new state.",
+		},
+		[]string{"backend", "state"},
+	)
+	circuitBreakerOpenVec = stdprometheus.NewGaugeVec(
+		stdprometheus.GaugeOpts{
+			Name: circuitBreakerOpenName,
+			Help: "Whether the circuit breaker of a backend is tripped (1) or not (0).",
+		},
+		[]string{"backend"},
+	)
+	circuitBreakerOpenGauge = prometheus.NewGauge(circuitBreakerOpenVec)
+)
+
+func init() {
+	stdprometheus.MustRegister(circuitBreakerOpenVec)
+}
+
 // CircuitBreaker holds the oxy circuit breaker.
 type CircuitBreaker struct {
 	circuitBreaker *cbreaker.CircuitBreaker
+	backend        string
+	lock           sync.RWMutex
+	state          string
+	trippedAt      time.Time
+	now            func() time.Time
 }
 
-// NewCircuitBreaker returns a new CircuitBreaker.
-func NewCircuitBreaker(next http.Handler, expression string, options ...cbreaker.CircuitBreakerOption) (*CircuitBreaker, error) {
+// NewCircuitBreaker returns a new CircuitBreaker of a backend.
+func NewCircuitBreaker(backend string, next http.Handler, expression string, options ...cbreaker.CircuitBreakerOption) (*CircuitBreaker, error) {
+	cb := &CircuitBreaker{
+		backend: backend,
+		state:   CircuitBreakerStandby,
+		now:     time.Now,
+	}
+	options = append(options,
+		cbreaker.FallbackDuration(circuitBreakerFallbackDuration),
+		cbreaker.OnTripped(&circuitBreakerTransition{cb, CircuitBreakerTripped}),
+		cbreaker.OnStandby(&circuitBreakerTransition{cb, CircuitBreakerStandby}))
 	circuitBreaker, err := cbreaker.New(next, expression, options...)
 	if err != nil {
 		return nil, err
 	}
-	return &CircuitBreaker{circuitBreaker}, nil
+	cb.circuitBreaker = circuitBreaker
+	circuitBreakerOpenGauge.With("backend", backend).Set(0)
+	return cb, nil
+}
+
+// NewFallback returns a handler sending the static response of a fallback
+func NewFallback(config *types.Fallback) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		statusCode := config.StatusCode
+		if statusCode == 0 {
+			statusCode = http.StatusServiceUnavailable
+		}
+		if config.ContentType != "" {
+			rw.Header().Set("Content-Type", config.ContentType)
+		}
+		rw.WriteHeader(statusCode)
+		rw.Write([]byte(config.Body))
+	})
 }
 
 func (cb *CircuitBreaker) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
 	cb.circuitBreaker.ServeHTTP(rw, r)
 }
+
+// State returns the current state of the circuit breaker
+func (cb *CircuitBreaker) State() string {
+	cb.lock.RLock()
+	defer cb.lock.RUnlock()
+	// oxy does not notify the recovery, which starts once the fallback duration has elapsed
+	if cb.state == CircuitBreakerTripped && cb.now().Sub(cb.trippedAt) >= circuitBreakerFallbackDuration {
+		return CircuitBreakerRecovering
+	}
+	return cb.state
+}
+
+// Close removes the metrics of the circuit breaker, once its backend is removed from the configuration
+func (cb *CircuitBreaker) Close() {
+	circuitBreakerOpenVec.DeleteLabelValues(cb.backend)
+}
+
+// circuitBreakerTransition records a new state of a circuit breaker
+type circuitBreakerTransition struct {
+	cb    *CircuitBreaker
+	state string
+}
+
+// Exec is called by oxy when the circuit breaker changes state
+func (t *circuitBreakerTransition) Exec() error {
+	t.cb.lock.Lock()
+	t.cb.state = t.state
+	if t.state == CircuitBreakerTripped {
+		t.cb.trippedAt = t.cb.now()
+	}
+	t.cb.lock.Unlock()
+
+	circuitBreakerTransitionsCounter.With("backend", t.cb.backend, "state", t.state).Add(1)
+	if t.state == CircuitBreakerTripped {
+		log.Warnf("Circuit breaker of backend %s tripped, sending the requests to its fallback", t.cb.backend)
+		circuitBreakerOpenGauge.With("backend", t.cb.backend).Set(1)
+	} else {
+		log.Infof("Circuit breaker of backend %s recovered, sending the requests to its servers", t.cb.backend)
+		circuitBreakerOpenGauge.With("backend", t.cb.backend).Set(0)
+	}
+	return nil
+}
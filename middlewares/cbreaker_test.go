@@ -0,0 +1,56 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/types"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreakerState(t *testing.T) {
+	cb, err := NewCircuitBreaker("backend-state", namedHandler("backend"), "NetworkErrorRatio() > 0.5")
+	assert.NoError(t, err, "there should be no error")
+	now := time.Now()
+	cb.now = func() time.Time {
+		return now
+	}
+	assert.Equal(t, CircuitBreakerStandby, cb.State())
+
+	(&circuitBreakerTransition{cb, CircuitBreakerTripped}).Exec()
+	assert.Equal(t, CircuitBreakerTripped, cb.State())
+
+	now = now.Add(circuitBreakerFallbackDuration)
+	assert.Equal(t, CircuitBreakerRecovering, cb.State(), "the circuit breaker should recover after the fallback duration")
+
+	(&circuitBreakerTransition{cb, CircuitBreakerStandby}).Exec()
+	assert.Equal(t, CircuitBreakerStandby, cb.State())
+}
+
+func TestCircuitBreakerClose(t *testing.T) {
+	cb, err := NewCircuitBreaker("backend-closed", namedHandler("backend"), "NetworkErrorRatio() > 0.5")
+	assert.NoError(t, err, "there should be no error")
+	recorder := httptest.NewRecorder()
+	promhttp.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Contains(t, recorder.Body.String(), circuitBreakerOpenName+`{backend="backend-closed"} 0`)
+
+	cb.Close()
+	recorder = httptest.NewRecorder()
+	promhttp.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	assert.NotContains(t, recorder.Body.String(), `backend="backend-closed"`, "the metrics of a removed backend should be removed")
+}
+
+func TestFallback(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	NewFallback(&types.Fallback{StatusCode: http.StatusOK, ContentType: "application/json", Body: `{"items":[]}`}).ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, `{"items":[]}`, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	NewFallback(&types.Fallback{}).ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code, "the default status should be 503")
+}
@@ -0,0 +1,104 @@
+package middlewares
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// parseIPNets parses a list of IPs and CIDRs, single IPs being turned into /32 (or /128) networks.
+func parseIPNets(ranges []string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, r := range ranges {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+		if !strings.Contains(r, "/") {
+			ip := net.ParseIP(r)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address: %s", r)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 8 * net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR: %s", r)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+func containsIP(nets []*net.IPNet, ip string) bool {
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		return false
+	}
+	for _, ipNet := range nets {
+		if ipNet.Contains(parsedIP) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteIP returns the IP of the peer the request comes from.
+func remoteIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
+
+// forwardedForHops returns the X-Forwarded-For entries, the closest proxy being last.
+func forwardedForHops(r *http.Request) []string {
+	var hops []string
+	for _, header := range r.Header["X-Forwarded-For"] {
+		for _, hop := range strings.Split(header, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+	return hops
+}
+
+// clientIP returns the IP of the client, walking the X-Forwarded-For chain from the right
+// as long as the hops are trusted proxies.
+func clientIP(r *http.Request, trustedProxies []*net.IPNet) string {
+	ip := remoteIP(r)
+	if !containsIP(trustedProxies, ip) {
+		return ip
+	}
+	hops := forwardedForHops(r)
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip = hops[i]
+		if !containsIP(trustedProxies, ip) {
+			break
+		}
+	}
+	return ip
+}
+
+// forwardedIP returns the Nth X-Forwarded-For entry starting from the right, the IP of the peer
+// if depth is not positive, or "" if the request went through less than depth proxies.
+func forwardedIP(r *http.Request, depth int) string {
+	if depth <= 0 {
+		return remoteIP(r)
+	}
+	hops := forwardedForHops(r)
+	if depth > len(hops) {
+		// the peer is a trusted proxy, which must not be taken for the client
+		return ""
+	}
+	return hops[len(hops)-depth]
+}
@@ -0,0 +1,179 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/containous/traefik/types"
+)
+
+var defaultCORSMethods = []string{"GET", "HEAD", "POST"}
+
+// CORS is a middleware that answers the CORS preflight requests and decorates the
+// responses to the cross-origin requests of allowed origins.
+type CORS struct {
+	allowedOrigins   []string
+	allowAnyOrigin   bool
+	allowedMethods   []string
+	allowedHeaders   []string
+	allowAnyHeader   bool
+	exposedHeaders   string
+	allowCredentials bool
+	maxAge           string
+}
+
+// NewCORS builds a new CORS given a config
+func NewCORS(config *types.CORS) (*CORS, error) {
+	cors := &CORS{
+		allowedMethods:   defaultCORSMethods,
+		exposedHeaders:   strings.Join(config.ExposedHeaders, ", "),
+		allowCredentials: config.AllowCredentials,
+	}
+	for _, origin := range config.AllowedOrigins {
+		origin = strings.ToLower(strings.TrimSpace(origin))
+		if origin == "*" {
+			cors.allowAnyOrigin = true
+			continue
+		}
+		if _, err := path.Match(origin, ""); err != nil {
+			return nil, fmt.Errorf("Error creating CORS: invalid origin %s", origin)
+		}
+		cors.allowedOrigins = append(cors.allowedOrigins, origin)
+	}
+	if len(config.AllowedMethods) > 0 {
+		cors.allowedMethods = nil
+		for _, method := range config.AllowedMethods {
+			cors.allowedMethods = append(cors.allowedMethods, strings.ToUpper(strings.TrimSpace(method)))
+		}
+	}
+	for _, header := range config.AllowedHeaders {
+		header = strings.TrimSpace(header)
+		if header == "*" {
+			cors.allowAnyHeader = true
+			continue
+		}
+		cors.allowedHeaders = append(cors.allowedHeaders, http.CanonicalHeaderKey(header))
+	}
+	if config.MaxAge < 0 {
+		return nil, fmt.Errorf("Error creating CORS: negative maxAge %d", config.MaxAge)
+	}
+	if config.MaxAge > 0 {
+		cors.maxAge = strconv.Itoa(config.MaxAge)
+	}
+	return cors, nil
+}
+
+func (c *CORS) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	origin := r.Header.Get("Origin")
+	if origin != "" && r.Method == "OPTIONS" && r.Header.Get("Access-Control-Request-Method") != "" {
+		c.servePreflight(rw, r, origin)
+		return
+	}
+	// the responses without Origin vary too, a shared cache must not serve them to the cross-origin requests
+	allowed := origin != "" && c.originAllowed(origin)
+	next(newHeaderHookResponseWriter(rw, func(header http.Header) {
+		if !c.wildcardOrigin() {
+			header.Add("Vary", "Origin")
+		}
+		if !allowed {
+			return
+		}
+		c.setOriginHeaders(header, origin)
+		if c.exposedHeaders != "" {
+			header.Set("Access-Control-Expose-Headers", c.exposedHeaders)
+		}
+	}), r)
+}
+
+// servePreflight answers a preflight request, without CORS headers if the request is not allowed
+func (c *CORS) servePreflight(rw http.ResponseWriter, r *http.Request, origin string) {
+	header := rw.Header()
+	header.Add("Vary", "Origin")
+	header.Add("Vary", "Access-Control-Request-Method")
+	header.Add("Vary", "Access-Control-Request-Headers")
+
+	method := strings.ToUpper(r.Header.Get("Access-Control-Request-Method"))
+	requestedHeaders := parseHeaderList(r.Header.Get("Access-Control-Request-Headers"))
+	if c.originAllowed(origin) && c.methodAllowed(method) && c.headersAllowed(requestedHeaders) {
+		c.setOriginHeaders(header, origin)
+		header.Set("Access-Control-Allow-Methods", strings.Join(c.allowedMethods, ", "))
+		if len(requestedHeaders) > 0 {
+			header.Set("Access-Control-Allow-Headers", strings.Join(requestedHeaders, ", "))
+		}
+		if c.maxAge != "" {
+			header.Set("Access-Control-Max-Age", c.maxAge)
+		}
+	}
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+// wildcardOrigin tells whether the responses are the same whatever the origin.
+// Credentials cannot be allowed for the "*" wildcard, the origin is echoed instead.
+func (c *CORS) wildcardOrigin() bool {
+	return c.allowAnyOrigin && !c.allowCredentials
+}
+
+func (c *CORS) setOriginHeaders(header http.Header, origin string) {
+	if c.wildcardOrigin() {
+		header.Set("Access-Control-Allow-Origin", "*")
+	} else {
+		header.Set("Access-Control-Allow-Origin", origin)
+	}
+	if c.allowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+func (c *CORS) originAllowed(origin string) bool {
+	if c.allowAnyOrigin {
+		return true
+	}
+	origin = strings.ToLower(origin)
+	for _, allowed := range c.allowedOrigins {
+		if matched, _ := path.Match(allowed, origin); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CORS) methodAllowed(method string) bool {
+	for _, allowed := range c.allowedMethods {
+		if allowed == method {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CORS) headersAllowed(headers []string) bool {
+	if c.allowAnyHeader {
+		return true
+	}
+	for _, header := range headers {
+		allowed := false
+		for _, allowedHeader := range c.allowedHeaders {
+			if allowedHeader == header {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return false
+		}
+	}
+	return true
+}
+
+func parseHeaderList(list string) []string {
+	var headers []string
+	for _, header := range strings.Split(list, ",") {
+		if header = strings.TrimSpace(header); header != "" {
+			headers = append(headers, http.CanonicalHeaderKey(header))
+		}
+	}
+	return headers
+}
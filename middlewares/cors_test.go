@@ -0,0 +1,119 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/codegangsta/negroni"
+	"github.com/containous/traefik/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func corsRequest(handler http.Handler, method string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/api", nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestCORSPreflight(t *testing.T) {
+	calls := 0
+	backend := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Access-Control-Allow-Origin", "https://backend.example.com")
+		w.WriteHeader(http.StatusOK)
+	})
+	cors, err := NewCORS(&types.CORS{
+		AllowedOrigins: []string{"https://app.example.com", "https://*.example.org"},
+		AllowedMethods: []string{"GET", "put"},
+		AllowedHeaders: []string{"Content-Type", "x-api-key"},
+		MaxAge:         600,
+	})
+	assert.NoError(t, err, "there should be no error")
+	handler := negroni.New(cors)
+	handler.UseHandler(backend)
+
+	recorder := corsRequest(handler, "OPTIONS", map[string]string{
+		"Origin":                         "https://foo.example.org",
+		"Access-Control-Request-Method":  "PUT",
+		"Access-Control-Request-Headers": "content-type, X-Api-Key",
+	})
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+	assert.Equal(t, 0, calls, "preflights should be answered by the proxy")
+	assert.Equal(t, "https://foo.example.org", recorder.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, PUT", recorder.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Content-Type, X-Api-Key", recorder.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "600", recorder.Header().Get("Access-Control-Max-Age"))
+
+	for _, headers := range []map[string]string{
+		{"Origin": "https://evil.com", "Access-Control-Request-Method": "GET"},
+		{"Origin": "https://app.example.com", "Access-Control-Request-Method": "DELETE"},
+		{"Origin": "https://app.example.com", "Access-Control-Request-Method": "GET", "Access-Control-Request-Headers": "X-Other"},
+	} {
+		recorder = corsRequest(handler, "OPTIONS", headers)
+		assert.Equal(t, http.StatusNoContent, recorder.Code)
+		assert.Empty(t, recorder.Header().Get("Access-Control-Allow-Origin"), "%v should not be allowed", headers)
+	}
+}
+
+func TestCORSActualRequest(t *testing.T) {
+	calls := 0
+	backend := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Access-Control-Allow-Origin", "https://backend.example.com")
+		w.WriteHeader(http.StatusOK)
+	})
+	cors, err := NewCORS(&types.CORS{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		ExposedHeaders:   []string{"X-Request-ID", "X-Total"},
+		AllowCredentials: true,
+	})
+	assert.NoError(t, err, "there should be no error")
+	handler := negroni.New(cors)
+	handler.UseHandler(backend)
+
+	recorder := corsRequest(handler, "GET", map[string]string{"Origin": "https://app.example.com"})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, "https://app.example.com", recorder.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", recorder.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "X-Request-ID, X-Total", recorder.Header().Get("Access-Control-Expose-Headers"))
+	assert.Equal(t, "Origin", recorder.Header().Get("Vary"))
+
+	recorder = corsRequest(handler, "GET", map[string]string{"Origin": "https://evil.com"})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Empty(t, recorder.Header().Get("Access-Control-Allow-Credentials"))
+
+	recorder = corsRequest(handler, "OPTIONS", nil)
+	assert.Equal(t, 3, calls, "requests without origin should go to the backend")
+	assert.Equal(t, "Origin", recorder.Header().Get("Vary"), "responses without origin should vary by origin too")
+}
+
+func TestCORSAnyOrigin(t *testing.T) {
+	cors, err := NewCORS(&types.CORS{AllowedOrigins: []string{"*"}})
+	assert.NoError(t, err, "there should be no error")
+	handler := negroni.New(cors)
+	handler.UseHandler(echoHandler)
+	recorder := corsRequest(handler, "GET", map[string]string{"Origin": "https://app.example.com"})
+	assert.Equal(t, "*", recorder.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, recorder.Header().Get("Vary"))
+
+	cors, err = NewCORS(&types.CORS{AllowedOrigins: []string{"*"}, AllowCredentials: true})
+	assert.NoError(t, err, "there should be no error")
+	handler = negroni.New(cors)
+	handler.UseHandler(echoHandler)
+	recorder = corsRequest(handler, "GET", map[string]string{"Origin": "https://app.example.com"})
+	assert.Equal(t, "https://app.example.com", recorder.Header().Get("Access-Control-Allow-Origin"), "the origin should be echoed when credentials are allowed")
+}
+
+func TestCORSInvalidConfig(t *testing.T) {
+	_, err := NewCORS(&types.CORS{AllowedOrigins: []string{"https://[.example.com"}})
+	assert.Error(t, err, "invalid globs should be rejected")
+
+	_, err = NewCORS(&types.CORS{MaxAge: -1})
+	assert.Error(t, err, "negative max age should be rejected")
+}
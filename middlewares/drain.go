@@ -0,0 +1,284 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/log"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+var (
+	backendDrainsLock sync.Mutex
+	backendDrains     = map[drainKey]*backendDrain{}
+)
+
+// drainKey identifies a backend, whose name is only unique within its provider
+type drainKey struct {
+	provider string
+	backend  string
+}
+
+// backendDrain holds the drained servers of a backend and the requests in progress of its servers,
+// kept across the configuration reloads
+type backendDrain struct {
+	lock sync.Mutex
+	// start of the drain of the drained servers, by server
+	drained map[string]time.Time
+	active  map[string]int
+	// last request of the drained servers, by server
+	lastRequest map[string]time.Time
+	// drainer of the current configuration
+	drainer *Drainer
+}
+
+// Drainer takes servers of a backend out of its load balancer on demand, e.g. for maintenance, while their requests in
+// progress and their sticky sessions finish. It sits between the load balancer and the forwarder, counting the requests
+// in progress of every server, and between the load balancer and what adds its servers: the configuration, the health
+// check and the outlier detection, which cannot add a drained server back.
+type Drainer struct {
+	provider string
+	backend  string
+	next     http.Handler
+	drain    *backendDrain
+	lb       drainLoadBalancer
+	weights  map[string]int
+	// drained servers kept out of the load balancer, added back with their configured weight when undrained
+	held map[string]*url.URL
+	now  func() time.Time
+}
+
+type drainLoadBalancer interface {
+	RemoveServer(u *url.URL) error
+	UpsertServer(u *url.URL, options ...roundrobin.ServerOption) error
+	Servers() []*url.URL
+}
+
+// Drain holds the state of a drained server
+type Drain struct {
+	Since time.Time `json:"since"`
+	// requests in progress
+	Connections int        `json:"connections"`
+	LastRequest *time.Time `json:"lastRequest,omitempty"`
+}
+
+// NewDrainer returns a new Drainer of a backend of a provider sending the requests to next,
+// which keeps the drained servers out of its load balancer set with SetLoadBalancer.
+// The servers drained afterwards are taken out of its load balancer once it is registered with Register.
+func NewDrainer(provider string, backend string, next http.Handler) *Drainer {
+	return &Drainer{
+		provider: provider,
+		backend:  backend,
+		next:     next,
+		drain:    getBackendDrain(provider, backend),
+		held:     make(map[string]*url.URL),
+		now:      time.Now,
+	}
+}
+
+// getBackendDrain returns the drained servers of a backend of a provider
+func getBackendDrain(provider string, backend string) *backendDrain {
+	backendDrainsLock.Lock()
+	defer backendDrainsLock.Unlock()
+	drain, ok := backendDrains[drainKey{provider, backend}]
+	if !ok {
+		drain = &backendDrain{
+			drained:     make(map[string]time.Time),
+			active:      make(map[string]int),
+			lastRequest: make(map[string]time.Time),
+		}
+		backendDrains[drainKey{provider, backend}] = drain
+	}
+	return drain
+}
+
+// Register makes the drainer the one draining the servers of its backend, once the configuration it belongs to
+// is the current one. The servers drained or undrained since it was created are taken out of its load balancer
+// or added back.
+func (d *Drainer) Register() {
+	d.drain.lock.Lock()
+	defer d.drain.lock.Unlock()
+	d.drain.drainer = d
+	for key := range d.drain.drained {
+		d.hold(key)
+	}
+	for key := range d.held {
+		if _, ok := d.drain.drained[key]; !ok {
+			d.release(key)
+		}
+	}
+}
+
+// SetLoadBalancer sets the load balancer the drained servers are kept out of, and the configured weights
+// of the servers by URL, restored when they are undrained
+func (d *Drainer) SetLoadBalancer(lb drainLoadBalancer, weights map[string]int) {
+	d.drain.lock.Lock()
+	defer d.drain.lock.Unlock()
+	d.lb = lb
+	d.weights = weights
+}
+
+func (d *Drainer) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	// the load balancer has set the URL of the request to the one of its server
+	key := serverKey(r.URL)
+	d.drain.lock.Lock()
+	d.drain.active[key]++
+	if _, ok := d.drain.drained[key]; ok {
+		d.drain.lastRequest[key] = d.now()
+	}
+	d.drain.lock.Unlock()
+	defer func() {
+		d.drain.lock.Lock()
+		d.drain.active[key]--
+		if d.drain.active[key] == 0 {
+			delete(d.drain.active, key)
+		}
+		d.drain.lock.Unlock()
+	}()
+	d.next.ServeHTTP(rw, r)
+}
+
+// UpsertServer adds a server to the load balancer, unless it is drained
+func (d *Drainer) UpsertServer(u *url.URL, options ...roundrobin.ServerOption) error {
+	d.drain.lock.Lock()
+	defer d.drain.lock.Unlock()
+	if _, ok := d.drain.drained[serverKey(u)]; ok {
+		log.Debugf("Keeping drained server %s of backend %s out of the load balancer", u, d.backend)
+		d.held[serverKey(u)] = u
+		return nil
+	}
+	return d.lb.UpsertServer(u, options...)
+}
+
+// RemoveServer removes a server from the load balancer
+func (d *Drainer) RemoveServer(u *url.URL) error {
+	d.drain.lock.Lock()
+	defer d.drain.lock.Unlock()
+	if _, ok := d.held[serverKey(u)]; ok {
+		delete(d.held, serverKey(u))
+		return nil
+	}
+	return d.lb.RemoveServer(u)
+}
+
+// Servers returns the URLs of the servers of the load balancer, without the drained ones
+func (d *Drainer) Servers() []*url.URL {
+	return d.lb.Servers()
+}
+
+// draining returns the URLs of the drained servers, which still get the requests of their sticky sessions
+func (d *Drainer) draining() []*url.URL {
+	d.drain.lock.Lock()
+	defer d.drain.lock.Unlock()
+	var urls []*url.URL
+	for _, u := range d.held {
+		urls = append(urls, u)
+	}
+	return urls
+}
+
+// hold takes a drained server out of the load balancer
+func (d *Drainer) hold(key string) {
+	if d.lb == nil {
+		return
+	}
+	for _, u := range d.lb.Servers() {
+		if serverKey(u) == key {
+			if err := d.lb.RemoveServer(u); err != nil {
+				log.Errorf("Error draining server %s of backend %s: %v", u, d.backend, err)
+				return
+			}
+			d.held[key] = u
+		}
+	}
+}
+
+// release adds an undrained server back to the load balancer with its configured weight
+func (d *Drainer) release(key string) {
+	u, ok := d.held[key]
+	if !ok {
+		return
+	}
+	delete(d.held, key)
+	weight, ok := d.weights[u.String()]
+	if !ok {
+		weight = 1
+	}
+	if err := d.lb.UpsertServer(u, roundrobin.Weight(weight)); err != nil {
+		log.Errorf("Error undraining server %s of backend %s: %v", u, d.backend, err)
+	}
+}
+
+// DrainServer stops sending new requests to a server of a backend of a provider, its requests in progress and its sticky
+// sessions finishing, until it is undrained. The server stays drained across the configuration reloads.
+func DrainServer(provider string, backend string, serverURL string) error {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return fmt.Errorf("invalid server URL %s: %v", serverURL, err)
+	}
+	drain := getBackendDrain(provider, backend)
+	drain.lock.Lock()
+	defer drain.lock.Unlock()
+	key := serverKey(u)
+	if _, ok := drain.drained[key]; ok {
+		return nil
+	}
+	log.Infof("Draining server %s of backend %s", u, backend)
+	drain.drained[key] = time.Now()
+	delete(drain.lastRequest, key)
+	if drain.drainer != nil {
+		drain.drainer.hold(key)
+	}
+	return nil
+}
+
+// UndrainServer adds a drained server of a backend of a provider back to its load balancer
+func UndrainServer(provider string, backend string, serverURL string) error {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return fmt.Errorf("invalid server URL %s: %v", serverURL, err)
+	}
+	drain := getBackendDrain(provider, backend)
+	drain.lock.Lock()
+	defer drain.lock.Unlock()
+	key := serverKey(u)
+	if _, ok := drain.drained[key]; !ok {
+		return nil
+	}
+	log.Infof("Undraining server %s of backend %s", u, backend)
+	delete(drain.drained, key)
+	delete(drain.lastRequest, key)
+	if drain.drainer != nil {
+		drain.drainer.release(key)
+	}
+	return nil
+}
+
+// GetServerDrain returns the drain of a server of a backend of a provider, or nil if it is not drained
+func GetServerDrain(provider string, backend string, serverURL string) *Drain {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return nil
+	}
+	backendDrainsLock.Lock()
+	drain, ok := backendDrains[drainKey{provider, backend}]
+	backendDrainsLock.Unlock()
+	if !ok {
+		return nil
+	}
+	drain.lock.Lock()
+	defer drain.lock.Unlock()
+	key := serverKey(u)
+	since, ok := drain.drained[key]
+	if !ok {
+		return nil
+	}
+	state := &Drain{Since: since, Connections: drain.active[key]}
+	if lastRequest, ok := drain.lastRequest[key]; ok {
+		state.LastRequest = &lastRequest
+	}
+	return state
+}
@@ -0,0 +1,111 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+func TestDrainer(t *testing.T) {
+	server1 := &url.URL{Scheme: "http", Host: "10.0.0.1:80"}
+	server2 := &url.URL{Scheme: "http", Host: "10.0.0.2:80"}
+	inFlight := make(chan struct{})
+	done := make(chan struct{})
+	d := NewDrainer("provider", "backend-drain", http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Host == server1.Host {
+			inFlight <- struct{}{}
+			<-done
+		}
+	}))
+	b := NewLeastConnBalancer(d)
+	d.SetLoadBalancer(b, map[string]int{server1.String(): 3, server2.String(): 1})
+	assert.NoError(t, d.UpsertServer(server1, roundrobin.Weight(3)))
+	assert.NoError(t, d.UpsertServer(server2, roundrobin.Weight(1)))
+	d.Register()
+
+	go d.ServeHTTP(httptest.NewRecorder(), &http.Request{URL: server1})
+	<-inFlight
+	assert.Nil(t, GetServerDrain("provider", "backend-drain", server1.String()), "a server should not be drained by default")
+	assert.NoError(t, DrainServer("provider", "backend-drain", server1.String()))
+	assert.Equal(t, []*url.URL{server2}, b.Servers(), "a drained server should get no new requests")
+	drain := GetServerDrain("provider", "backend-drain", server1.String())
+	if assert.NotNil(t, drain) {
+		assert.Equal(t, 1, drain.Connections, "the requests in progress of a drained server should finish")
+	}
+	close(done)
+
+	assert.NoError(t, d.UpsertServer(server1, roundrobin.Weight(3)))
+	assert.Equal(t, []*url.URL{server2}, b.Servers(), "a drained server should not be added back by a health check")
+
+	assert.Nil(t, GetServerDrain("other", "backend-drain", server1.String()), "a server should only be drained for its provider")
+	assert.NoError(t, UndrainServer("provider", "backend-drain", server1.String()))
+	assert.Nil(t, GetServerDrain("provider", "backend-drain", server1.String()))
+	assert.Equal(t, map[string]int{"10.0.0.1:80": 3, "10.0.0.2:80": 1}, weights(b), "an undrained server should get its weight back")
+}
+
+func TestDrainerReload(t *testing.T) {
+	server1 := &url.URL{Scheme: "http", Host: "10.0.0.1:80"}
+	server2 := &url.URL{Scheme: "http", Host: "10.0.0.2:80"}
+	d := NewDrainer("provider", "backend-drain-reload", http.NotFoundHandler())
+	d.SetLoadBalancer(NewLeastConnBalancer(d), map[string]int{server1.String(): 1, server2.String(): 1})
+	assert.NoError(t, d.UpsertServer(server1, roundrobin.Weight(1)))
+	assert.NoError(t, d.UpsertServer(server2, roundrobin.Weight(1)))
+	d.Register()
+	assert.NoError(t, DrainServer("provider", "backend-drain-reload", server1.String()))
+
+	reloaded := NewDrainer("provider", "backend-drain-reload", http.NotFoundHandler())
+	b := NewLeastConnBalancer(reloaded)
+	reloaded.SetLoadBalancer(b, map[string]int{server1.String(): 1, server2.String(): 1})
+	assert.NoError(t, reloaded.UpsertServer(server1, roundrobin.Weight(1)))
+	assert.NoError(t, reloaded.UpsertServer(server2, roundrobin.Weight(1)))
+	assert.Equal(t, map[string]int{"10.0.0.2:80": 1}, weights(b), "a server should stay drained after a reload")
+
+	// the servers drained or undrained while the configuration is loaded are updated once it is the current one
+	assert.NoError(t, UndrainServer("provider", "backend-drain-reload", server1.String()))
+	assert.NoError(t, DrainServer("provider", "backend-drain-reload", server2.String()))
+	assert.Equal(t, map[string]int{"10.0.0.2:80": 1}, weights(b), "a drainer should not drain its servers before it is registered")
+	reloaded.Register()
+	assert.Equal(t, map[string]int{"10.0.0.1:80": 1}, weights(b), "a registered drainer should catch up with the drains")
+	assert.NoError(t, UndrainServer("provider", "backend-drain-reload", server2.String()))
+	assert.Equal(t, map[string]int{"10.0.0.1:80": 1, "10.0.0.2:80": 1}, weights(b))
+}
+
+func TestDrainerStickySession(t *testing.T) {
+	counter := newRequestCounter(nil)
+	d := NewDrainer("provider", "backend-drain-sticky", counter)
+	s, err := NewStickySession("backend-drain-sticky", nil, d)
+	assert.NoError(t, err, "there should be no error")
+	b := NewLeastConnBalancer(s.Forwarder())
+	upsertServers(t, b, map[string]int{"10.0.0.1:80": 1, "10.0.0.2:80": 1, "10.0.0.3:80": 1})
+	s.SetLoadBalancer(b)
+	s.SetDrainer(d)
+	d.SetLoadBalancer(b, map[string]int{})
+	d.Register()
+
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, httptest.NewRequest("GET", "http://traefik/", nil))
+	cookie := stickyCookie(t, recorder)
+	var stuck string
+	for host := range counter.requests {
+		stuck = host
+	}
+	assert.NoError(t, DrainServer("provider", "backend-drain-sticky", "http://"+stuck))
+
+	for i := 0; i < 5; i++ {
+		req := httptest.NewRequest("GET", "http://traefik/", nil)
+		req.AddCookie(cookie)
+		s.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	for i := 0; i < 5; i++ {
+		s.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "http://traefik/", nil))
+	}
+	assert.Equal(t, 6, counter.requests[stuck], "a drained server should only get the requests of its sticky sessions")
+	drain := GetServerDrain("provider", "backend-drain-sticky", "http://"+stuck)
+	if assert.NotNil(t, drain) {
+		assert.NotNil(t, drain.LastRequest, "the last request of a drained server should be recorded")
+	}
+}
@@ -0,0 +1,228 @@
+package middlewares
+
+import (
+	"bufio"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/types"
+	"github.com/vulcand/oxy/utils"
+)
+
+// errorPageKeptHeaders are the headers of an error response still sent with the error page replacing it
+var errorPageKeptHeaders = []string{"Allow", "Retry-After", "Set-Cookie", "Www-Authenticate"}
+
+// ErrorPages is a middleware replacing the error responses of a frontend by custom error pages served from a backend.
+// The status code of the error is kept, and the URL of the request is sent to the backend in the X-Original-Url header.
+// API clients accepting JSON get the original error responses.
+type ErrorPages struct {
+	pages []*errorPage
+}
+
+type errorPage struct {
+	name    string
+	ranges  [][2]int
+	query   string
+	handler http.Handler
+}
+
+// NewErrorPages builds a new ErrorPages given the configured pages and the handlers of their backends
+func NewErrorPages(pages map[string]*types.ErrorPage, handlers map[string]http.Handler) (*ErrorPages, error) {
+	// the pages are sorted so that the first matching one is always the same
+	var names []string
+	for name := range pages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	errorPages := &ErrorPages{}
+	for _, name := range names {
+		config := pages[name]
+		handler, ok := handlers[config.Backend]
+		if !ok {
+			return nil, fmt.Errorf("Error creating ErrorPages: undefined backend %s for error page %s", config.Backend, name)
+		}
+		page := &errorPage{name: name, query: config.Query, handler: handler}
+		if page.query == "" {
+			page.query = "/"
+		}
+		for _, status := range config.Status {
+			statusRange, err := parseStatusRange(status)
+			if err != nil {
+				return nil, fmt.Errorf("Error creating ErrorPages: %v for error page %s", err, name)
+			}
+			page.ranges = append(page.ranges, statusRange)
+		}
+		errorPages.pages = append(errorPages.pages, page)
+	}
+	return errorPages, nil
+}
+
+// parseStatusRange parses a status code, e.g. 404, or a range of status codes, e.g. 500-599
+func parseStatusRange(status string) ([2]int, error) {
+	bounds := strings.SplitN(status, "-", 2)
+	from, err := strconv.Atoi(strings.TrimSpace(bounds[0]))
+	if err != nil {
+		return [2]int{}, fmt.Errorf("invalid status %s", status)
+	}
+	to := from
+	if len(bounds) == 2 {
+		to, err = strconv.Atoi(strings.TrimSpace(bounds[1]))
+		if err != nil || to < from {
+			return [2]int{}, fmt.Errorf("invalid status range %s", status)
+		}
+	}
+	return [2]int{from, to}, nil
+}
+
+func (e *ErrorPages) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if acceptsJSON(r) {
+		next(rw, r)
+		return
+	}
+	writer := &errorPageResponseWriter{
+		responseWriter: rw,
+		header:         make(http.Header),
+		request:        r,
+		pages:          e,
+	}
+	next(writer, r)
+	writer.WriteHeader(http.StatusOK)
+}
+
+// match returns the page of a status code, if any
+func (e *ErrorPages) match(code int) *errorPage {
+	for _, page := range e.pages {
+		for _, statusRange := range page.ranges {
+			if code >= statusRange[0] && code <= statusRange[1] {
+				return page
+			}
+		}
+	}
+	return nil
+}
+
+// serve sends the page to rw with the status code and some headers of the error it replaces,
+// returning false if the page could not be fetched from its backend
+func (p *errorPage) serve(rw http.ResponseWriter, r *http.Request, code int, header http.Header) bool {
+	query, err := url.Parse(strings.Replace(p.query, "{status}", strconv.Itoa(code), -1))
+	if err != nil {
+		log.Errorf("Error parsing the query of error page %s: %v", p.name, err)
+		return false
+	}
+	pageURL := *r.URL
+	pageURL.Path = query.Path
+	pageURL.RawPath = ""
+	pageURL.RawQuery = query.RawQuery
+	pageReq := r.WithContext(r.Context())
+	pageReq.Method = http.MethodGet
+	pageReq.URL = &pageURL
+	pageReq.RequestURI = pageURL.RequestURI()
+	pageReq.Body = nil
+	pageReq.ContentLength = 0
+	pageReq.TransferEncoding = nil
+	pageReq.Header = make(http.Header)
+	utils.CopyHeaders(pageReq.Header, r.Header)
+	// the whole page is needed, whatever the cache of the client
+	for _, name := range []string{"If-Match", "If-None-Match", "If-Modified-Since", "If-Unmodified-Since", "If-Range", "Range"} {
+		pageReq.Header.Del(name)
+	}
+	pageReq.Header.Set("X-Original-Url", r.URL.RequestURI())
+
+	recorder := NewRecorder()
+	recorder.responseWriter = rw
+	p.handler.ServeHTTP(recorder, pageReq)
+	if recorder.Code != http.StatusOK {
+		log.Errorf("Error fetching error page %s %s: status %d", p.name, pageURL.RequestURI(), recorder.Code)
+		return false
+	}
+	for _, name := range errorPageKeptHeaders {
+		if values, ok := header[name]; ok {
+			recorder.HeaderMap[name] = values
+		}
+	}
+	recorder.Code = code
+	recorder.sendTo(rw)
+	return true
+}
+
+// acceptsJSON returns true if the request comes from an API client accepting JSON
+func acceptsJSON(r *http.Request) bool {
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		if mediaType, _, err := mime.ParseMediaType(accept); err == nil && mediaType == "application/json" {
+			return true
+		}
+	}
+	return false
+}
+
+// errorPageResponseWriter holds the headers of a response until its status code is known,
+// and replaces its body by an error page if the status code has one
+type errorPageResponseWriter struct {
+	responseWriter http.ResponseWriter
+	header         http.Header
+	request        *http.Request
+	pages          *ErrorPages
+	wroteHeader    bool
+	replaced       bool
+}
+
+func (w *errorPageResponseWriter) Header() http.Header {
+	if w.wroteHeader {
+		return w.responseWriter.Header()
+	}
+	return w.header
+}
+
+func (w *errorPageResponseWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if page := w.pages.match(code); page != nil {
+		if page.serve(w.responseWriter, w.request, code, w.header) {
+			w.replaced = true
+			return
+		}
+	}
+	utils.CopyHeaders(w.responseWriter.Header(), w.header)
+	w.responseWriter.WriteHeader(code)
+}
+
+func (w *errorPageResponseWriter) Write(buf []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	if w.replaced {
+		return len(buf), nil
+	}
+	return w.responseWriter.Write(buf)
+}
+
+// Flush sends any buffered data to the client, unless the response has been replaced
+func (w *errorPageResponseWriter) Flush() {
+	if w.replaced {
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	if flusher, ok := w.responseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// CloseNotify returns a channel that receives at most a
+// single value (true) when the client connection has gone
+// away.
+func (w *errorPageResponseWriter) CloseNotify() <-chan bool {
+	return w.responseWriter.(http.CloseNotifier).CloseNotify()
+}
+
+// Hijack hijacks the connection
+func (w *errorPageResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.wroteHeader = true
+	return w.responseWriter.(http.Hijacker).Hijack()
+}
@@ -0,0 +1,122 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/codegangsta/negroni"
+	"github.com/containous/traefik/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var errorPageBackend = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/missing.html" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html")
+	w.Write([]byte(r.Method + " " + r.URL.RequestURI() + " for " + r.Header.Get("X-Original-Url")))
+})
+
+func statusHandler(code int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Header().Set("Retry-After", "10")
+		w.WriteHeader(code)
+		w.Write([]byte("upstream error"))
+	})
+}
+
+func TestErrorPages(t *testing.T) {
+	pages := map[string]*types.ErrorPage{
+		"server": {Status: []string{"500-599"}, Backend: "errors", Query: "/{status}.html?lang=en"},
+		"client": {Status: []string{"401", "403"}, Backend: "errors", Query: "/denied.html"},
+	}
+	cases := []struct {
+		code         int
+		expectedBody string
+	}{
+		{code: http.StatusServiceUnavailable, expectedBody: "GET /503.html?lang=en for /api/orders?id=1"},
+		{code: http.StatusForbidden, expectedBody: "GET /denied.html for /api/orders?id=1"},
+		{code: http.StatusNotFound, expectedBody: "upstream error"},
+		{code: http.StatusOK, expectedBody: "upstream error"},
+	}
+
+	errorPages, err := NewErrorPages(pages, map[string]http.Handler{"errors": errorPageBackend})
+	assert.NoError(t, err, "there should be no error")
+	for _, c := range cases {
+		handler := negroni.New(errorPages)
+		handler.UseHandler(statusHandler(c.code))
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest("POST", "/api/orders?id=1", nil))
+		assert.Equal(t, c.code, recorder.Code, "the status code should be kept")
+		assert.Equal(t, c.expectedBody, recorder.Body.String())
+		assert.Equal(t, "10", recorder.Header().Get("Retry-After"))
+		if c.expectedBody == "upstream error" {
+			assert.Equal(t, "text/plain", recorder.Header().Get("Content-Type"))
+		} else {
+			assert.Equal(t, "text/html", recorder.Header().Get("Content-Type"))
+		}
+	}
+}
+
+func TestErrorPagesJSON(t *testing.T) {
+	errorPages, err := NewErrorPages(map[string]*types.ErrorPage{
+		"server": {Status: []string{"500-599"}, Backend: "errors", Query: "/{status}.html"},
+	}, map[string]http.Handler{"errors": errorPageBackend})
+	assert.NoError(t, err, "there should be no error")
+	handler := negroni.New(errorPages)
+	handler.UseHandler(statusHandler(http.StatusBadGateway))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Accept", "text/html;q=0.9, application/json")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusBadGateway, recorder.Code)
+	assert.Equal(t, "upstream error", recorder.Body.String(), "API clients should get the original error")
+}
+
+func TestErrorPagesMissingPage(t *testing.T) {
+	errorPages, err := NewErrorPages(map[string]*types.ErrorPage{
+		"server": {Status: []string{"500-599"}, Backend: "errors", Query: "/missing.html"},
+	}, map[string]http.Handler{"errors": errorPageBackend})
+	assert.NoError(t, err, "there should be no error")
+	handler := negroni.New(errorPages)
+	handler.UseHandler(statusHandler(http.StatusInternalServerError))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Equal(t, "upstream error", recorder.Body.String(), "the original error should be sent if the page is missing")
+	assert.Equal(t, []string{"10"}, recorder.Header()["Retry-After"])
+}
+
+func TestErrorPagesHeaders(t *testing.T) {
+	errorPages, err := NewErrorPages(map[string]*types.ErrorPage{
+		"server": {Status: []string{"500-599"}, Backend: "errors", Query: "/{status}.html"},
+	}, map[string]http.Handler{"errors": errorPageBackend})
+	assert.NoError(t, err, "there should be no error")
+	handler := negroni.New(
+		NewSecurityHeaders(&types.SecurityHeaders{FrameDeny: true}),
+		NewHeaders(&types.Headers{Response: &types.HeaderOperations{Set: map[string]string{"X-Custom": "value"}}}),
+		errorPages,
+	)
+	handler.UseHandler(statusHandler(http.StatusServiceUnavailable))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, "GET /503.html for /", recorder.Body.String())
+	assert.Equal(t, "DENY", recorder.Header().Get("X-Frame-Options"), "the security headers should be added to the page")
+	assert.Equal(t, "value", recorder.Header().Get("X-Custom"), "the custom headers should be added to the page")
+}
+
+func TestErrorPagesInvalidConfig(t *testing.T) {
+	handlers := map[string]http.Handler{"errors": errorPageBackend}
+	for _, status := range []string{"5xx", "599-500", "500-abc"} {
+		_, err := NewErrorPages(map[string]*types.ErrorPage{"server": {Status: []string{status}, Backend: "errors"}}, handlers)
+		assert.Error(t, err, "status %s should be invalid", status)
+	}
+	_, err := NewErrorPages(map[string]*types.ErrorPage{"server": {Status: []string{"500"}, Backend: "other"}}, handlers)
+	assert.Error(t, err)
+}
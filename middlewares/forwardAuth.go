@@ -0,0 +1,135 @@
+package middlewares
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/types"
+)
+
+const defaultForwardAuthTimeout = 30 * time.Second
+
+// hopHeaders are the headers of the auth response that are not returned to the client
+var hopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Te",
+	"Trailers",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// forwardAuth delegates the authentication of the requests to an external service
+type forwardAuth struct {
+	address         string
+	requestHeaders  []string
+	responseHeaders []string
+	client          *http.Client
+}
+
+func newForwardAuth(config *types.Forward) (*forwardAuth, error) {
+	if _, err := url.ParseRequestURI(config.Address); err != nil {
+		return nil, fmt.Errorf("Error creating Authenticator: invalid forward address %s", config.Address)
+	}
+	transport := &http.Transport{Proxy: http.ProxyFromEnvironment}
+	if config.TLS != nil {
+		tlsConfig, err := config.TLS.CreateTLSConfig()
+		if err != nil {
+			return nil, fmt.Errorf("Error creating Authenticator: %v", err)
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+	timeout := time.Duration(config.Timeout)
+	if timeout <= 0 {
+		timeout = defaultForwardAuthTimeout
+	}
+	return &forwardAuth{
+		address:         config.Address,
+		requestHeaders:  config.AuthRequestHeaders,
+		responseHeaders: config.AuthResponseHeaders,
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   timeout,
+			// redirections, e.g. to a login page, are returned to the client
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}, nil
+}
+
+func (f *forwardAuth) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	authReq, err := http.NewRequest("GET", f.address, nil)
+	if err != nil {
+		log.Errorf("Error creating forward auth request to %s: %v", f.address, err)
+		http.Error(rw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	f.copyRequestHeaders(authReq.Header, r)
+
+	authResp, err := f.client.Do(authReq)
+	if err != nil {
+		log.Errorf("Error calling forward auth service %s: %v", f.address, err)
+		http.Error(rw, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+		return
+	}
+	defer authResp.Body.Close()
+
+	if authResp.StatusCode < 200 || authResp.StatusCode > 299 {
+		log.Debugf("Forward auth failed with status %d...", authResp.StatusCode)
+		for name, values := range authResp.Header {
+			rw.Header()[name] = values
+		}
+		for _, name := range hopHeaders {
+			rw.Header().Del(name)
+		}
+		rw.WriteHeader(authResp.StatusCode)
+		if _, err := io.Copy(rw, authResp.Body); err != nil {
+			log.Debugf("Error copying forward auth response: %v", err)
+		}
+		return
+	}
+
+	log.Debugf("Forward auth success...")
+	for _, name := range f.responseHeaders {
+		// never trust the values sent by the client
+		r.Header.Del(name)
+		for _, value := range authResp.Header[http.CanonicalHeaderKey(name)] {
+			r.Header.Add(name, value)
+		}
+	}
+	next(rw, r)
+}
+
+func (f *forwardAuth) copyRequestHeaders(header http.Header, r *http.Request) {
+	if len(f.requestHeaders) == 0 {
+		for name, values := range r.Header {
+			header[name] = values
+		}
+	} else {
+		for _, name := range f.requestHeaders {
+			if values, ok := r.Header[http.CanonicalHeaderKey(name)]; ok {
+				header[http.CanonicalHeaderKey(name)] = values
+			}
+		}
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	header.Set("X-Forwarded-Method", r.Method)
+	header.Set("X-Forwarded-Proto", scheme)
+	header.Set("X-Forwarded-Host", r.Host)
+	header.Set("X-Forwarded-Uri", r.URL.RequestURI())
+	if prior := r.Header.Get("X-Forwarded-For"); prior != "" {
+		header.Set("X-Forwarded-For", prior+", "+remoteIP(r))
+	} else {
+		header.Set("X-Forwarded-For", remoteIP(r))
+	}
+}
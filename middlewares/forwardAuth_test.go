@@ -0,0 +1,108 @@
+package middlewares
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/codegangsta/negroni"
+	"github.com/containous/traefik/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newForwardAuthServer(t *testing.T, forward *types.Forward) *httptest.Server {
+	authMiddleware, err := NewAuthenticator(&types.Auth{Forward: forward})
+	assert.NoError(t, err, "there should be no error")
+
+	n := negroni.New(authMiddleware)
+	n.UseHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "traefik %s", r.Header.Get("X-User-Id"))
+	}))
+	return httptest.NewServer(n)
+}
+
+func TestForwardAuthSuccess(t *testing.T) {
+	authServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "POST", r.Header.Get("X-Forwarded-Method"))
+		assert.Equal(t, "/foo?bar=1", r.Header.Get("X-Forwarded-Uri"))
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.Empty(t, r.Header.Get("X-Other"), "only the selected headers should be sent")
+		w.Header().Set("X-User-Id", "42")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer authServer.Close()
+
+	ts := newForwardAuthServer(t, &types.Forward{
+		Address:             authServer.URL,
+		AuthRequestHeaders:  []string{"Authorization"},
+		AuthResponseHeaders: []string{"X-User-Id"},
+	})
+	defer ts.Close()
+
+	req, err := http.NewRequest("POST", ts.URL+"/foo?bar=1", nil)
+	assert.NoError(t, err, "there should be no error")
+	req.Header.Set("Authorization", "Bearer token")
+	req.Header.Set("X-Other", "other")
+	req.Header.Set("X-User-Id", "spoofed")
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err, "there should be no error")
+	assert.Equal(t, http.StatusOK, res.StatusCode, "they should be equal")
+
+	body, err := ioutil.ReadAll(res.Body)
+	assert.NoError(t, err, "there should be no error")
+	assert.Equal(t, "traefik 42", string(body), "they should be equal")
+}
+
+func TestForwardAuthFail(t *testing.T) {
+	authServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "https://login.example.com")
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		w.WriteHeader(http.StatusFound)
+		fmt.Fprint(w, "login please")
+	}))
+	defer authServer.Close()
+
+	ts := newForwardAuthServer(t, &types.Forward{Address: authServer.URL})
+	defer ts.Close()
+
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	res, err := client.Get(ts.URL)
+	assert.NoError(t, err, "there should be no error")
+	assert.Equal(t, http.StatusFound, res.StatusCode, "the auth response should be returned verbatim")
+	assert.Equal(t, "https://login.example.com", res.Header.Get("Location"))
+	assert.Equal(t, "Bearer", res.Header.Get("WWW-Authenticate"))
+
+	body, err := ioutil.ReadAll(res.Body)
+	assert.NoError(t, err, "there should be no error")
+	assert.Equal(t, "login please", string(body), "they should be equal")
+}
+
+func TestForwardAuthTimeout(t *testing.T) {
+	authServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer authServer.Close()
+
+	ts := newForwardAuthServer(t, &types.Forward{
+		Address: authServer.URL,
+		Timeout: types.Duration(10 * time.Millisecond),
+	})
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL)
+	assert.NoError(t, err, "there should be no error")
+	assert.Equal(t, http.StatusBadGateway, res.StatusCode, "they should be equal")
+}
+
+func TestForwardAuthInvalidConfig(t *testing.T) {
+	_, err := NewAuthenticator(&types.Auth{Forward: &types.Forward{Address: "not an url"}})
+	assert.Error(t, err, "invalid addresses should be rejected")
+}
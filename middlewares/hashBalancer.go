@@ -0,0 +1,111 @@
+package middlewares
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/containous/traefik/types"
+)
+
+const defaultVirtualNodes = 100
+
+// hashRing is a consistent hash ring of the servers of a balancer: adding or removing a server
+// only moves the keys of its points to the next servers on the ring
+type hashRing struct {
+	virtualNodes int
+	points       hashPoints
+}
+
+type hashPoint struct {
+	hash   uint64
+	server *balancedServer
+}
+
+type hashPoints []hashPoint
+
+func (p hashPoints) Len() int           { return len(p) }
+func (p hashPoints) Less(i, j int) bool { return p[i].hash < p[j].hash }
+func (p hashPoints) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+
+// NewHashBalancer returns a new Balancer sending each request to the server of its key on a consistent hash ring,
+// each server having virtual nodes on the ring in proportion to its weight
+func NewHashBalancer(next http.Handler, key *types.HashKey) (*Balancer, error) {
+	if key == nil {
+		key = &types.HashKey{}
+	}
+	ring := &hashRing{virtualNodes: key.VirtualNodes}
+	if ring.virtualNodes < 0 {
+		return nil, fmt.Errorf("Error creating hash balancer: invalid number of virtual nodes %d", ring.virtualNodes)
+	}
+	if ring.virtualNodes == 0 {
+		ring.virtualNodes = defaultVirtualNodes
+	}
+	b := newBalancer(next, func(b *Balancer, r *http.Request) *balancedServer {
+		return b.ring.get(hashKey(r, key))
+	})
+	b.ring = ring
+	return b, nil
+}
+
+// hashKey returns the key of a request, its client IP if it has not the configured one
+func hashKey(r *http.Request, key *types.HashKey) string {
+	switch {
+	case key.Header != "":
+		if value := r.Header.Get(key.Header); value != "" {
+			return value
+		}
+	case key.Cookie != "":
+		if cookie, err := r.Cookie(key.Cookie); err == nil && cookie.Value != "" {
+			return cookie.Value
+		}
+	case key.Query != "":
+		if value := r.URL.Query().Get(key.Query); value != "" {
+			return value
+		}
+	case key.Path:
+		return r.URL.Path
+	}
+	return remoteIP(r)
+}
+
+func ringHash(key string) uint64 {
+	fnvHash := fnv.New64a()
+	fnvHash.Write([]byte(key))
+	h := fnvHash.Sum64()
+	// the finalizer of murmur3, as fnv spreads keys differing only by their last characters poorly
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
+
+// build places the points of the servers on the ring
+func (ring *hashRing) build(servers []*balancedServer) {
+	ring.points = nil
+	for _, server := range servers {
+		for i := 0; i < server.weight*ring.virtualNodes; i++ {
+			ring.points = append(ring.points, hashPoint{hash: ringHash(server.url.String() + "#" + strconv.Itoa(i)), server: server})
+		}
+	}
+	sort.Sort(ring.points)
+}
+
+// get returns the server of the first point after the hash of a key on the ring
+func (ring *hashRing) get(key string) *balancedServer {
+	if len(ring.points) == 0 {
+		return nil
+	}
+	h := ringHash(key)
+	i := sort.Search(len(ring.points), func(i int) bool {
+		return ring.points[i].hash >= h
+	})
+	if i == len(ring.points) {
+		i = 0
+	}
+	return ring.points[i].server
+}
@@ -0,0 +1,117 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/containous/traefik/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+// serversOfKeys returns the server of every key
+func serversOfKeys(b *Balancer, keys int) map[string]string {
+	servers := make(map[string]string)
+	for i := 0; i < keys; i++ {
+		key := "key-" + strconv.Itoa(i)
+		servers[key] = b.ring.get(key).url.Host
+	}
+	return servers
+}
+
+func TestHashKey(t *testing.T) {
+	req := httptest.NewRequest("GET", "http://traefik/users/42?tenant=acme", nil)
+	req.RemoteAddr = "192.168.1.1:4242"
+	req.Header.Set("X-User", "alice")
+	req.AddCookie(&http.Cookie{Name: "session", Value: "s3ss10n"})
+
+	cases := []struct {
+		key      *types.HashKey
+		expected string
+	}{
+		{key: &types.HashKey{}, expected: "192.168.1.1"},
+		{key: &types.HashKey{Header: "X-User"}, expected: "alice"},
+		{key: &types.HashKey{Cookie: "session"}, expected: "s3ss10n"},
+		{key: &types.HashKey{Query: "tenant"}, expected: "acme"},
+		{key: &types.HashKey{Path: true}, expected: "/users/42"},
+		{key: &types.HashKey{Header: "X-Missing"}, expected: "192.168.1.1"},
+		{key: &types.HashKey{Cookie: "missing"}, expected: "192.168.1.1"},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.expected, hashKey(req, c.key), "%+v", c.key)
+	}
+}
+
+func TestHashBalancerSameKeySameServer(t *testing.T) {
+	counter := newRequestCounter(nil)
+	b, err := NewHashBalancer(counter, &types.HashKey{Header: "X-User"})
+	assert.NoError(t, err, "there should be no error")
+	upsertServers(t, b, map[string]int{"10.0.0.1:80": 1, "10.0.0.2:80": 1, "10.0.0.3:80": 1})
+
+	for i := 0; i < 10; i++ {
+		req := httptest.NewRequest("GET", "http://traefik/", nil)
+		req.Header.Set("X-User", "alice")
+		b.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	assert.Len(t, counter.requests, 1, "the requests of a key should all go to the same server")
+}
+
+func TestHashBalancerConsistency(t *testing.T) {
+	b, err := NewHashBalancer(http.NotFoundHandler(), nil)
+	assert.NoError(t, err, "there should be no error")
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, b.UpsertServer(&url.URL{Scheme: "http", Host: "10.0.0." + strconv.Itoa(i) + ":80"}))
+	}
+	before := serversOfKeys(b, 10000)
+
+	removed := &url.URL{Scheme: "http", Host: "10.0.0.3:80"}
+	assert.NoError(t, b.RemoveServer(removed))
+	after := serversOfKeys(b, 10000)
+	moved := 0
+	for key, server := range before {
+		if server != after[key] {
+			moved++
+			assert.Equal(t, removed.Host, server, "only the keys of the removed server should move")
+		}
+	}
+	assert.InDelta(t, 1000, moved, 300, "a tenth of the keys should move")
+
+	assert.NoError(t, b.UpsertServer(removed))
+	assert.Equal(t, before, serversOfKeys(b, 10000), "the keys should go back to the server added again")
+
+	assert.NoError(t, b.UpsertServer(&url.URL{Scheme: "http", Host: "10.0.0.10:80"}))
+	moved = 0
+	for key, server := range serversOfKeys(b, 10000) {
+		if server != before[key] {
+			moved++
+			assert.Equal(t, "10.0.0.10:80", server, "only the keys of the added server should move")
+		}
+	}
+	assert.InDelta(t, 10000/11, moved, 300, "an eleventh of the keys should move")
+}
+
+func TestHashBalancerDistribution(t *testing.T) {
+	b, err := NewHashBalancer(http.NotFoundHandler(), nil)
+	assert.NoError(t, err, "there should be no error")
+	for i := 0; i < 4; i++ {
+		assert.NoError(t, b.UpsertServer(&url.URL{Scheme: "http", Host: "10.0.0." + strconv.Itoa(i) + ":80"}))
+	}
+	assert.NoError(t, b.UpsertServer(&url.URL{Scheme: "http", Host: "10.0.0.0:80"}, roundrobin.Weight(2)))
+
+	keys := make(map[string]int)
+	for _, server := range serversOfKeys(b, 10000) {
+		keys[server]++
+	}
+	assert.InDelta(t, 4000, keys["10.0.0.0:80"], 800, "a server of weight 2 should get twice as many keys: %v", keys)
+	for i := 1; i < 4; i++ {
+		assert.InDelta(t, 2000, keys["10.0.0."+strconv.Itoa(i)+":80"], 600, "the keys should be spread evenly: %v", keys)
+	}
+}
+
+func TestNewHashBalancerInvalidVirtualNodes(t *testing.T) {
+	_, err := NewHashBalancer(http.NotFoundHandler(), &types.HashKey{VirtualNodes: -1})
+	assert.Error(t, err)
+}
@@ -0,0 +1,54 @@
+package middlewares
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// headerHookResponseWriter is a http.ResponseWriter that gives a middleware a
+// last chance to modify the response headers just before they are sent to the
+// client, i.e. after the backend has set its own.
+type headerHookResponseWriter struct {
+	http.ResponseWriter
+	hook        func(header http.Header)
+	wroteHeader bool
+}
+
+func newHeaderHookResponseWriter(rw http.ResponseWriter, hook func(header http.Header)) *headerHookResponseWriter {
+	return &headerHookResponseWriter{ResponseWriter: rw, hook: hook}
+}
+
+func (hw *headerHookResponseWriter) WriteHeader(code int) {
+	if !hw.wroteHeader {
+		hw.wroteHeader = true
+		hw.hook(hw.ResponseWriter.Header())
+	}
+	hw.ResponseWriter.WriteHeader(code)
+}
+
+func (hw *headerHookResponseWriter) Write(b []byte) (int, error) {
+	if !hw.wroteHeader {
+		hw.WriteHeader(http.StatusOK)
+	}
+	return hw.ResponseWriter.Write(b)
+}
+
+func (hw *headerHookResponseWriter) Flush() {
+	if flusher, ok := hw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (hw *headerHookResponseWriter) CloseNotify() <-chan bool {
+	return hw.ResponseWriter.(http.CloseNotifier).CloseNotify()
+}
+
+func (hw *headerHookResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := hw.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("the ResponseWriter doesn't support the Hijacker interface")
+	}
+	return hijacker.Hijack()
+}
@@ -0,0 +1,65 @@
+package middlewares
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/containous/traefik/types"
+)
+
+// Headers is a middleware that removes, overwrites and adds headers on the requests
+// sent to the backend and on the responses returned to the client.
+type Headers struct {
+	request  *types.HeaderOperations
+	response *types.HeaderOperations
+}
+
+// NewHeaders builds a new Headers given a config
+func NewHeaders(config *types.Headers) *Headers {
+	return &Headers{
+		request:  config.Request,
+		response: config.Response,
+	}
+}
+
+func (h *Headers) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if h.request != nil {
+		applyHeaderOperations(r.Header, h.request, r)
+	}
+	if h.response != nil {
+		rw = newHeaderHookResponseWriter(rw, func(header http.Header) {
+			applyHeaderOperations(header, h.response, r)
+		})
+	}
+	next(rw, r)
+}
+
+func applyHeaderOperations(header http.Header, operations *types.HeaderOperations, r *http.Request) {
+	for _, name := range operations.Remove {
+		header.Del(strings.TrimSpace(name))
+	}
+	for name, value := range operations.Set {
+		header.Set(name, expandHeaderPlaceholders(value, r))
+	}
+	for name, value := range operations.Add {
+		header.Add(name, expandHeaderPlaceholders(value, r))
+	}
+}
+
+// expandHeaderPlaceholders replaces the {client.ip}, {request.id}, {request.host}
+// and {request.scheme} placeholders of a header value.
+func expandHeaderPlaceholders(value string, r *http.Request) string {
+	if !strings.Contains(value, "{") {
+		return value
+	}
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return strings.NewReplacer(
+		"{client.ip}", remoteIP(r),
+		"{request.id}", GetRequestID(r),
+		"{request.host}", r.Host,
+		"{request.scheme}", scheme,
+	).Replace(value)
+}
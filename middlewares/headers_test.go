@@ -0,0 +1,68 @@
+package middlewares
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/codegangsta/negroni"
+	"github.com/containous/traefik/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeadersRequest(t *testing.T) {
+	var upstream http.Header
+	n := negroni.New(NewHeaders(&types.Headers{
+		Request: &types.HeaderOperations{
+			Remove: []string{"X-Secret"},
+			Set: map[string]string{
+				"X-Forwarded-Proto": "{request.scheme}",
+				"X-Client":          "{client.ip} for {request.host}",
+			},
+			Add: map[string]string{"X-Tag": "traefik"},
+		},
+	}))
+	n.UseHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstream = r.Header
+	}))
+
+	req := httptest.NewRequest("GET", "https://example.com/", nil)
+	req.TLS = &tls.ConnectionState{}
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("X-Secret", "foo")
+	req.Header.Set("X-Forwarded-Proto", "http")
+	req.Header.Set("X-Tag", "client")
+	n.ServeHTTP(httptest.NewRecorder(), req)
+
+	assert.Empty(t, upstream.Get("X-Secret"))
+	assert.Equal(t, "https", upstream.Get("X-Forwarded-Proto"))
+	assert.Equal(t, "10.0.0.1 for example.com", upstream.Get("X-Client"))
+	assert.Equal(t, []string{"client", "traefik"}, upstream["X-Tag"])
+}
+
+func TestHeadersResponse(t *testing.T) {
+	requestID, err := NewRequestID(&types.RequestID{})
+	assert.NoError(t, err, "there should be no error")
+	n := negroni.New(requestID, NewHeaders(&types.Headers{
+		Response: &types.HeaderOperations{
+			Remove: []string{"Server", "X-Powered-By"},
+			Set:    map[string]string{"X-Trace": "{request.id}"},
+		},
+	}))
+	n.UseHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "Apache")
+		w.Header().Set("X-Powered-By", "PHP")
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte("ok"))
+	}))
+
+	recorder := httptest.NewRecorder()
+	n.ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+
+	assert.Empty(t, recorder.Header().Get("Server"))
+	assert.Empty(t, recorder.Header().Get("X-Powered-By"))
+	assert.Equal(t, "text/plain", recorder.Header().Get("Content-Type"))
+	assert.NotEmpty(t, recorder.Header().Get("X-Trace"))
+	assert.Equal(t, recorder.Header().Get("X-Request-ID"), recorder.Header().Get("X-Trace"))
+}
@@ -0,0 +1,71 @@
+package middlewares
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/codegangsta/negroni"
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/types"
+)
+
+// IPFilter is a middleware that denies the requests whose client IP does not match
+// the whitelist or matches the blacklist, with a 403.
+type IPFilter struct {
+	name      string
+	whitelist []*net.IPNet
+	blacklist []*net.IPNet
+	depth     int
+	// DeniedTap, if set, is given the denied requests, e.g. to audit them
+	DeniedTap negroni.Handler
+}
+
+// NewIPFilter builds a new IPFilter given a config, name being the entrypoint or frontend it applies to
+func NewIPFilter(name string, config *types.IPFilter) (*IPFilter, error) {
+	if config == nil || (len(config.Whitelist) == 0 && len(config.Blacklist) == 0) {
+		return nil, fmt.Errorf("Error creating IPFilter: no source range defined")
+	}
+	if config.ForwardedForDepth < 0 {
+		return nil, fmt.Errorf("Error creating IPFilter: negative forwardedForDepth %d", config.ForwardedForDepth)
+	}
+	whitelist, err := parseIPNets(config.Whitelist)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating IPFilter: %v", err)
+	}
+	blacklist, err := parseIPNets(config.Blacklist)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating IPFilter: %v", err)
+	}
+	return &IPFilter{
+		name:      name,
+		whitelist: whitelist,
+		blacklist: blacklist,
+		depth:     config.ForwardedForDepth,
+	}, nil
+}
+
+func (f *IPFilter) allowed(ip string) bool {
+	if len(f.whitelist) > 0 && !containsIP(f.whitelist, ip) {
+		return false
+	}
+	return !containsIP(f.blacklist, ip)
+}
+
+func (f *IPFilter) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	ip := forwardedIP(r, f.depth)
+	if ip != "" && f.allowed(ip) {
+		next(rw, r)
+		return
+	}
+	log.Debugf("Source IP %s denied on %s", ip, f.name)
+	if f.DeniedTap != nil {
+		f.DeniedTap.ServeHTTP(rw, r, forbidden)
+		return
+	}
+	forbidden(rw, r)
+}
+
+func forbidden(rw http.ResponseWriter, r *http.Request) {
+	http.Error(rw, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+}
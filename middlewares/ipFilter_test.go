@@ -0,0 +1,93 @@
+package middlewares
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/codegangsta/negroni"
+	"github.com/containous/traefik/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIPFilterWhitelist(t *testing.T) {
+	ipFilter, err := NewIPFilter("frontend1", &types.IPFilter{
+		Whitelist: []string{"10.0.0.0/8", "2001:db8::/32", "192.168.1.1"},
+	})
+	assert.NoError(t, err, "there should be no error")
+	handler := negroni.New(ipFilter)
+	handler.UseHandler(echoHandler)
+
+	assert.Equal(t, http.StatusOK, statusFor(handler, "10.1.2.3:1234", nil).Code)
+	assert.Equal(t, http.StatusOK, statusFor(handler, "[2001:db8::1]:1234", nil).Code)
+	assert.Equal(t, http.StatusOK, statusFor(handler, "192.168.1.1:1234", nil).Code)
+	assert.Equal(t, http.StatusForbidden, statusFor(handler, "192.168.1.2:1234", nil).Code)
+	assert.Equal(t, http.StatusForbidden, statusFor(handler, "[2001:db9::1]:1234", nil).Code)
+	assert.Equal(t, http.StatusForbidden, statusFor(handler, "8.8.8.8:1234", map[string]string{"X-Forwarded-For": "10.1.2.3"}).Code, "X-Forwarded-For should be ignored by default")
+}
+
+func TestIPFilterBlacklist(t *testing.T) {
+	ipFilter, err := NewIPFilter("frontend1", &types.IPFilter{
+		Whitelist: []string{"10.0.0.0/8"},
+		Blacklist: []string{"10.0.0.0/24"},
+	})
+	assert.NoError(t, err, "there should be no error")
+	handler := negroni.New(ipFilter)
+	handler.UseHandler(echoHandler)
+
+	assert.Equal(t, http.StatusOK, statusFor(handler, "10.1.2.3:1234", nil).Code)
+	assert.Equal(t, http.StatusForbidden, statusFor(handler, "10.0.0.3:1234", nil).Code)
+
+	ipFilter, err = NewIPFilter("frontend1", &types.IPFilter{
+		Blacklist: []string{"8.8.8.8"},
+	})
+	assert.NoError(t, err, "there should be no error")
+	handler = negroni.New(ipFilter)
+	handler.UseHandler(echoHandler)
+	assert.Equal(t, http.StatusOK, statusFor(handler, "10.1.2.3:1234", nil).Code)
+	assert.Equal(t, http.StatusForbidden, statusFor(handler, "8.8.8.8:1234", nil).Code)
+}
+
+func TestIPFilterForwardedForDepth(t *testing.T) {
+	ipFilter, err := NewIPFilter("frontend1", &types.IPFilter{
+		Whitelist:         []string{"10.0.0.0/8"},
+		ForwardedForDepth: 2,
+	})
+	assert.NoError(t, err, "there should be no error")
+	handler := negroni.New(ipFilter)
+	handler.UseHandler(echoHandler)
+
+	assert.Equal(t, http.StatusOK, statusFor(handler, "8.8.8.8:1234", map[string]string{"X-Forwarded-For": "1.1.1.1, 10.1.2.3, 8.8.4.4"}).Code)
+	assert.Equal(t, http.StatusForbidden, statusFor(handler, "10.0.0.1:1234", map[string]string{"X-Forwarded-For": "10.1.2.3, 1.1.1.1, 8.8.4.4"}).Code)
+	assert.Equal(t, http.StatusForbidden, statusFor(handler, "8.8.8.8:1234", map[string]string{"X-Forwarded-For": "10.1.2.3"}).Code, "requests with too few hops should be denied")
+	assert.Equal(t, http.StatusForbidden, statusFor(handler, "10.0.0.1:1234", nil).Code, "the trusted proxy should not be taken for the client")
+}
+
+func TestIPFilterDeniedTap(t *testing.T) {
+	ipFilter, err := NewIPFilter("frontend1", &types.IPFilter{Whitelist: []string{"10.0.0.0/8"}})
+	assert.NoError(t, err, "there should be no error")
+	tapped := 0
+	ipFilter.DeniedTap = negroni.HandlerFunc(func(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+		tapped++
+		next(rw, r)
+	})
+	n := negroni.New(ipFilter)
+	n.UseHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	assert.Equal(t, http.StatusOK, statusFor(n, "10.1.2.3:1234", nil).Code)
+	assert.Equal(t, 0, tapped)
+	assert.Equal(t, http.StatusForbidden, statusFor(n, "8.8.8.8:1234", nil).Code)
+	assert.Equal(t, 1, tapped)
+}
+
+func TestIPFilterInvalidConfig(t *testing.T) {
+	_, err := NewIPFilter("frontend1", &types.IPFilter{})
+	assert.Error(t, err, "a filter without source range should be rejected")
+
+	_, err = NewIPFilter("frontend1", &types.IPFilter{Whitelist: []string{"10.0.0.0/33"}})
+	assert.Error(t, err, "invalid CIDRs should be rejected")
+
+	_, err = NewIPFilter("frontend1", &types.IPFilter{Blacklist: []string{"foo"}})
+	assert.Error(t, err, "invalid IPs should be rejected")
+}
@@ -0,0 +1,210 @@
+package middlewares
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/log"
+)
+
+const (
+	defaultJWKSRefreshInterval = time.Hour
+	// unknown key IDs do not load the keys again more often than this
+	minJWKSRefreshInterval = 10 * time.Second
+)
+
+// jwk is a JSON Web Key, as defined by RFC 7517
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	K   string `json:"k"`
+}
+
+// verificationKey is a key of a JWKS, with the signing method it can verify
+type verificationKey struct {
+	alg string
+	key interface{}
+}
+
+// jwks caches the keys of a JSON Web Key Set read from a file or an URL
+type jwks struct {
+	source          string
+	refreshInterval time.Duration
+	client          *http.Client
+	now             func() time.Time
+
+	mutex    sync.RWMutex
+	keys     map[string]*verificationKey
+	loadedAt time.Time
+}
+
+func newJWKS(source string, refreshInterval time.Duration) *jwks {
+	if refreshInterval <= 0 {
+		refreshInterval = defaultJWKSRefreshInterval
+	}
+	return &jwks{
+		source:          source,
+		refreshInterval: refreshInterval,
+		client:          &http.Client{Timeout: 10 * time.Second},
+		now:             time.Now,
+		keys:            map[string]*verificationKey{},
+	}
+}
+
+// key returns the key of the given ID, the only key of the set if kid is empty.
+// The keys are loaded again if they are too old or if the ID is unknown, e.g. after a key rotation.
+func (s *jwks) key(kid string) (*verificationKey, error) {
+	s.mutex.RLock()
+	key, found := s.lookup(kid)
+	loadedAt := s.loadedAt
+	s.mutex.RUnlock()
+
+	age := s.now().Sub(loadedAt)
+	if age > s.refreshInterval || (!found && age > minJWKSRefreshInterval) {
+		if err := s.load(loadedAt); err != nil {
+			log.Errorf("Error loading JWKS %s: %v", s.source, err)
+		}
+		s.mutex.RLock()
+		key, found = s.lookup(kid)
+		s.mutex.RUnlock()
+	}
+	if !found {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+	return key, nil
+}
+
+func (s *jwks) lookup(kid string) (*verificationKey, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+// load reads the keys again, keeping the current ones if they cannot be read.
+// Nothing is done if they have been loaded by another request since seenLoadedAt.
+func (s *jwks) load(seenLoadedAt time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !s.loadedAt.Equal(seenLoadedAt) {
+		return nil
+	}
+	// whatever happens, do not try again before the next refresh
+	s.loadedAt = s.now()
+
+	data, err := s.read()
+	if err != nil {
+		return err
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return err
+	}
+	keys := map[string]*verificationKey{}
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.verificationKey()
+		if err != nil {
+			log.Warnf("Skipping key %q of JWKS %s: %v", k.Kid, s.source, err)
+			continue
+		}
+		keys[k.Kid] = key
+	}
+	s.keys = keys
+	return nil
+}
+
+func (s *jwks) read() ([]byte, error) {
+	if !strings.HasPrefix(s.source, "http://") && !strings.HasPrefix(s.source, "https://") {
+		return ioutil.ReadFile(s.source)
+	}
+	resp, err := s.client.Get(s.source)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+func (k *jwk) verificationKey() (*verificationKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeJWKInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeJWKInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return k.withAlg("RS256", &rsa.PublicKey{N: n, E: int(e.Int64())})
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %s", k.Crv)
+		}
+		x, err := decodeJWKInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeJWKInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !elliptic.P256().IsOnCurve(x, y) {
+			return nil, fmt.Errorf("invalid P-256 point")
+		}
+		return k.withAlg("ES256", &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y})
+	case "oct":
+		secret, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(k.K, "="))
+		if err != nil {
+			return nil, err
+		}
+		return k.withAlg("HS256", secret)
+	}
+	return nil, fmt.Errorf("unsupported key type %s", k.Kty)
+}
+
+func (k *jwk) withAlg(alg string, key interface{}) (*verificationKey, error) {
+	if k.Alg != "" && k.Alg != alg {
+		return nil, fmt.Errorf("unsupported algorithm %s", k.Alg)
+	}
+	return &verificationKey{alg: alg, key: key}, nil
+}
+
+func decodeJWKInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty integer")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,189 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/types"
+	"github.com/dgrijalva/jwt-go"
+)
+
+// jwtAuth validates the bearer tokens of the requests against the keys of a JWKS
+type jwtAuth struct {
+	keys           *jwks
+	issuer         string
+	audience       string
+	requiredScopes []string
+	requiredClaims map[string]string
+	forwardClaims  map[string]string
+	headerField    string
+	parser         *jwt.Parser
+}
+
+// jwtError is a RFC 6750 bearer token error
+type jwtError struct {
+	status      int
+	code        string
+	description string
+}
+
+func newJWTAuth(config *types.JWT, headerField string) (*jwtAuth, error) {
+	if config.JWKS == "" {
+		return nil, fmt.Errorf("Error creating Authenticator: no JWKS defined")
+	}
+	keys := newJWKS(config.JWKS, time.Duration(config.JWKSRefreshInterval))
+	if err := keys.load(time.Time{}); err != nil {
+		if !strings.HasPrefix(config.JWKS, "http://") && !strings.HasPrefix(config.JWKS, "https://") {
+			return nil, fmt.Errorf("Error creating Authenticator: %v", err)
+		}
+		// the keys will be loaded again by the next requests
+		log.Errorf("Error loading JWKS %s: %v", config.JWKS, err)
+	}
+	return &jwtAuth{
+		keys:           keys,
+		issuer:         config.Issuer,
+		audience:       config.Audience,
+		requiredScopes: config.RequiredScopes,
+		requiredClaims: config.RequiredClaims,
+		forwardClaims:  config.ForwardClaims,
+		headerField:    headerField,
+		parser:         &jwt.Parser{ValidMethods: []string{"RS256", "ES256", "HS256"}},
+	}, nil
+}
+
+func (j *jwtAuth) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	claims, jwtErr := j.validate(r)
+	if jwtErr != nil {
+		log.Debugf("JWT auth failed: %s %s", jwtErr.code, jwtErr.description)
+		j.requireAuth(rw, jwtErr)
+		return
+	}
+	log.Debugf("JWT auth success...")
+
+	for claim, header := range j.forwardClaims {
+		// never trust the values sent by the client
+		r.Header.Del(header)
+		if value, ok := claimString(claims[claim]); ok {
+			r.Header.Set(header, value)
+		}
+	}
+	subject, _ := claims["sub"].(string)
+	if j.headerField != "" && subject != "" {
+		r.Header[j.headerField] = []string{subject}
+	}
+	next.ServeHTTP(rw, withAuthUser(r, subject))
+}
+
+func (j *jwtAuth) validate(r *http.Request) (jwt.MapClaims, *jwtError) {
+	authorization := r.Header.Get("Authorization")
+	if authorization == "" {
+		return nil, &jwtError{status: http.StatusUnauthorized}
+	}
+	if len(authorization) < 7 || !strings.EqualFold(authorization[:7], "Bearer ") {
+		return nil, &jwtError{http.StatusBadRequest, "invalid_request", "bearer token expected"}
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := j.parser.ParseWithClaims(strings.TrimSpace(authorization[7:]), claims, j.keyFor)
+	if err != nil {
+		return nil, &jwtError{http.StatusUnauthorized, "invalid_token", err.Error()}
+	}
+	// the parser only checks exp when it is set, a token without it would never expire
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return nil, &jwtError{http.StatusUnauthorized, "invalid_token", "token without expiration"}
+	}
+	if j.issuer != "" && !claims.VerifyIssuer(j.issuer, true) {
+		return nil, &jwtError{http.StatusUnauthorized, "invalid_token", "unexpected issuer"}
+	}
+	if j.audience != "" && !claimContains(claims["aud"], j.audience) {
+		return nil, &jwtError{http.StatusUnauthorized, "invalid_token", "unexpected audience"}
+	}
+
+	scopes := claims["scp"]
+	if scope, ok := claims["scope"].(string); ok {
+		scopes = strings.Fields(scope)
+	}
+	for _, scope := range j.requiredScopes {
+		if !claimContains(scopes, scope) {
+			return nil, &jwtError{http.StatusForbidden, "insufficient_scope", "scope " + scope + " required"}
+		}
+	}
+	for claim, value := range j.requiredClaims {
+		if !claimContains(claims[claim], value) {
+			return nil, &jwtError{http.StatusForbidden, "insufficient_scope", "claim " + claim + " does not match"}
+		}
+	}
+	return claims, nil
+}
+
+// keyFor returns the key of the JWKS the token is signed with, if the signing method matches
+func (j *jwtAuth) keyFor(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, err := j.keys.key(kid)
+	if err != nil {
+		return nil, err
+	}
+	if token.Method.Alg() != key.alg {
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+	return key.key, nil
+}
+
+func (j *jwtAuth) requireAuth(rw http.ResponseWriter, jwtErr *jwtError) {
+	challenge := `Bearer realm="traefik"`
+	if jwtErr.code != "" {
+		challenge += fmt.Sprintf(`, error="%s", error_description="%s"`, jwtErr.code, strings.Replace(jwtErr.description, `"`, `'`, -1))
+	}
+	if jwtErr.code == "insufficient_scope" && len(j.requiredScopes) > 0 {
+		challenge += fmt.Sprintf(`, scope="%s"`, strings.Join(j.requiredScopes, " "))
+	}
+	rw.Header().Set("WWW-Authenticate", challenge)
+	http.Error(rw, http.StatusText(jwtErr.status), jwtErr.status)
+}
+
+// claimContains tells whether a claim is the given value or a list containing it
+func claimContains(claim interface{}, value string) bool {
+	switch c := claim.(type) {
+	case []interface{}:
+		for _, item := range c {
+			if s, ok := claimString(item); ok && s == value {
+				return true
+			}
+		}
+	case []string:
+		for _, item := range c {
+			if item == value {
+				return true
+			}
+		}
+	default:
+		if s, ok := claimString(c); ok && s == value {
+			return true
+		}
+	}
+	return false
+}
+
+// claimString formats a claim as a header value, lists being comma separated
+func claimString(claim interface{}) (string, bool) {
+	switch c := claim.(type) {
+	case nil:
+		return "", false
+	case string:
+		return c, true
+	case []interface{}:
+		var items []string
+		for _, item := range c {
+			if s, ok := claimString(item); ok {
+				items = append(items, s)
+			}
+		}
+		return strings.Join(items, ","), true
+	case map[string]interface{}:
+		return "", false
+	}
+	return fmt.Sprint(claim), true
+}
@@ -0,0 +1,221 @@
+package middlewares
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/codegangsta/negroni"
+	"github.com/containous/traefik/types"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+)
+
+type testJWTKeys struct {
+	rsaKey  *rsa.PrivateKey
+	ecKey   *ecdsa.PrivateKey
+	hmacKey []byte
+}
+
+func generateJWTKeys(t *testing.T) *testJWTKeys {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err, "there should be no error")
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err, "there should be no error")
+	return &testJWTKeys{rsaKey: rsaKey, ecKey: ecKey, hmacKey: []byte("a very secret shared key")}
+}
+
+func encodeJWKInt(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.Bytes())
+}
+
+func (k *testJWTKeys) jwks(rsaKid string) string {
+	set := map[string][]map[string]string{
+		"keys": {
+			{"kid": rsaKid, "kty": "RSA", "alg": "RS256", "use": "sig", "n": encodeJWKInt(k.rsaKey.N), "e": encodeJWKInt(big.NewInt(int64(k.rsaKey.E)))},
+			{"kid": "ec", "kty": "EC", "crv": "P-256", "x": encodeJWKInt(k.ecKey.X), "y": encodeJWKInt(k.ecKey.Y)},
+			{"kid": "hmac", "kty": "oct", "k": base64.RawURLEncoding.EncodeToString(k.hmacKey)},
+		},
+	}
+	data, _ := json.Marshal(set)
+	return string(data)
+}
+
+func signJWT(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	assert.NoError(t, err, "there should be no error")
+	return signed
+}
+
+func jwtRequest(handler http.Handler, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	req.Header.Set("X-Roles", "spoofed")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func writeJWKSFile(t *testing.T, content string) string {
+	file, err := ioutil.TempFile("", "jwks")
+	assert.NoError(t, err, "there should be no error")
+	_, err = file.WriteString(content)
+	assert.NoError(t, err, "there should be no error")
+	file.Close()
+	return file.Name()
+}
+
+func TestJWTAuth(t *testing.T) {
+	keys := generateJWTKeys(t)
+	jwksFile := writeJWKSFile(t, keys.jwks("rsa"))
+	defer os.Remove(jwksFile)
+
+	authMiddleware, err := NewAuthenticator(&types.Auth{
+		JWT: &types.JWT{
+			JWKS:           jwksFile,
+			Issuer:         "https://issuer.example.com",
+			Audience:       "api",
+			RequiredScopes: []string{"read"},
+			RequiredClaims: map[string]string{"tenant": "acme"},
+			ForwardClaims:  map[string]string{"roles": "X-Roles"},
+		},
+		HeaderField: "X-Webauth-User",
+	})
+	assert.NoError(t, err, "there should be no error")
+	handler := negroni.New(authMiddleware)
+	handler.UseHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s %s %s", GetAuthUser(r), r.Header.Get("X-Webauth-User"), r.Header.Get("X-Roles"))
+	}))
+
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub":    "alice",
+			"iss":    "https://issuer.example.com",
+			"aud":    []string{"other", "api"},
+			"exp":    time.Now().Add(time.Hour).Unix(),
+			"scope":  "read write",
+			"tenant": "acme",
+			"roles":  []string{"admin", "user"},
+		}
+	}
+
+	for _, token := range []string{
+		signJWT(t, jwt.SigningMethodRS256, "rsa", keys.rsaKey, valid()),
+		signJWT(t, jwt.SigningMethodES256, "ec", keys.ecKey, valid()),
+		signJWT(t, jwt.SigningMethodHS256, "hmac", keys.hmacKey, valid()),
+	} {
+		recorder := jwtRequest(handler, token)
+		assert.Equal(t, http.StatusOK, recorder.Code, recorder.Header().Get("WWW-Authenticate"))
+		assert.Equal(t, "alice alice admin,user", recorder.Body.String())
+	}
+
+	recorder := jwtRequest(handler, "")
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Equal(t, `Bearer realm="traefik"`, recorder.Header().Get("WWW-Authenticate"))
+
+	invalidTokens := map[string]jwt.MapClaims{
+		"expired":        {"exp": time.Now().Add(-time.Minute).Unix()},
+		"not yet valid":  {"nbf": time.Now().Add(time.Hour).Unix()},
+		"wrong issuer":   {"iss": "https://evil.example.com"},
+		"wrong audience": {"aud": "other"},
+	}
+	for name, overrides := range invalidTokens {
+		claims := valid()
+		for claim, value := range overrides {
+			claims[claim] = value
+		}
+		recorder = jwtRequest(handler, signJWT(t, jwt.SigningMethodRS256, "rsa", keys.rsaKey, claims))
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code, name)
+		assert.Contains(t, recorder.Header().Get("WWW-Authenticate"), `error="invalid_token"`, name)
+	}
+
+	claims := valid()
+	delete(claims, "exp")
+	recorder = jwtRequest(handler, signJWT(t, jwt.SigningMethodRS256, "rsa", keys.rsaKey, claims))
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code, "a token without expiration should be rejected")
+
+	claims = valid()
+	claims["scope"] = "write"
+	recorder = jwtRequest(handler, signJWT(t, jwt.SigningMethodRS256, "rsa", keys.rsaKey, claims))
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	assert.Contains(t, recorder.Header().Get("WWW-Authenticate"), `error="insufficient_scope"`)
+	assert.Contains(t, recorder.Header().Get("WWW-Authenticate"), `scope="read"`)
+
+	claims = valid()
+	claims["tenant"] = "other"
+	recorder = jwtRequest(handler, signJWT(t, jwt.SigningMethodRS256, "rsa", keys.rsaKey, claims))
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+
+	// a HS256 token cannot be verified with the RSA key, whatever its secret
+	recorder = jwtRequest(handler, signJWT(t, jwt.SigningMethodHS256, "rsa", keys.hmacKey, valid()))
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	recorder = jwtRequest(handler, "not.a.token")
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Basic dGVzdDp0ZXN0")
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Header().Get("WWW-Authenticate"), `error="invalid_request"`)
+}
+
+func TestJWTAuthKeyRotation(t *testing.T) {
+	keys := generateJWTKeys(t)
+	var mutex sync.Mutex
+	currentJWKS := keys.jwks("rsa1")
+	jwksServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		fmt.Fprint(w, currentJWKS)
+	}))
+	defer jwksServer.Close()
+
+	auth, err := newJWTAuth(&types.JWT{JWKS: jwksServer.URL}, "")
+	assert.NoError(t, err, "there should be no error")
+	now := time.Now()
+	auth.keys.now = func() time.Time {
+		return now
+	}
+	// the keys have been loaded with the real clock
+	auth.keys.loadedAt = now
+	n := negroni.New(auth)
+	n.UseHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	claims := jwt.MapClaims{"sub": "alice", "exp": time.Now().Add(time.Hour).Unix()}
+	assert.Equal(t, http.StatusOK, jwtRequest(n, signJWT(t, jwt.SigningMethodRS256, "rsa1", keys.rsaKey, claims)).Code)
+
+	mutex.Lock()
+	currentJWKS = keys.jwks("rsa2")
+	mutex.Unlock()
+	rotated := signJWT(t, jwt.SigningMethodRS256, "rsa2", keys.rsaKey, claims)
+	assert.Equal(t, http.StatusUnauthorized, jwtRequest(n, rotated).Code, "the keys should not be loaded again that soon")
+
+	now = now.Add(time.Minute)
+	assert.Equal(t, http.StatusOK, jwtRequest(n, rotated).Code, "an unknown key should load the keys again")
+}
+
+func TestJWTAuthInvalidConfig(t *testing.T) {
+	_, err := NewAuthenticator(&types.Auth{JWT: &types.JWT{}})
+	assert.Error(t, err, "a JWKS is required")
+
+	_, err = NewAuthenticator(&types.Auth{JWT: &types.JWT{JWKS: "/does/not/exist.json"}})
+	assert.Error(t, err, "a missing JWKS file should be rejected")
+}
@@ -48,11 +48,12 @@ var (
 // logInfoResponseWriter is a wrapper of type http.ResponseWriter
 // that tracks frontend and backend names and request status and size
 type logInfoResponseWriter struct {
-	rw       http.ResponseWriter
-	backend  string
-	frontend string
-	status   int
-	size     int
+	rw        http.ResponseWriter
+	backend   string
+	frontend  string
+	requestID string
+	status    int
+	size      int
 }
 
 // logEntry is a single log entry for use in encoding to json
@@ -184,6 +185,15 @@ func saveBackendNameForLogger(r *http.Request, backendName string) {
 	}
 }
 
+// Save the request ID for the Logger
+func saveRequestIDForLogger(r *http.Request, requestID string) {
+	if reqidHdr := r.Header[loggerReqidHeader]; len(reqidHdr) == 1 {
+		if infoRw, ok := infoRwMap.Get(reqidHdr[0]); ok {
+			infoRw.(*logInfoResponseWriter).SetRequestID(requestID)
+		}
+	}
+}
+
 // Close closes the Logger (i.e. the file).
 func (l *Logger) Close() {
 	if l.file != nil {
@@ -235,7 +245,10 @@ func (fblh *frontendBackendLoggingHandler) ServeHTTP(rw http.ResponseWriter, req
 	e.HttpUserAgent = req.UserAgent()
 	e.HttpXRequestChain = req.Header.Get("X-Request-Chain")
 	e.HttpXSessionId = req.Header.Get("X-Session-ID")
-	e.HttpXRequestId = req.Header.Get("X-Request-ID")
+	e.HttpXRequestId = infoRw.GetRequestID()
+	if e.HttpXRequestId == "" {
+		e.HttpXRequestId = req.Header.Get("X-Request-ID")
+	}
 	e.RemoteAddr = ip
 	e.HttpTrueClientIp = req.Header.Get("True-Client-IP")
 	e.ProxyHost = infoRw.backend
@@ -324,6 +337,10 @@ func (lirw *logInfoResponseWriter) GetFrontend() string {
 	return lirw.frontend
 }
 
+func (lirw *logInfoResponseWriter) GetRequestID() string {
+	return lirw.requestID
+}
+
 func (lirw *logInfoResponseWriter) SetBackend(backend string) {
 	lirw.backend = backend
 }
@@ -331,3 +348,7 @@ func (lirw *logInfoResponseWriter) SetBackend(backend string) {
 func (lirw *logInfoResponseWriter) SetFrontend(frontend string) {
 	lirw.frontend = frontend
 }
+
+func (lirw *logInfoResponseWriter) SetRequestID(requestID string) {
+	lirw.requestID = requestID
+}
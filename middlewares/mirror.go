@@ -0,0 +1,230 @@
+package middlewares
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/safe"
+	"github.com/containous/traefik/types"
+	"github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	mirrorReqsName    = "traefik_mirror_requests_total"
+	mirrorLatencyName = "traefik_mirror_request_duration_seconds"
+	mirrorDroppedName = "traefik_mirror_dropped_total"
+
+	// maxMirrorsInFlight bounds the mirrored requests waiting for a slow mirror backend
+	maxMirrorsInFlight = 100
+)
+
+var (
+	mirrorReqsCounter = prometheus.NewCounterFrom(
+		stdprometheus.CounterOpts{
+			Name: mirrorReqsName,
+			Help: "How many HTTP requests were mirrored, partitioned by frontend and status code of the primary and mirror backends.",
+		},
+		[]string{"frontend", "primary_code", "mirror_code"},
+	)
+	mirrorLatencyHistogram = prometheus.NewHistogramFrom(
+		stdprometheus.HistogramOpts{
+			Name:    mirrorLatencyName,
+			Help:    "How long it took the primary and mirror backends to process the mirrored requests.",
+			Buckets: []float64{0.1, 0.3, 1.2, 5},
+		},
+		[]string{"frontend", "target"},
+	)
+	mirrorDroppedCounter = prometheus.NewCounterFrom(
+		stdprometheus.CounterOpts{
+			Name: mirrorDroppedName,
+			Help: "How many HTTP requests sampled for mirroring were not mirrored, partitioned by frontend and reason.",
+		},
+		[]string{"frontend", "reason"},
+	)
+)
+
+// Mirror is a middleware sending a copy of a sample of the requests to a shadow backend.
+// The responses of the shadow backend are discarded, and its failures never affect the client.
+type Mirror struct {
+	frontend     string
+	handler      http.Handler
+	percent      int
+	maxBodyBytes int64
+	inFlight     chan struct{}
+	random       func(n int) int
+}
+
+// NewMirror builds a new Mirror sending the requests to handler given a config
+func NewMirror(frontend string, config *types.Mirror, handler http.Handler) (*Mirror, error) {
+	percent := 100
+	if config.Percent != nil {
+		percent = *config.Percent
+	}
+	if percent < 0 || percent > 100 {
+		return nil, fmt.Errorf("Error creating Mirror: invalid percentage %d", percent)
+	}
+	mirror := &Mirror{
+		frontend:     frontend,
+		handler:      handler,
+		percent:      percent,
+		maxBodyBytes: config.MaxBodyBytes,
+		inFlight:     make(chan struct{}, maxMirrorsInFlight),
+		random:       rand.Intn,
+	}
+	if mirror.maxBodyBytes <= 0 {
+		mirror.maxBodyBytes = defaultMemBodyBytes
+	}
+	return mirror, nil
+}
+
+type mirrorResult struct {
+	code    int
+	latency time.Duration
+}
+
+func (m *Mirror) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if m.random(100) >= m.percent || r.Header.Get("Upgrade") != "" {
+		next(rw, r)
+		return
+	}
+	body, err := m.teeBody(r)
+	if err != nil {
+		log.Debugf("Not mirroring request %s: %v", r.URL, err)
+		mirrorDroppedCounter.With("frontend", m.frontend, "reason", "body").Add(1)
+		next(rw, r)
+		return
+	}
+	select {
+	case m.inFlight <- struct{}{}:
+	default:
+		mirrorDroppedCounter.With("frontend", m.frontend, "reason", "overloaded").Add(1)
+		next(rw, r)
+		return
+	}
+
+	// the mirrored request outlives the request of the client
+	mirrorReq := r.WithContext(context.Background())
+	mirrorURL := *r.URL
+	mirrorReq.URL = &mirrorURL
+	mirrorReq.Header = make(http.Header)
+	for name, values := range r.Header {
+		mirrorReq.Header[name] = append([]string(nil), values...)
+	}
+	// the access log entry of the request belongs to the primary backend
+	delete(mirrorReq.Header, loggerReqidHeader)
+	if body != nil {
+		mirrorReq.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	primaryResult := make(chan mirrorResult, 1)
+	safe.Go(func() {
+		defer func() {
+			<-m.inFlight
+		}()
+		start := time.Now()
+		writer := &discardResponseWriter{header: make(http.Header), code: http.StatusOK}
+		m.handler.ServeHTTP(writer, mirrorReq)
+		latency := time.Since(start)
+		primary := <-primaryResult
+		mirrorReqsCounter.With("frontend", m.frontend, "primary_code", strconv.Itoa(primary.code), "mirror_code", strconv.Itoa(writer.code)).Add(1)
+		mirrorLatencyHistogram.With("frontend", m.frontend, "target", "primary").Observe(primary.latency.Seconds())
+		mirrorLatencyHistogram.With("frontend", m.frontend, "target", "mirror").Observe(latency.Seconds())
+	})
+
+	start := time.Now()
+	recorder := &statusResponseWriter{ResponseWriter: rw, code: http.StatusOK}
+	defer func() {
+		primaryResult <- mirrorResult{code: recorder.code, latency: time.Since(start)}
+	}()
+	next(recorder, r)
+}
+
+// teeBody reads the body of the request to send it to the mirror too, the request keeping the whole body.
+// It returns an error if the body is too large to be mirrored.
+func (m *Mirror) teeBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil || r.ContentLength == 0 {
+		return nil, nil
+	}
+	if r.ContentLength > m.maxBodyBytes {
+		return nil, errors.New("body too large")
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, m.maxBodyBytes+1))
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > m.maxBodyBytes {
+		return nil, errors.New("body too large")
+	}
+	return body, nil
+}
+
+// statusResponseWriter records the status code of a response
+type statusResponseWriter struct {
+	http.ResponseWriter
+	code int
+}
+
+func (w *statusResponseWriter) WriteHeader(code int) {
+	w.code = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Flush sends any buffered data to the client.
+func (w *statusResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// CloseNotify returns a channel that receives at most a
+// single value (true) when the client connection has gone
+// away.
+func (w *statusResponseWriter) CloseNotify() <-chan bool {
+	return w.ResponseWriter.(http.CloseNotifier).CloseNotify()
+}
+
+// Hijack hijacks the connection
+func (w *statusResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.ResponseWriter.(http.Hijacker).Hijack()
+}
+
+// discardResponseWriter discards a response, recording its status code
+type discardResponseWriter struct {
+	header http.Header
+	code   int
+}
+
+func (w *discardResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *discardResponseWriter) WriteHeader(code int) {
+	w.code = code
+}
+
+func (w *discardResponseWriter) Write(buf []byte) (int, error) {
+	return len(buf), nil
+}
+
+// Flush does nothing, the response being discarded
+func (w *discardResponseWriter) Flush() {}
+
+// CloseNotify returns a channel which never receives anything, there being no client
+func (w *discardResponseWriter) CloseNotify() <-chan bool {
+	return make(chan bool)
+}
@@ -0,0 +1,178 @@
+package middlewares
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codegangsta/negroni"
+	"github.com/containous/traefik/types"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/stretchr/testify/assert"
+)
+
+// mirrorBackend records the bodies of the mirrored requests
+type mirrorBackend struct {
+	bodies chan string
+	code   int
+}
+
+func (b *mirrorBackend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	w.WriteHeader(b.code)
+	b.bodies <- string(body)
+}
+
+func receive(t *testing.T, bodies chan string) (string, bool) {
+	select {
+	case body := <-bodies:
+		return body, true
+	case <-time.After(time.Second):
+		return "", false
+	}
+}
+
+func TestMirrorBody(t *testing.T) {
+	mirrored := &mirrorBackend{bodies: make(chan string, 1), code: http.StatusOK}
+	mirror, err := NewMirror("frontend1", &types.Mirror{MaxBodyBytes: 10}, mirrored)
+	assert.NoError(t, err, "there should be no error")
+	handler := negroni.New(mirror)
+	handler.UseHandler(echoHandler)
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader("0123456789"))
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	assert.Equal(t, "0123456789", recorder.Body.String(), "the whole body should reach the primary backend")
+	body, ok := receive(t, mirrored.bodies)
+	assert.True(t, ok, "the request should be mirrored")
+	assert.Equal(t, "0123456789", body, "the whole body should reach the mirror backend")
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest("POST", "/", strings.NewReader("0123456789a")),
+		httptest.NewRequest("POST", "/", chunkedReader{strings.NewReader("0123456789a")}),
+	} {
+		req.ContentLength = -1
+		recorder = httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		assert.Equal(t, "0123456789a", recorder.Body.String(), "the whole body should reach the primary backend")
+		_, ok = receive(t, mirrored.bodies)
+		assert.False(t, ok, "a too large request should not be mirrored")
+	}
+}
+
+func TestMirrorPercent(t *testing.T) {
+	mirrored := &mirrorBackend{bodies: make(chan string, 10), code: http.StatusOK}
+	percent := 30
+	mirror, err := NewMirror("frontend1", &types.Mirror{Percent: &percent}, mirrored)
+	assert.NoError(t, err, "there should be no error")
+	handler := negroni.New(mirror)
+	handler.UseHandler(echoHandler)
+	draws := []int{0, 29, 30, 99}
+	mirror.random = func(n int) int {
+		draw := draws[0]
+		draws = draws[1:]
+		return draw
+	}
+
+	for i := 0; i < 4; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	}
+	for i := 0; i < 2; i++ {
+		_, ok := receive(t, mirrored.bodies)
+		assert.True(t, ok, "request %d should be mirrored", i)
+	}
+	_, ok := receive(t, mirrored.bodies)
+	assert.False(t, ok, "only 30% of the requests should be mirrored")
+
+	percent = 0
+	mirror, err = NewMirror("frontend1", &types.Mirror{Percent: &percent}, mirrored)
+	assert.NoError(t, err, "there should be no error")
+	handler = negroni.New(mirror)
+	handler.UseHandler(echoHandler)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	_, ok = receive(t, mirrored.bodies)
+	assert.False(t, ok, "no request should be mirrored at 0%")
+}
+
+func TestMirrorHeaders(t *testing.T) {
+	headers := make(chan http.Header, 1)
+	mirrored := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header
+	})
+	mirror, err := NewMirror("frontend1", &types.Mirror{}, mirrored)
+	assert.NoError(t, err, "there should be no error")
+	handler := negroni.New(mirror)
+	handler.UseHandler(echoHandler)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Custom", "value")
+	req.Header[loggerReqidHeader] = []string{"1"}
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	select {
+	case header := <-headers:
+		assert.Equal(t, "value", header.Get("X-Custom"))
+		assert.Empty(t, header[loggerReqidHeader], "the mirror should not be logged as the backend of the request")
+	case <-time.After(time.Second):
+		t.Fatal("the request should be mirrored")
+	}
+	assert.Equal(t, []string{"1"}, req.Header[loggerReqidHeader], "the request of the primary backend should be left as is")
+}
+
+func TestMirrorFailure(t *testing.T) {
+	done := make(chan struct{})
+	panicking := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(done)
+		panic("mirror failure")
+	})
+	mirror, err := NewMirror("frontend1", &types.Mirror{}, panicking)
+	assert.NoError(t, err, "there should be no error")
+	handler := negroni.New(mirror)
+	handler.UseHandler(echoHandler)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("POST", "/", strings.NewReader("body")))
+	assert.Equal(t, http.StatusOK, recorder.Code, "a failing mirror should not affect the primary backend")
+	assert.Equal(t, "body", recorder.Body.String())
+	<-done
+
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Second)
+	})
+	mirror, err = NewMirror("frontend1", &types.Mirror{}, slow)
+	assert.NoError(t, err, "there should be no error")
+	handler = negroni.New(mirror)
+	handler.UseHandler(echoHandler)
+	start := time.Now()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	assert.True(t, time.Since(start) < time.Second, "the primary backend should not wait for the mirror")
+}
+
+func TestMirrorMetrics(t *testing.T) {
+	mirrored := &mirrorBackend{bodies: make(chan string, 1), code: http.StatusNotFound}
+	mirror, err := NewMirror("frontend1", &types.Mirror{}, mirrored)
+	assert.NoError(t, err, "there should be no error")
+	handler := negroni.New(mirror)
+	handler.UseHandler(echoHandler)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	receive(t, mirrored.bodies)
+
+	expected := mirrorReqsName + `{frontend="frontend1",mirror_code="404",primary_code="200"} 1`
+	var body string
+	for i := 0; i < 10 && !strings.Contains(body, expected); i++ {
+		time.Sleep(10 * time.Millisecond)
+		recorder := httptest.NewRecorder()
+		promhttp.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+		body = recorder.Body.String()
+	}
+	assert.Contains(t, body, expected, "the status codes of the primary and mirror backends should be compared")
+	assert.Contains(t, body, mirrorLatencyName)
+}
+
+func TestMirrorInvalidConfig(t *testing.T) {
+	percent := 101
+	_, err := NewMirror("frontend1", &types.Mirror{Percent: &percent}, http.NotFoundHandler())
+	assert.Error(t, err)
+}
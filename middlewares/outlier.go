@@ -0,0 +1,275 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/types"
+	"github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+const (
+	outlierEjectionsName      = "traefik_outlier_ejections_total"
+	outlierEjectedServersName = "traefik_outlier_ejected_servers"
+
+	defaultConsecutiveErrors  = 5
+	defaultBaseEjectionTime   = 30 * time.Second
+	defaultMaxEjectionTime    = 5 * time.Minute
+	defaultMaxEjectionPercent = 50
+)
+
+var (
+	outlierEjectionsCounter = prometheus.NewCounterFrom(
+		stdprometheus.CounterOpts{
+			Name: outlierEjectionsName,
+			Help: "How many servers were ejected from their backend by the outlier detection, partitioned by backend.",
+		},
+		[]string{"backend"},
+	)
+	outlierEjectedServersVec = stdprometheus.NewGaugeVec(
+		stdprometheus.GaugeOpts{
+			Name: outlierEjectedServersName,
+			Help: "How many servers of a backend are currently ejected by the outlier detection.",
+		},
+		[]string{"backend"},
+	)
+	outlierEjectedServersGauge = prometheus.NewGauge(outlierEjectedServersVec)
+)
+
+func init() {
+	stdprometheus.MustRegister(outlierEjectedServersVec)
+}
+
+// OutlierDetector is a passive health check of the servers of a backend. It sits between the load balancer and the
+// forwarder, and ejects from the load balancer the servers answering too many 5xx in a row, including the 502 and 504
+// of connection errors. An ejected server is re-admitted after an ejection time doubling at each new ejection.
+type OutlierDetector struct {
+	backend            string
+	next               http.Handler
+	consecutiveErrors  int
+	baseEjectionTime   time.Duration
+	maxEjectionTime    time.Duration
+	maxEjectionPercent int
+	lock               sync.Mutex
+	lb                 outlierLoadBalancer
+	weights            map[string]int
+	servers            map[string]*outlierServer
+	stopped            bool
+	now                func() time.Time
+	afterFunc          func(d time.Duration, f func()) *time.Timer
+}
+
+type outlierLoadBalancer interface {
+	RemoveServer(u *url.URL) error
+	UpsertServer(u *url.URL, options ...roundrobin.ServerOption) error
+	Servers() []*url.URL
+}
+
+type outlierServer struct {
+	url               *url.URL
+	consecutiveErrors int
+	ejections         int
+	ejectedUntil      time.Time
+	readmittedAt      time.Time
+	readmission       *time.Timer
+}
+
+// Ejection holds the state of a server ejected by an outlier detection
+type Ejection struct {
+	Until time.Time `json:"until"`
+	// ejections of the server, the ejection time doubling at each one
+	Count int `json:"count"`
+}
+
+// NewOutlierDetector returns a new OutlierDetector of a backend sending the requests to next,
+// which ejects servers once its load balancer is set with SetLoadBalancer
+func NewOutlierDetector(backend string, config *types.OutlierDetection, next http.Handler) (*OutlierDetector, error) {
+	o := &OutlierDetector{
+		backend:            backend,
+		next:               next,
+		consecutiveErrors:  config.ConsecutiveErrors,
+		baseEjectionTime:   time.Duration(config.BaseEjectionTime),
+		maxEjectionTime:    time.Duration(config.MaxEjectionTime),
+		maxEjectionPercent: config.MaxEjectionPercent,
+		servers:            make(map[string]*outlierServer),
+		now:                time.Now,
+		afterFunc:          time.AfterFunc,
+	}
+	if o.consecutiveErrors < 0 || o.baseEjectionTime < 0 || o.maxEjectionTime < 0 {
+		return nil, fmt.Errorf("Error creating OutlierDetector: negative option in %+v", config)
+	}
+	if o.maxEjectionPercent < 0 || o.maxEjectionPercent > 100 {
+		return nil, fmt.Errorf("Error creating OutlierDetector: invalid maximum ejection percentage %d", o.maxEjectionPercent)
+	}
+	if o.consecutiveErrors == 0 {
+		o.consecutiveErrors = defaultConsecutiveErrors
+	}
+	if o.baseEjectionTime == 0 {
+		o.baseEjectionTime = defaultBaseEjectionTime
+	}
+	if o.maxEjectionTime == 0 {
+		o.maxEjectionTime = defaultMaxEjectionTime
+	}
+	if o.maxEjectionTime < o.baseEjectionTime {
+		o.maxEjectionTime = o.baseEjectionTime
+	}
+	if o.maxEjectionPercent == 0 {
+		o.maxEjectionPercent = defaultMaxEjectionPercent
+	}
+	outlierEjectedServersGauge.With("backend", backend).Set(0)
+	return o, nil
+}
+
+// SetLoadBalancer sets the load balancer the servers are ejected from, and the configured weights
+// of the servers by URL, restored when they are re-admitted
+func (o *OutlierDetector) SetLoadBalancer(lb outlierLoadBalancer, weights map[string]int) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.lb = lb
+	o.weights = weights
+}
+
+func (o *OutlierDetector) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	recorder := &statusResponseWriter{ResponseWriter: rw, code: http.StatusOK}
+	o.next.ServeHTTP(recorder, r)
+	// the load balancer has set the URL of the request to the one of its server
+	o.record(serverKey(r.URL), recorder.code >= http.StatusInternalServerError)
+}
+
+// serverKey identifies the server of a URL
+func serverKey(u *url.URL) string {
+	return u.Scheme + "://" + u.Host
+}
+
+// record records the result of a request sent to a server, ejecting it after too many errors in a row
+func (o *OutlierDetector) record(key string, failed bool) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if o.lb == nil {
+		return
+	}
+	server, ok := o.servers[key]
+	if !ok {
+		server = &outlierServer{}
+		o.servers[key] = server
+	}
+	if !server.ejectedUntil.IsZero() {
+		// a request sent before the ejection
+		return
+	}
+	if !failed {
+		server.consecutiveErrors = 0
+		return
+	}
+	server.consecutiveErrors++
+	if server.consecutiveErrors >= o.consecutiveErrors {
+		o.eject(key, server)
+	}
+}
+
+// eject removes a server from the load balancer, unless too many servers are already ejected
+func (o *OutlierDetector) eject(key string, server *outlierServer) {
+	var serverURL *url.URL
+	servers := o.lb.Servers()
+	for _, u := range servers {
+		if serverKey(u) == key {
+			serverURL = u
+		}
+	}
+	if serverURL == nil {
+		// removed by the health check in the meantime
+		return
+	}
+	ejected := o.ejected()
+	if (ejected+1)*100 > o.maxEjectionPercent*(len(servers)+ejected) {
+		log.Debugf("Not ejecting server %s of backend %s: %d%% of the servers at most can be ejected", serverURL, o.backend, o.maxEjectionPercent)
+		return
+	}
+
+	now := o.now()
+	if now.Sub(server.readmittedAt) > o.maxEjectionTime {
+		server.ejections = 0
+	}
+	ejectionTime := o.baseEjectionTime
+	for i := 0; i < server.ejections && ejectionTime < o.maxEjectionTime; i++ {
+		ejectionTime *= 2
+	}
+	if ejectionTime > o.maxEjectionTime {
+		ejectionTime = o.maxEjectionTime
+	}
+	server.url = serverURL
+	server.ejections++
+	server.ejectedUntil = now.Add(ejectionTime)
+	o.lb.RemoveServer(serverURL)
+	log.Warnf("Ejecting server %s of backend %s for %s after %d errors in a row", serverURL, o.backend, ejectionTime, server.consecutiveErrors)
+	outlierEjectionsCounter.With("backend", o.backend).Add(1)
+	outlierEjectedServersGauge.With("backend", o.backend).Set(float64(ejected + 1))
+	server.readmission = o.afterFunc(ejectionTime, func() {
+		o.readmit(server)
+	})
+}
+
+// readmit upserts an ejected server in the load balancer with its configured weight
+func (o *OutlierDetector) readmit(server *outlierServer) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if o.stopped {
+		return
+	}
+	weight, ok := o.weights[server.url.String()]
+	if !ok {
+		weight = 1
+	}
+	log.Infof("Re-admitting server %s of backend %s with weight %d", server.url, o.backend, weight)
+	o.lb.UpsertServer(server.url, roundrobin.Weight(weight))
+	server.consecutiveErrors = 0
+	server.ejectedUntil = time.Time{}
+	server.readmittedAt = o.now()
+	server.readmission = nil
+	outlierEjectedServersGauge.With("backend", o.backend).Set(float64(o.ejected()))
+}
+
+// ejected returns how many servers are ejected
+func (o *OutlierDetector) ejected() int {
+	ejected := 0
+	for _, server := range o.servers {
+		if !server.ejectedUntil.IsZero() {
+			ejected++
+		}
+	}
+	return ejected
+}
+
+// Ejection returns the ejection of a server, or nil if it is not ejected
+func (o *OutlierDetector) Ejection(serverURL *url.URL) *Ejection {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	server, ok := o.servers[serverKey(serverURL)]
+	if !ok || server.ejectedUntil.IsZero() {
+		return nil
+	}
+	return &Ejection{Until: server.ejectedUntil, Count: server.ejections}
+}
+
+// Stop stops the pending readmissions, once the load balancer of the outlier detector is replaced by a configuration reload
+func (o *OutlierDetector) Stop() {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.stopped = true
+	for _, server := range o.servers {
+		if server.readmission != nil {
+			server.readmission.Stop()
+		}
+	}
+}
+
+// Close removes the metrics of the outlier detector, once its backend is removed from the configuration
+func (o *OutlierDetector) Close() {
+	outlierEjectedServersVec.DeleteLabelValues(o.backend)
+}
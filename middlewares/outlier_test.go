@@ -0,0 +1,146 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/types"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+type fakeLoadBalancer struct {
+	servers []*url.URL
+}
+
+func (lb *fakeLoadBalancer) RemoveServer(u *url.URL) error {
+	for i, server := range lb.servers {
+		if server == u {
+			lb.servers = append(lb.servers[:i], lb.servers[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (lb *fakeLoadBalancer) UpsertServer(u *url.URL, options ...roundrobin.ServerOption) error {
+	lb.servers = append(lb.servers, u)
+	return nil
+}
+
+func (lb *fakeLoadBalancer) Servers() []*url.URL {
+	return append([]*url.URL{}, lb.servers...)
+}
+
+func sendTo(o *OutlierDetector, host string, requests int) {
+	for i := 0; i < requests; i++ {
+		o.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "http://"+host+"/", nil))
+	}
+}
+
+func TestOutlierDetectorEjection(t *testing.T) {
+	statuses := map[string]int{"10.0.0.1:80": http.StatusBadGateway, "10.0.0.2:80": http.StatusOK}
+	o, err := NewOutlierDetector("backend-outlier", &types.OutlierDetection{
+		ConsecutiveErrors: 3,
+		BaseEjectionTime:  types.Duration(10 * time.Second),
+		MaxEjectionTime:   types.Duration(30 * time.Second),
+	}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statuses[r.URL.Host])
+	}))
+	assert.NoError(t, err, "there should be no error")
+	lb := &fakeLoadBalancer{servers: []*url.URL{{Scheme: "http", Host: "10.0.0.1:80"}, {Scheme: "http", Host: "10.0.0.2:80"}}}
+	o.SetLoadBalancer(lb, nil)
+	now := time.Now()
+	o.now = func() time.Time {
+		return now
+	}
+	var durations []time.Duration
+	var readmissions []func()
+	o.afterFunc = func(d time.Duration, f func()) *time.Timer {
+		durations = append(durations, d)
+		readmissions = append(readmissions, f)
+		return time.NewTimer(d)
+	}
+
+	sendTo(o, "10.0.0.1:80", 2)
+	statuses["10.0.0.1:80"] = http.StatusOK
+	sendTo(o, "10.0.0.1:80", 1)
+	statuses["10.0.0.1:80"] = http.StatusBadGateway
+	sendTo(o, "10.0.0.1:80", 2)
+	assert.Len(t, lb.servers, 2, "a success should reset the errors")
+
+	sendTo(o, "10.0.0.1:80", 1)
+	assert.Equal(t, []*url.URL{{Scheme: "http", Host: "10.0.0.2:80"}}, lb.servers, "the server should be ejected after 3 errors")
+	assert.Equal(t, &Ejection{Until: now.Add(10 * time.Second), Count: 1}, o.Ejection(&url.URL{Scheme: "http", Host: "10.0.0.1:80"}))
+	assert.Nil(t, o.Ejection(&url.URL{Scheme: "http", Host: "10.0.0.2:80"}))
+
+	expectedDurations := []time.Duration{10 * time.Second, 20 * time.Second, 30 * time.Second, 30 * time.Second}
+	for i, expected := range expectedDurations {
+		assert.Equal(t, expected, durations[i], "ejection %d", i+1)
+		readmissions[i]()
+		assert.Len(t, lb.servers, 2, "the server should be re-admitted")
+		assert.Nil(t, o.Ejection(&url.URL{Scheme: "http", Host: "10.0.0.1:80"}))
+		sendTo(o, "10.0.0.1:80", 3)
+	}
+
+	readmissions[4]()
+	now = now.Add(time.Minute)
+	sendTo(o, "10.0.0.1:80", 3)
+	assert.Equal(t, 10*time.Second, durations[5], "the ejection time should be reset once the server is fine for long enough")
+}
+
+func TestOutlierDetectorMaxEjectionPercent(t *testing.T) {
+	o, err := NewOutlierDetector("backend-percent", &types.OutlierDetection{ConsecutiveErrors: 1}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	assert.NoError(t, err, "there should be no error")
+	lb := &fakeLoadBalancer{servers: []*url.URL{{Scheme: "http", Host: "10.0.0.1:80"}, {Scheme: "http", Host: "10.0.0.2:80"}}}
+	o.SetLoadBalancer(lb, nil)
+
+	sendTo(o, "10.0.0.1:80", 1)
+	sendTo(o, "10.0.0.2:80", 1)
+	assert.Len(t, lb.servers, 1, "half of the servers at most should be ejected by default")
+}
+
+func TestOutlierDetectorStop(t *testing.T) {
+	o, err := NewOutlierDetector("backend-stopped", &types.OutlierDetection{ConsecutiveErrors: 1}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	assert.NoError(t, err, "there should be no error")
+	lb := &fakeLoadBalancer{servers: []*url.URL{{Scheme: "http", Host: "10.0.0.1:80"}, {Scheme: "http", Host: "10.0.0.2:80"}}}
+	o.SetLoadBalancer(lb, nil)
+	var readmission func()
+	o.afterFunc = func(d time.Duration, f func()) *time.Timer {
+		readmission = f
+		return time.NewTimer(d)
+	}
+
+	sendTo(o, "10.0.0.1:80", 1)
+	assert.Len(t, lb.servers, 1, "the server should be ejected")
+	o.Stop()
+	readmission()
+	assert.Len(t, lb.servers, 1, "a stopped outlier detector should not re-admit its servers")
+
+	recorder := httptest.NewRecorder()
+	promhttp.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Contains(t, recorder.Body.String(), outlierEjectedServersName+`{backend="backend-stopped"} 1`)
+	o.Close()
+	recorder = httptest.NewRecorder()
+	promhttp.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	assert.NotContains(t, recorder.Body.String(), outlierEjectedServersName+`{backend="backend-stopped"}`, "the metrics of a removed backend should be removed")
+}
+
+func TestOutlierDetectorInvalidConfig(t *testing.T) {
+	for _, config := range []*types.OutlierDetection{
+		{ConsecutiveErrors: -1},
+		{MaxEjectionPercent: 101},
+		{BaseEjectionTime: types.Duration(-time.Second)},
+	} {
+		_, err := NewOutlierDetector("backend", config, http.NotFoundHandler())
+		assert.Error(t, err, "%+v should be invalid", config)
+	}
+}
@@ -0,0 +1,198 @@
+package middlewares
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/types"
+	"github.com/go-kit/kit/metrics/prometheus"
+	stdprometheus "github.com/prometheus/client_golang/prometheus"
+)
+
+const rateLimitName = "traefik_ratelimit_requests_total"
+
+var rateLimitCounter = prometheus.NewCounterFrom(
+	stdprometheus.CounterOpts{
+		Name: rateLimitName,
+		Help: "How many HTTP requests went through a rate limiter, partitioned by frontend and result.",
+	},
+	[]string{"frontend", "result"},
+)
+
+// RateLimiter is a middleware that limits the rate of requests of each client using
+// a set of token buckets. A request is let through only if every rate of the set allows it.
+type RateLimiter struct {
+	frontend     string
+	extract      func(r *http.Request) string
+	rates        []*rate
+	idleTimeout  time.Duration
+	now          func() time.Time
+	mutex        sync.Mutex
+	clients      map[string]*clientBuckets
+	lastEviction time.Time
+}
+
+type rate struct {
+	capacity float64
+	// tokens added per second
+	fillRate float64
+}
+
+type clientBuckets struct {
+	tokens   []float64
+	lastSeen time.Time
+}
+
+// NewRateLimiter builds a new RateLimiter given a config
+func NewRateLimiter(frontend string, config *types.RateLimit) (*RateLimiter, error) {
+	if config == nil || len(config.RateSet) == 0 {
+		return nil, fmt.Errorf("Error creating RateLimiter: no rate defined")
+	}
+	trustedProxies, err := parseIPNets(config.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("Error creating RateLimiter: %v", err)
+	}
+	extract, err := newRateLimitExtractor(config.ExtractorFunc, trustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	rateLimiter := RateLimiter{
+		frontend: frontend,
+		extract:  extract,
+		now:      time.Now,
+		clients:  make(map[string]*clientBuckets),
+	}
+
+	names := make([]string, 0, len(config.RateSet))
+	for name := range config.RateSet {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		r := config.RateSet[name]
+		period := time.Duration(r.Period)
+		if period <= 0 || r.Average <= 0 {
+			return nil, fmt.Errorf("Error creating RateLimiter: rate %s needs a positive period and average", name)
+		}
+		burst := r.Burst
+		if burst < r.Average {
+			burst = r.Average
+		}
+		newRate := &rate{
+			capacity: float64(burst),
+			fillRate: float64(r.Average) / period.Seconds(),
+		}
+		rateLimiter.rates = append(rateLimiter.rates, newRate)
+		// time for an empty bucket to be full again
+		refill := time.Duration(newRate.capacity / newRate.fillRate * float64(time.Second))
+		if refill > rateLimiter.idleTimeout {
+			rateLimiter.idleTimeout = refill
+		}
+	}
+	return &rateLimiter, nil
+}
+
+func newRateLimitExtractor(extractorFunc string, trustedProxies []*net.IPNet) (func(r *http.Request) string, error) {
+	sourceIP := func(r *http.Request) string {
+		return clientIP(r, trustedProxies)
+	}
+	switch {
+	case extractorFunc == "" || extractorFunc == "client.ip":
+		return sourceIP, nil
+	case extractorFunc == "request.host":
+		return func(r *http.Request) string {
+			return r.Host
+		}, nil
+	case extractorFunc == "auth.user":
+		// unauthenticated requests are limited by source IP
+		return func(r *http.Request) string {
+			if user := GetAuthUser(r); user != "" {
+				return "user:" + user
+			}
+			return sourceIP(r)
+		}, nil
+	case strings.HasPrefix(extractorFunc, "request.header."):
+		header := strings.TrimPrefix(extractorFunc, "request.header.")
+		// requests without the header are limited by source IP
+		return func(r *http.Request) string {
+			if value := r.Header.Get(header); value != "" {
+				return "header:" + value
+			}
+			return sourceIP(r)
+		}, nil
+	}
+	return nil, fmt.Errorf("Error creating RateLimiter: unsupported extractor function %s", extractorFunc)
+}
+
+func (rl *RateLimiter) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	key := rl.extract(r)
+	if wait, ok := rl.take(key); !ok {
+		log.Debugf("Rate limit reached for %s on frontend %s, retry in %s", key, rl.frontend, wait)
+		rateLimitCounter.With("frontend", rl.frontend, "result", "rejected").Add(1)
+		rw.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		http.Error(rw, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+		return
+	}
+	rateLimitCounter.With("frontend", rl.frontend, "result", "allowed").Add(1)
+	next(rw, r)
+}
+
+// take consumes a token from each of the client buckets if they all have one left.
+// Otherwise, it returns how long the client has to wait before its next request.
+func (rl *RateLimiter) take(key string) (time.Duration, bool) {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	now := rl.now()
+	rl.evictIdleClients(now)
+
+	client, ok := rl.clients[key]
+	if !ok {
+		client = &clientBuckets{tokens: make([]float64, len(rl.rates)), lastSeen: now}
+		for i, r := range rl.rates {
+			client.tokens[i] = r.capacity
+		}
+		rl.clients[key] = client
+	}
+	elapsed := now.Sub(client.lastSeen).Seconds()
+	client.lastSeen = now
+
+	var wait time.Duration
+	for i, r := range rl.rates {
+		client.tokens[i] = math.Min(r.capacity, client.tokens[i]+elapsed*r.fillRate)
+		if client.tokens[i] < 1 {
+			rateWait := time.Duration((1 - client.tokens[i]) / r.fillRate * float64(time.Second))
+			if rateWait > wait {
+				wait = rateWait
+			}
+		}
+	}
+	if wait > 0 {
+		return wait, false
+	}
+	for i := range rl.rates {
+		client.tokens[i]--
+	}
+	return 0, true
+}
+
+// evictIdleClients forgets the clients that have been idle long enough for all their buckets to be full again.
+func (rl *RateLimiter) evictIdleClients(now time.Time) {
+	if now.Sub(rl.lastEviction) < rl.idleTimeout {
+		return
+	}
+	rl.lastEviction = now
+	for key, client := range rl.clients {
+		if now.Sub(client.lastSeen) > rl.idleTimeout {
+			delete(rl.clients, key)
+		}
+	}
+}
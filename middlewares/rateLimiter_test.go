@@ -0,0 +1,131 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/codegangsta/negroni"
+	"github.com/containous/traefik/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func statusFor(handler http.Handler, remoteAddr string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = remoteAddr
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestRateLimiterBurstAndRefill(t *testing.T) {
+	now := time.Now()
+	rateLimiter, err := NewRateLimiter("frontend1", &types.RateLimit{
+		RateSet: map[string]*types.Rate{
+			"second": {Period: types.Duration(time.Second), Average: 1, Burst: 3},
+		},
+	})
+	assert.NoError(t, err, "there should be no error")
+	rateLimiter.now = func() time.Time {
+		return now
+	}
+	handler := negroni.New(rateLimiter)
+	handler.UseHandler(echoHandler)
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, statusFor(handler, "10.0.0.1:1234", nil).Code, "burst request %d should be allowed", i)
+	}
+	rejected := statusFor(handler, "10.0.0.1:1234", nil)
+	assert.Equal(t, http.StatusTooManyRequests, rejected.Code)
+	assert.Equal(t, "1", rejected.Header().Get("Retry-After"))
+
+	assert.Equal(t, http.StatusOK, statusFor(handler, "10.0.0.2:1234", nil).Code, "another client should have its own bucket")
+
+	now = now.Add(time.Second)
+	assert.Equal(t, http.StatusOK, statusFor(handler, "10.0.0.1:1234", nil).Code, "a token should have been refilled")
+	assert.Equal(t, http.StatusTooManyRequests, statusFor(handler, "10.0.0.1:1234", nil).Code)
+}
+
+func TestRateLimiterAllRatesMustAllow(t *testing.T) {
+	now := time.Now()
+	rateLimiter, err := NewRateLimiter("frontend1", &types.RateLimit{
+		RateSet: map[string]*types.Rate{
+			"second": {Period: types.Duration(time.Second), Average: 10},
+			"minute": {Period: types.Duration(time.Minute), Average: 2},
+		},
+	})
+	assert.NoError(t, err, "there should be no error")
+	rateLimiter.now = func() time.Time {
+		return now
+	}
+	handler := negroni.New(rateLimiter)
+	handler.UseHandler(echoHandler)
+
+	assert.Equal(t, http.StatusOK, statusFor(handler, "10.0.0.1:1234", nil).Code)
+	assert.Equal(t, http.StatusOK, statusFor(handler, "10.0.0.1:1234", nil).Code)
+	rejected := statusFor(handler, "10.0.0.1:1234", nil)
+	assert.Equal(t, http.StatusTooManyRequests, rejected.Code)
+	assert.Equal(t, "30", rejected.Header().Get("Retry-After"))
+}
+
+func TestRateLimiterTrustedProxies(t *testing.T) {
+	now := time.Now()
+	rateLimiter, err := NewRateLimiter("frontend1", &types.RateLimit{
+		TrustedProxies: []string{"10.0.0.0/8", "192.168.1.1"},
+		RateSet: map[string]*types.Rate{
+			"second": {Period: types.Duration(time.Second), Average: 1},
+		},
+	})
+	assert.NoError(t, err, "there should be no error")
+	rateLimiter.now = func() time.Time {
+		return now
+	}
+	handler := negroni.New(rateLimiter)
+	handler.UseHandler(echoHandler)
+
+	forwarded := map[string]string{"X-Forwarded-For": "1.2.3.4, 5.6.7.8, 192.168.1.1"}
+	assert.Equal(t, http.StatusOK, statusFor(handler, "10.0.0.1:1234", forwarded).Code)
+	assert.Equal(t, http.StatusTooManyRequests, statusFor(handler, "10.0.0.2:1234", forwarded).Code, "5.6.7.8 should be limited whatever the proxy")
+	assert.Equal(t, http.StatusOK, statusFor(handler, "10.0.0.1:1234", map[string]string{"X-Forwarded-For": "1.2.3.4"}).Code)
+	assert.Equal(t, http.StatusOK, statusFor(handler, "8.8.8.8:1234", forwarded).Code, "untrusted peers should not be able to spoof their IP")
+}
+
+func TestRateLimiterHeaderExtractor(t *testing.T) {
+	now := time.Now()
+	rateLimiter, err := NewRateLimiter("frontend1", &types.RateLimit{
+		ExtractorFunc: "request.header.X-Api-Key",
+		RateSet: map[string]*types.Rate{
+			"second": {Period: types.Duration(time.Second), Average: 1},
+		},
+	})
+	assert.NoError(t, err, "there should be no error")
+	rateLimiter.now = func() time.Time {
+		return now
+	}
+	handler := negroni.New(rateLimiter)
+	handler.UseHandler(echoHandler)
+
+	assert.Equal(t, http.StatusOK, statusFor(handler, "10.0.0.1:1234", map[string]string{"X-Api-Key": "a"}).Code)
+	assert.Equal(t, http.StatusTooManyRequests, statusFor(handler, "10.0.0.2:1234", map[string]string{"X-Api-Key": "a"}).Code)
+	assert.Equal(t, http.StatusOK, statusFor(handler, "10.0.0.1:1234", map[string]string{"X-Api-Key": "b"}).Code)
+}
+
+func TestRateLimiterInvalidConfig(t *testing.T) {
+	_, err := NewRateLimiter("frontend1", &types.RateLimit{})
+	assert.Error(t, err, "a rate limiter without rates should be rejected")
+
+	_, err = NewRateLimiter("frontend1", &types.RateLimit{
+		ExtractorFunc: "request.cookie",
+		RateSet:       map[string]*types.Rate{"second": {Period: types.Duration(time.Second), Average: 1}},
+	})
+	assert.Error(t, err, "unknown extractor functions should be rejected")
+
+	_, err = NewRateLimiter("frontend1", &types.RateLimit{
+		RateSet: map[string]*types.Rate{"second": {Average: 1}},
+	})
+	assert.Error(t, err, "rates without period should be rejected")
+}
@@ -0,0 +1,137 @@
+package middlewares
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/types"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultRequestIDHeader = "X-Request-ID"
+	// longer client-supplied IDs are never trusted
+	maxIncomingRequestIDLength = 200
+	crockfordAlphabet          = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+)
+
+type requestIDKey struct{}
+
+// RequestID is a middleware that tags each request with a unique ID. The ID
+// is sent upstream, echoed on the response and recorded in the access log.
+type RequestID struct {
+	headerName    string
+	prefix        string
+	trustIncoming bool
+	generate      func() (string, error)
+}
+
+// NewRequestID builds a new RequestID given a config
+func NewRequestID(config *types.RequestID) (*RequestID, error) {
+	if config == nil {
+		return nil, fmt.Errorf("Error creating RequestID: config is nil")
+	}
+	requestID := RequestID{
+		headerName:    defaultRequestIDHeader,
+		prefix:        config.Prefix,
+		trustIncoming: config.TrustIncoming,
+	}
+	if config.HeaderName != "" {
+		requestID.headerName = http.CanonicalHeaderKey(config.HeaderName)
+	}
+	switch strings.ToLower(config.Generator) {
+	case "", "uuid", "uuidv4":
+		requestID.generate = newUUIDv4
+	case "ulid":
+		requestID.generate = newULID
+	default:
+		return nil, fmt.Errorf("Error creating RequestID: unknown generator %s", config.Generator)
+	}
+	return &requestID, nil
+}
+
+func (ri *RequestID) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	id := r.Header.Get(ri.headerName)
+	if !ri.trustIncoming || !isValidIncomingRequestID(id) {
+		generated, err := ri.generate()
+		if err != nil {
+			log.Errorf("Error generating request ID: %s", err)
+			next(rw, r)
+			return
+		}
+		id = ri.prefix + generated
+	}
+	// Set rather than Add so that an untrusted incoming ID is replaced, not duplicated
+	r.Header.Set(ri.headerName, id)
+	saveRequestIDForLogger(r, id)
+
+	hw := newHeaderHookResponseWriter(rw, func(header http.Header) {
+		header.Set(ri.headerName, id)
+	})
+	next(hw, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+}
+
+// GetRequestID returns the ID assigned to the request by the RequestID middleware.
+// It falls back to the X-Request-ID header when the middleware is not enabled.
+func GetRequestID(r *http.Request) string {
+	if id, ok := r.Context().Value(requestIDKey{}).(string); ok {
+		return id
+	}
+	return r.Header.Get(defaultRequestIDHeader)
+}
+
+func isValidIncomingRequestID(id string) bool {
+	if id == "" || len(id) > maxIncomingRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		if c < 0x21 || c > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newUUIDv4() (string, error) {
+	id, err := uuid.NewRandom()
+	if err != nil {
+		return "", err
+	}
+	return id.String(), nil
+}
+
+// newULID returns a ULID: a 48 bits millisecond timestamp followed by 80 random
+// bits, encoded as 26 characters of Crockford's base32 so that IDs sort by time.
+func newULID() (string, error) {
+	var id [16]byte
+	ms := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+	for i := 0; i < 6; i++ {
+		id[i] = byte(ms >> uint(40-8*i))
+	}
+	if _, err := rand.Read(id[6:]); err != nil {
+		return "", err
+	}
+
+	out := make([]byte, 26)
+	pos := len(out) - 1
+	var buf uint
+	var bits uint
+	for i := len(id) - 1; i >= 0; i-- {
+		buf |= uint(id[i]) << bits
+		bits += 8
+		for bits >= 5 {
+			out[pos] = crockfordAlphabet[buf&31]
+			buf >>= 5
+			bits -= 5
+			pos--
+		}
+	}
+	// the 3 remaining most significant bits
+	out[0] = crockfordAlphabet[buf&31]
+	return string(out), nil
+}
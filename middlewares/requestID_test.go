@@ -0,0 +1,76 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/codegangsta/negroni"
+	"github.com/containous/traefik/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveWithRequestID(t *testing.T, config *types.RequestID, req *http.Request) (*httptest.ResponseRecorder, *http.Request) {
+	requestID, err := NewRequestID(config)
+	assert.NoError(t, err, "there should be no error")
+
+	var upstream *http.Request
+	n := negroni.New(requestID)
+	n.UseHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstream = r
+		w.Header().Set("X-Request-ID", "from-backend")
+		w.WriteHeader(http.StatusOK)
+	}))
+	recorder := httptest.NewRecorder()
+	n.ServeHTTP(recorder, req)
+	return recorder, upstream
+}
+
+func TestRequestIDReplacesUntrustedIncomingID(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Add("X-Request-ID", "client-supplied")
+
+	recorder, upstream := serveWithRequestID(t, &types.RequestID{Prefix: "svc-"}, req)
+
+	ids := upstream.Header["X-Request-Id"]
+	assert.Len(t, ids, 1, "there should be a single request ID")
+	assert.Regexp(t, regexp.MustCompile("^svc-[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$"), ids[0])
+	assert.Equal(t, ids[0], GetRequestID(upstream))
+	assert.Equal(t, []string{ids[0]}, recorder.HeaderMap["X-Request-Id"], "the ID should be echoed on the response")
+}
+
+func TestRequestIDTrustsIncomingID(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Correlation-Id", "client-supplied")
+
+	recorder, upstream := serveWithRequestID(t, &types.RequestID{HeaderName: "correlation-id", TrustIncoming: true}, req)
+
+	assert.Equal(t, "client-supplied", upstream.Header.Get("Correlation-Id"))
+	assert.Equal(t, "client-supplied", GetRequestID(upstream))
+	assert.Equal(t, "client-supplied", recorder.Header().Get("Correlation-Id"))
+}
+
+func TestRequestIDIgnoresInvalidIncomingID(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Request-ID", "not a valid id")
+
+	_, upstream := serveWithRequestID(t, &types.RequestID{TrustIncoming: true, Generator: "ulid"}, req)
+
+	assert.Regexp(t, regexp.MustCompile("^[0-7][0-9A-HJKMNP-TV-Z]{25}$"), GetRequestID(upstream))
+}
+
+func TestRequestIDUnknownGenerator(t *testing.T) {
+	_, err := NewRequestID(&types.RequestID{Generator: "sequence"})
+	assert.Error(t, err, "there should be an error")
+}
+
+func TestULIDSortsByTime(t *testing.T) {
+	first, err := newULID()
+	assert.NoError(t, err, "there should be no error")
+	for i := 0; i < 10; i++ {
+		next, err := newULID()
+		assert.NoError(t, err, "there should be no error")
+		assert.True(t, next[:10] >= first[:10], "the timestamp prefix should not move backwards")
+	}
+}
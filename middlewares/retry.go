@@ -3,61 +3,291 @@ package middlewares
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"io/ioutil"
+	"math/rand"
 	"net"
 	"net/http"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/containous/traefik/log"
 	"github.com/vulcand/oxy/utils"
 )
 
 var (
+	_ http.ResponseWriter = &retryResponseWriter{}
+	_ http.Hijacker       = &retryResponseWriter{}
+	_ http.Flusher        = &retryResponseWriter{}
+	_ http.CloseNotifier  = &retryResponseWriter{}
 	_ http.ResponseWriter = &ResponseRecorder{}
 	_ http.Hijacker       = &ResponseRecorder{}
 	_ http.Flusher        = &ResponseRecorder{}
 	_ http.CloseNotifier  = &ResponseRecorder{}
 )
 
-// Retry is a middleware that retries requests
+// retryBudgetSeconds is the number of seconds over which the retries of a backend are counted
+const retryBudgetSeconds = 10
+
+// retryAttemptKey is the context key of the retryAttempt of a request
+type retryAttemptKey struct{}
+
+// retryAttempt records whether an attempt failed with a network error
+type retryAttempt struct {
+	networkError bool
+}
+
+// NetworkErrorHandler is the error handler of the forwarders, answering their errors as oxy does
+// while telling Retry that the attempt failed with a network error and can be retried
+var NetworkErrorHandler utils.ErrorHandler = utils.ErrorHandlerFunc(func(rw http.ResponseWriter, r *http.Request, err error) {
+	if attempt, ok := r.Context().Value(retryAttemptKey{}).(*retryAttempt); ok {
+		attempt.networkError = true
+	}
+	utils.DefaultHandler.ServeHTTP(rw, r, err)
+})
+
+// RetryPolicy holds which requests are retried, and when
+type RetryPolicy struct {
+	// methods of the requests that can be retried, idempotent ones if empty
+	Methods []string
+	// maximum wait before the first retry, doubled at each retry, 100ms if 0
+	InitialInterval time.Duration
+	// maximum wait before a retry, 1s if 0
+	MaxInterval time.Duration
+	// ratio of retries to requests allowed over 10 seconds, 0.2 if 0
+	BudgetRatio float64
+	// number of retries always allowed over 10 seconds, 10 if 0
+	BudgetMinRetries int
+}
+
+// Retry is a middleware that retries the requests failing with a network error, reported by NetworkErrorHandler.
+// Only requests with a method of the policy are retried, and only if their body can be read again.
+// The response of an attempt is streamed to the client as soon as it is not a network error,
+// so a request is never retried once something has been sent to the client.
 type Retry struct {
-	attempts int
-	next     http.Handler
+	attempts        int
+	methods         map[string]bool
+	initialInterval time.Duration
+	maxInterval     time.Duration
+	budget          *retryBudget
+	next            http.Handler
 }
 
-// NewRetry returns a new Retry instance
-func NewRetry(attempts int, next http.Handler) *Retry {
-	return &Retry{
-		attempts: attempts,
-		next:     next,
+// NewRetry returns a new Retry instance, with the default policy if policy is nil
+func NewRetry(attempts int, policy *RetryPolicy, next http.Handler) *Retry {
+	if policy == nil {
+		policy = &RetryPolicy{}
 	}
+	retry := &Retry{
+		attempts:        attempts,
+		methods:         make(map[string]bool),
+		initialInterval: policy.InitialInterval,
+		maxInterval:     policy.MaxInterval,
+		budget:          newRetryBudget(policy.BudgetRatio, policy.BudgetMinRetries),
+		next:            next,
+	}
+	methods := policy.Methods
+	if len(methods) == 0 {
+		methods = []string{"GET", "HEAD", "OPTIONS", "PUT", "DELETE", "TRACE"}
+	}
+	for _, method := range methods {
+		retry.methods[strings.ToUpper(method)] = true
+	}
+	if retry.initialInterval <= 0 {
+		retry.initialInterval = 100 * time.Millisecond
+	}
+	if retry.maxInterval <= 0 {
+		retry.maxInterval = time.Second
+	}
+	return retry
 }
 
 func (retry *Retry) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
 	// if we might make multiple attempts, swap the body for an ioutil.NopCloser
 	// cf https://github.com/containous/traefik/issues/1008
-	if retry.attempts > 1 {
+	// bodies read by the Buffering middleware are not closed and can be read again
+	_, buffered := r.Body.(*bufferedBody)
+	if retry.attempts > 1 && !buffered {
 		body := r.Body
 		defer body.Close()
 		r.Body = ioutil.NopCloser(body)
 	}
+	retry.budget.request()
+	retryable := retry.methods[r.Method] && (buffered || r.ContentLength == 0)
 	attempts := 1
 	for {
-		recorder := NewRecorder()
-		recorder.responseWriter = rw
-		retry.next.ServeHTTP(recorder, r)
-		if !isNetworkError(recorder.Code) || attempts >= retry.attempts {
-			utils.CopyHeaders(rw.Header(), recorder.Header())
-			rw.WriteHeader(recorder.Code)
-			rw.Write(recorder.Body.Bytes())
-			break
+		attempt := &retryAttempt{}
+		writer := &retryResponseWriter{
+			responseWriter: rw,
+			header:         make(http.Header),
+			retryable:      retryable && attempts < retry.attempts,
+			attempt:        attempt,
+		}
+		retry.next.ServeHTTP(writer, r.WithContext(context.WithValue(r.Context(), retryAttemptKey{}, attempt)))
+		if writer.failed == nil {
+			return
+		}
+		if !retry.budget.withdraw() {
+			log.Debugf("Retry budget exhausted for request: %v", r.URL)
+			writer.failed.sendTo(rw)
+			return
+		}
+		if !retry.wait(r, attempts) {
+			writer.failed.sendTo(rw)
+			return
 		}
 		attempts++
+		if body, ok := r.Body.(*bufferedBody); ok {
+			r.Body = body.reopen()
+		}
 		log.Debugf("New attempt %d for request: %v", attempts, r.URL)
 	}
 }
 
-func isNetworkError(status int) bool {
-	return status == http.StatusBadGateway || status == http.StatusGatewayTimeout
+// wait waits before the retry following the given attempt, with an exponential backoff and a full jitter.
+// It returns false if the client has gone away meanwhile.
+func (retry *Retry) wait(r *http.Request, attempt int) bool {
+	interval := retry.maxInterval
+	if attempt < 32 && retry.initialInterval<<uint(attempt-1) < interval {
+		interval = retry.initialInterval << uint(attempt-1)
+	}
+	timer := time.NewTimer(time.Duration(rand.Int63n(int64(interval)) + 1))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-r.Context().Done():
+		return false
+	}
+}
+
+// retryBudget limits the retries over a sliding window to a ratio of the requests, so that
+// retries do not pile up on a backend which is already failing.
+type retryBudget struct {
+	ratio      float64
+	minRetries int
+	now        func() time.Time
+	lock       sync.Mutex
+	// requests and retries of each second of the window
+	requests [retryBudgetSeconds]int
+	retries  [retryBudgetSeconds]int
+	second   int64
+}
+
+func newRetryBudget(ratio float64, minRetries int) *retryBudget {
+	if ratio <= 0 {
+		ratio = 0.2
+	}
+	if minRetries <= 0 {
+		minRetries = 10
+	}
+	return &retryBudget{ratio: ratio, minRetries: minRetries, now: time.Now}
+}
+
+// advance forgets the seconds which have left the window
+func (b *retryBudget) advance() int {
+	second := b.now().Unix()
+	for b.second < second {
+		b.second++
+		if second-b.second >= retryBudgetSeconds {
+			b.second = second
+			b.requests = [retryBudgetSeconds]int{}
+			b.retries = [retryBudgetSeconds]int{}
+			break
+		}
+		b.requests[b.second%retryBudgetSeconds] = 0
+		b.retries[b.second%retryBudgetSeconds] = 0
+	}
+	return int(second % retryBudgetSeconds)
+}
+
+func (b *retryBudget) request() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.requests[b.advance()]++
+}
+
+// withdraw returns whether a retry is allowed, counting it if so
+func (b *retryBudget) withdraw() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	current := b.advance()
+	requests, retries := 0, 0
+	for i := range b.requests {
+		requests += b.requests[i]
+		retries += b.retries[i]
+	}
+	if float64(retries) >= float64(b.minRetries)+b.ratio*float64(requests) {
+		return false
+	}
+	b.retries[current]++
+	return true
+}
+
+// retryResponseWriter streams the response of an attempt to the client, unless it is
+// a network error which can be retried, which is then recorded in failed
+type retryResponseWriter struct {
+	responseWriter http.ResponseWriter
+	header         http.Header
+	retryable      bool
+	attempt        *retryAttempt
+	written        bool
+	failed         *ResponseRecorder
+}
+
+func (w *retryResponseWriter) Header() http.Header {
+	if w.failed != nil {
+		return w.failed.Header()
+	}
+	return w.header
+}
+
+func (w *retryResponseWriter) WriteHeader(code int) {
+	if w.written || w.failed != nil {
+		return
+	}
+	if w.retryable && w.attempt.networkError {
+		w.failed = NewRecorder()
+		w.failed.responseWriter = w.responseWriter
+		utils.CopyHeaders(w.failed.Header(), w.header)
+		w.failed.WriteHeader(code)
+		return
+	}
+	w.written = true
+	utils.CopyHeaders(w.responseWriter.Header(), w.header)
+	w.responseWriter.WriteHeader(code)
+}
+
+func (w *retryResponseWriter) Write(buf []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	if w.failed != nil {
+		return w.failed.Write(buf)
+	}
+	return w.responseWriter.Write(buf)
+}
+
+// Flush sends any buffered data to the client, unless the attempt is to be retried.
+func (w *retryResponseWriter) Flush() {
+	if !w.written {
+		return
+	}
+	if flusher, ok := w.responseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// CloseNotify returns a channel that receives at most a
+// single value (true) when the client connection has gone
+// away.
+func (w *retryResponseWriter) CloseNotify() <-chan bool {
+	return w.responseWriter.(http.CloseNotifier).CloseNotify()
+}
+
+// Hijack hijacks the connection, which is then never retried
+func (w *retryResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.written = true
+	return w.responseWriter.(http.Hijacker).Hijack()
 }
 
 // ResponseRecorder is an implementation of http.ResponseWriter that
@@ -115,6 +345,13 @@ func (rw *ResponseRecorder) CloseNotify() <-chan bool {
 	return rw.responseWriter.(http.CloseNotifier).CloseNotify()
 }
 
+// sendTo sends the recorded response to rw
+func (rw *ResponseRecorder) sendTo(w http.ResponseWriter) {
+	utils.CopyHeaders(w.Header(), rw.Header())
+	w.WriteHeader(rw.Code)
+	w.Write(rw.Body.Bytes())
+}
+
 // Flush sends any buffered data to the client.
 func (rw *ResponseRecorder) Flush() {
 	_, err := rw.responseWriter.Write(rw.Body.Bytes())
@@ -0,0 +1,146 @@
+package middlewares
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testRetryPolicy = &RetryPolicy{InitialInterval: time.Millisecond, MaxInterval: time.Millisecond}
+
+// failingHandler answers with a network error to the first failures requests
+func failingHandler(failures int, attempts *int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*attempts++
+		if *attempts <= failures {
+			w.Header().Set("X-Attempt", "failed")
+			NetworkErrorHandler.ServeHTTP(w, r, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")})
+			return
+		}
+		w.Header().Set("X-Attempt", "succeeded")
+		w.Write([]byte("OK"))
+	})
+}
+
+func TestRetry(t *testing.T) {
+	attempts := 0
+	retry := NewRetry(3, testRetryPolicy, failingHandler(2, &attempts))
+
+	recorder := httptest.NewRecorder()
+	retry.ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "OK", recorder.Body.String())
+	assert.Equal(t, []string{"succeeded"}, recorder.HeaderMap["X-Attempt"], "headers of failed attempts should be dropped")
+
+	attempts = 0
+	retry = NewRetry(2, testRetryPolicy, failingHandler(2, &attempts))
+	recorder = httptest.NewRecorder()
+	retry.ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, 2, attempts)
+	assert.Equal(t, http.StatusBadGateway, recorder.Code, "the last failure should be returned")
+	assert.Equal(t, "failed", recorder.Header().Get("X-Attempt"))
+}
+
+func TestRetryServerError(t *testing.T) {
+	attempts := 0
+	retry := NewRetry(3, testRetryPolicy, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+	}))
+
+	recorder := httptest.NewRecorder()
+	retry.ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, 1, attempts, "a 502 of the server itself should not be retried")
+	assert.Equal(t, http.StatusBadGateway, recorder.Code)
+}
+
+func TestRetryMethods(t *testing.T) {
+	attempts := 0
+	retry := NewRetry(3, testRetryPolicy, failingHandler(1, &attempts))
+
+	recorder := httptest.NewRecorder()
+	retry.ServeHTTP(recorder, httptest.NewRequest("POST", "/", nil))
+	assert.Equal(t, 1, attempts, "non idempotent requests should not be retried")
+	assert.Equal(t, http.StatusBadGateway, recorder.Code)
+
+	attempts = 0
+	recorder = httptest.NewRecorder()
+	retry.ServeHTTP(recorder, httptest.NewRequest("PUT", "/", strings.NewReader("body")))
+	assert.Equal(t, 1, attempts, "requests with a body which cannot be read again should not be retried")
+
+	attempts = 0
+	retry = NewRetry(3, &RetryPolicy{Methods: []string{"post"}, InitialInterval: time.Millisecond}, failingHandler(1, &attempts))
+	recorder = httptest.NewRecorder()
+	retry.ServeHTTP(recorder, httptest.NewRequest("POST", "/", nil))
+	assert.Equal(t, 2, attempts, "methods of the policy should be retried")
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestRetryClientGone(t *testing.T) {
+	attempts := 0
+	retry := NewRetry(3, &RetryPolicy{InitialInterval: time.Hour, MaxInterval: time.Hour}, failingHandler(1, &attempts))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	recorder := httptest.NewRecorder()
+	retry.ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil).WithContext(ctx))
+	assert.Equal(t, 1, attempts, "requests of gone clients should not be retried")
+	assert.Equal(t, http.StatusBadGateway, recorder.Code)
+}
+
+func TestRetryStreaming(t *testing.T) {
+	release := make(chan struct{})
+	backend := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("first\n"))
+		w.(http.Flusher).Flush()
+		<-release
+		w.Write([]byte("second\n"))
+	})
+	server := httptest.NewServer(NewRetry(3, testRetryPolicy, backend))
+	defer server.Close()
+	defer close(release)
+
+	res, err := http.Get(server.URL)
+	assert.NoError(t, err, "there should be no error")
+	defer res.Body.Close()
+	done := make(chan string)
+	go func() {
+		line, _ := bufio.NewReader(res.Body).ReadString('\n')
+		done <- line
+	}()
+	select {
+	case line := <-done:
+		assert.Equal(t, "first\n", line, "the response should be streamed")
+	case <-time.After(5 * time.Second):
+		t.Fatal("the response is not streamed")
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	now := time.Unix(1000, 0)
+	budget := newRetryBudget(0.1, 1)
+	budget.now = func() time.Time {
+		return now
+	}
+	for i := 0; i < 10; i++ {
+		budget.request()
+	}
+	assert.True(t, budget.withdraw(), "the minimum retries should be allowed")
+	assert.True(t, budget.withdraw(), "a ratio of the requests should be allowed")
+	assert.False(t, budget.withdraw(), "retries beyond the budget should not be allowed")
+
+	now = now.Add(5 * time.Second)
+	assert.False(t, budget.withdraw(), "retries should be counted over the window")
+
+	now = now.Add(retryBudgetSeconds * time.Second)
+	assert.True(t, budget.withdraw(), "retries out of the window should be forgotten")
+}
@@ -0,0 +1,86 @@
+package middlewares
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/containous/traefik/types"
+)
+
+// SecurityHeaders is a middleware that redirects HTTP requests to HTTPS and sets
+// the security headers (HSTS, frame options, CSP...) of the responses.
+type SecurityHeaders struct {
+	config *types.SecurityHeaders
+	sts    string
+}
+
+// NewSecurityHeaders builds a new SecurityHeaders given a config
+func NewSecurityHeaders(config *types.SecurityHeaders) *SecurityHeaders {
+	sts := ""
+	if config.STSSeconds > 0 {
+		sts = fmt.Sprintf("max-age=%d", config.STSSeconds)
+		if config.STSIncludeSubdomains {
+			sts += "; includeSubdomains"
+		}
+		if config.STSPreload {
+			sts += "; preload"
+		}
+	}
+	return &SecurityHeaders{config: config, sts: sts}
+}
+
+func (s *SecurityHeaders) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if s.config.IsDevelopment {
+		next(rw, r)
+		return
+	}
+	isSSL := s.isSSL(r)
+	if s.config.SSLRedirect && !isSSL {
+		host := r.Host
+		if s.config.SSLHost != "" {
+			host = s.config.SSLHost
+		}
+		status := http.StatusMovedPermanently
+		if s.config.SSLTemporaryRedirect {
+			status = http.StatusTemporaryRedirect
+		}
+		http.Redirect(rw, r, "https://"+host+r.URL.RequestURI(), status)
+		return
+	}
+	next(newHeaderHookResponseWriter(rw, func(header http.Header) {
+		s.setHeaders(header, isSSL)
+	}), r)
+}
+
+func (s *SecurityHeaders) isSSL(r *http.Request) bool {
+	if r.TLS != nil {
+		return true
+	}
+	for name, value := range s.config.SSLProxyHeaders {
+		if r.Header.Get(name) == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *SecurityHeaders) setHeaders(header http.Header, isSSL bool) {
+	// browsers ignore HSTS over HTTP, it is only sent there on purpose
+	if s.sts != "" && (isSSL || s.config.ForceSTSHeader) {
+		header.Set("Strict-Transport-Security", s.sts)
+	}
+	if s.config.CustomFrameOptionsValue != "" {
+		header.Set("X-Frame-Options", s.config.CustomFrameOptionsValue)
+	} else if s.config.FrameDeny {
+		header.Set("X-Frame-Options", "DENY")
+	}
+	if s.config.ContentTypeNosniff {
+		header.Set("X-Content-Type-Options", "nosniff")
+	}
+	if s.config.ContentSecurityPolicy != "" {
+		header.Set("Content-Security-Policy", s.config.ContentSecurityPolicy)
+	}
+	if s.config.ReferrerPolicy != "" {
+		header.Set("Referrer-Policy", s.config.ReferrerPolicy)
+	}
+}
@@ -0,0 +1,80 @@
+package middlewares
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/codegangsta/negroni"
+	"github.com/containous/traefik/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveSecurityHeaders(config *types.SecurityHeaders, req *http.Request) *httptest.ResponseRecorder {
+	n := negroni.New(NewSecurityHeaders(config))
+	n.UseHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Frame-Options", "ALLOWALL")
+		w.WriteHeader(http.StatusOK)
+	}))
+	recorder := httptest.NewRecorder()
+	n.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	config := &types.SecurityHeaders{
+		STSSeconds:            31536000,
+		STSIncludeSubdomains:  true,
+		STSPreload:            true,
+		FrameDeny:             true,
+		ContentTypeNosniff:    true,
+		ContentSecurityPolicy: "default-src 'self'",
+		ReferrerPolicy:        "same-origin",
+	}
+	req := httptest.NewRequest("GET", "https://example.com/", nil)
+	req.TLS = &tls.ConnectionState{}
+	recorder := serveSecurityHeaders(config, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "max-age=31536000; includeSubdomains; preload", recorder.Header().Get("Strict-Transport-Security"))
+	assert.Equal(t, "DENY", recorder.Header().Get("X-Frame-Options"), "the backend value should be overwritten")
+	assert.Equal(t, "nosniff", recorder.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "default-src 'self'", recorder.Header().Get("Content-Security-Policy"))
+	assert.Equal(t, "same-origin", recorder.Header().Get("Referrer-Policy"))
+
+	recorder = serveSecurityHeaders(config, httptest.NewRequest("GET", "http://example.com/", nil))
+	assert.Empty(t, recorder.Header().Get("Strict-Transport-Security"), "HSTS should not be sent over HTTP")
+
+	config.ForceSTSHeader = true
+	config.CustomFrameOptionsValue = "SAMEORIGIN"
+	recorder = serveSecurityHeaders(config, httptest.NewRequest("GET", "http://example.com/", nil))
+	assert.NotEmpty(t, recorder.Header().Get("Strict-Transport-Security"))
+	assert.Equal(t, "SAMEORIGIN", recorder.Header().Get("X-Frame-Options"))
+}
+
+func TestSecurityHeadersSSLRedirect(t *testing.T) {
+	config := &types.SecurityHeaders{
+		SSLRedirect:     true,
+		SSLProxyHeaders: map[string]string{"X-Forwarded-Proto": "https"},
+	}
+
+	recorder := serveSecurityHeaders(config, httptest.NewRequest("GET", "http://example.com/foo?bar=1", nil))
+	assert.Equal(t, http.StatusMovedPermanently, recorder.Code)
+	assert.Equal(t, "https://example.com/foo?bar=1", recorder.Header().Get("Location"))
+
+	req := httptest.NewRequest("GET", "http://example.com/foo", nil)
+	req.Header.Set("X-Forwarded-Proto", "https")
+	assert.Equal(t, http.StatusOK, serveSecurityHeaders(config, req).Code, "requests received as HTTPS by a proxy should not be redirected")
+
+	config.SSLTemporaryRedirect = true
+	config.SSLHost = "secure.example.com"
+	recorder = serveSecurityHeaders(config, httptest.NewRequest("GET", "http://example.com/foo", nil))
+	assert.Equal(t, http.StatusTemporaryRedirect, recorder.Code)
+	assert.Equal(t, "https://secure.example.com/foo", recorder.Header().Get("Location"))
+
+	config.IsDevelopment = true
+	recorder = serveSecurityHeaders(config, httptest.NewRequest("GET", "http://example.com/foo", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code, "development mode should bypass everything")
+	assert.Equal(t, "ALLOWALL", recorder.Header().Get("X-Frame-Options"))
+}
@@ -0,0 +1,154 @@
+package middlewares
+
+import (
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/log"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+const (
+	// slowStartScale scales the weights of the servers in the load balancer of a backend with slow start,
+	// so that a server can start at a tenth of its weight
+	slowStartScale = 10
+	// slowStartSteps is how many times the weight of a server is raised during its slow start
+	slowStartSteps = 10
+)
+
+// SlowStart ramps up linearly the weight of the servers added to the load balancer of a backend, from a tenth of their
+// weight to their weight, so that a new or recovered server does not take its full share of the requests with cold
+// caches. It sits between the load balancer and what adds its servers: the configuration, the health check and the
+// outlier detection. The servers already in the load balancer of the backend before a configuration reload keep
+// their weight, or go on with their slow start.
+type SlowStart struct {
+	backend   string
+	duration  time.Duration
+	lb        slowStartLoadBalancer
+	lock      sync.Mutex
+	servers   map[string]*slowStartServer
+	stopped   bool
+	now       func() time.Time
+	afterFunc func(d time.Duration, f func()) *time.Timer
+	// start of the servers of the previous load balancer of the backend, zero if they had finished their slow start
+	previous map[string]time.Time
+}
+
+type slowStartLoadBalancer interface {
+	RemoveServer(u *url.URL) error
+	UpsertServer(u *url.URL, options ...roundrobin.ServerOption) error
+	Servers() []*url.URL
+}
+
+type slowStartServer struct {
+	url    *url.URL
+	weight int
+	start  time.Time
+	raise  *time.Timer
+}
+
+// NewSlowStart returns a new SlowStart of the servers of a load balancer, without slow start if duration is 0.
+// The servers of the SlowStart of the previous configuration of the backend, if any, keep their slow start.
+func NewSlowStart(backend string, duration time.Duration, lb slowStartLoadBalancer, previous *SlowStart) *SlowStart {
+	s := &SlowStart{
+		backend:   backend,
+		duration:  duration,
+		lb:        lb,
+		servers:   make(map[string]*slowStartServer),
+		now:       time.Now,
+		afterFunc: time.AfterFunc,
+		previous:  make(map[string]time.Time),
+	}
+	if previous != nil {
+		previous.lock.Lock()
+		for _, u := range previous.lb.Servers() {
+			var start time.Time
+			if server, ok := previous.servers[u.String()]; ok {
+				start = server.start
+			}
+			s.previous[u.String()] = start
+		}
+		previous.lock.Unlock()
+	}
+	return s
+}
+
+// UpsertServer adds a server to the load balancer at the start of its slow start, or updates its weight
+func (s *SlowStart) UpsertServer(u *url.URL, options ...roundrobin.ServerOption) error {
+	if s.duration <= 0 {
+		return s.lb.UpsertServer(u, options...)
+	}
+	weight, err := serverWeight(u, options...)
+	if err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	server := &slowStartServer{url: u, weight: weight, start: s.now()}
+	if start, ok := s.previous[u.String()]; ok {
+		server.start = start
+		delete(s.previous, u.String())
+	} else if current, ok := s.servers[u.String()]; ok {
+		server.start = current.start
+	} else {
+		for _, existing := range s.lb.Servers() {
+			if existing.String() == u.String() {
+				// already in the load balancer, with its slow start done
+				server.start = time.Time{}
+			}
+		}
+	}
+	return s.rampUp(server)
+}
+
+// rampUp sets the weight of a server in the load balancer given the elapsed part of its slow start,
+// and schedules its next raise until the end of its slow start
+func (s *SlowStart) rampUp(server *slowStartServer) error {
+	elapsed := s.now().Sub(server.start)
+	if elapsed >= s.duration {
+		delete(s.servers, server.url.String())
+		return s.lb.UpsertServer(server.url, roundrobin.Weight(server.weight*slowStartScale))
+	}
+	weight := server.weight + int(int64(server.weight*(slowStartScale-1))*int64(elapsed)/int64(s.duration))
+	if _, ok := s.servers[server.url.String()]; !ok {
+		log.Debugf("Slow start of server %s of backend %s over %s", server.url, s.backend, s.duration)
+	}
+	s.servers[server.url.String()] = server
+	if err := s.lb.UpsertServer(server.url, roundrobin.Weight(weight)); err != nil {
+		delete(s.servers, server.url.String())
+		return err
+	}
+	server.raise = s.afterFunc(s.duration/slowStartSteps, func() {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		// the server may have been removed or upserted again in the meantime
+		if !s.stopped && s.servers[server.url.String()] == server {
+			s.rampUp(server)
+		}
+	})
+	return nil
+}
+
+// RemoveServer removes a server from the load balancer, stopping its slow start
+func (s *SlowStart) RemoveServer(u *url.URL) error {
+	s.lock.Lock()
+	delete(s.servers, u.String())
+	s.lock.Unlock()
+	return s.lb.RemoveServer(u)
+}
+
+// Servers returns the URLs of the servers of the load balancer
+func (s *SlowStart) Servers() []*url.URL {
+	return s.lb.Servers()
+}
+
+// Stop stops the slow starts in progress, once the load balancer is replaced by a configuration reload
+func (s *SlowStart) Stop() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.stopped = true
+	for _, server := range s.servers {
+		server.raise.Stop()
+	}
+}
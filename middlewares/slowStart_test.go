@@ -0,0 +1,146 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vulcand/oxy/roundrobin"
+)
+
+// weights returns the weights of the servers of a balancer by host
+func weights(b *Balancer) map[string]int {
+	weights := make(map[string]int)
+	for _, server := range b.servers {
+		weights[server.url.Host] = server.weight
+	}
+	return weights
+}
+
+func TestSlowStartRampUp(t *testing.T) {
+	b := NewLeastConnBalancer(http.NotFoundHandler())
+	s := NewSlowStart("backend-slowstart", 100*time.Second, b, nil)
+	now := time.Now()
+	s.now = func() time.Time {
+		return now
+	}
+	var raises []func()
+	s.afterFunc = func(d time.Duration, f func()) *time.Timer {
+		raises = append(raises, f)
+		return time.NewTimer(d)
+	}
+	raise := func() {
+		pending := raises
+		raises = nil
+		for _, f := range pending {
+			f()
+		}
+	}
+
+	server := &url.URL{Scheme: "http", Host: "10.0.0.1:80"}
+	assert.NoError(t, s.UpsertServer(server, roundrobin.Weight(2)))
+	assert.Equal(t, map[string]int{"10.0.0.1:80": 2}, weights(b), "a new server should start at a tenth of its weight")
+
+	now = now.Add(50 * time.Second)
+	raise()
+	assert.Equal(t, map[string]int{"10.0.0.1:80": 11}, weights(b), "the weight should ramp up linearly")
+
+	now = now.Add(50 * time.Second)
+	raise()
+	assert.Equal(t, map[string]int{"10.0.0.1:80": 20}, weights(b), "the server should get its weight at the end of its slow start")
+	assert.Empty(t, raises, "the slow start should be over")
+}
+
+func TestSlowStartRecovery(t *testing.T) {
+	b := NewLeastConnBalancer(http.NotFoundHandler())
+	s := NewSlowStart("backend-slowstart", 100*time.Second, b, nil)
+	now := time.Now()
+	s.now = func() time.Time {
+		return now
+	}
+	var raises []func()
+	s.afterFunc = func(d time.Duration, f func()) *time.Timer {
+		raises = append(raises, f)
+		return time.NewTimer(d)
+	}
+	raise := func() {
+		pending := raises
+		raises = nil
+		for _, f := range pending {
+			f()
+		}
+	}
+
+	server1 := &url.URL{Scheme: "http", Host: "10.0.0.1:80"}
+	server2 := &url.URL{Scheme: "http", Host: "10.0.0.2:80"}
+	assert.NoError(t, s.UpsertServer(server1))
+	assert.NoError(t, s.UpsertServer(server2))
+	now = now.Add(100 * time.Second)
+	raise()
+	assert.Equal(t, map[string]int{"10.0.0.1:80": 10, "10.0.0.2:80": 10}, weights(b))
+
+	assert.NoError(t, s.RemoveServer(server1))
+	assert.NoError(t, s.UpsertServer(server1))
+	assert.Equal(t, map[string]int{"10.0.0.1:80": 1, "10.0.0.2:80": 10}, weights(b), "a recovered server should start slowly again")
+
+	assert.NoError(t, s.RemoveServer(server1))
+	raise()
+	assert.Equal(t, map[string]int{"10.0.0.2:80": 10}, weights(b), "a removed server should not be raised")
+}
+
+func TestSlowStartReload(t *testing.T) {
+	s := NewSlowStart("backend-reload", 100*time.Second, NewLeastConnBalancer(http.NotFoundHandler()), nil)
+	now := time.Now()
+	s.now = func() time.Time {
+		return now
+	}
+	var raises []func()
+	s.afterFunc = func(d time.Duration, f func()) *time.Timer {
+		raises = append(raises, f)
+		return time.NewTimer(d)
+	}
+	started := &url.URL{Scheme: "http", Host: "10.0.0.1:80"}
+	starting := &url.URL{Scheme: "http", Host: "10.0.0.2:80"}
+	assert.NoError(t, s.UpsertServer(started))
+	now = now.Add(100 * time.Second)
+	raises[0]()
+	assert.NoError(t, s.UpsertServer(starting))
+	now = now.Add(50 * time.Second)
+
+	b := NewLeastConnBalancer(http.NotFoundHandler())
+	reloaded := NewSlowStart("backend-reload", 100*time.Second, b, s)
+	reloaded.now = func() time.Time {
+		return now
+	}
+	s.Stop()
+	assert.NoError(t, reloaded.UpsertServer(started))
+	assert.NoError(t, reloaded.UpsertServer(starting))
+	assert.NoError(t, reloaded.UpsertServer(&url.URL{Scheme: "http", Host: "10.0.0.3:80"}))
+	assert.Equal(t, map[string]int{"10.0.0.1:80": 10, "10.0.0.2:80": 5, "10.0.0.3:80": 1}, weights(b),
+		"only the new servers should start slowly after a reload, the starting ones going on")
+	reloaded.Stop()
+}
+
+func TestSlowStartStop(t *testing.T) {
+	b := NewLeastConnBalancer(http.NotFoundHandler())
+	s := NewSlowStart("backend-stopped", 100*time.Second, b, nil)
+	var raise func()
+	s.afterFunc = func(d time.Duration, f func()) *time.Timer {
+		raise = f
+		return time.NewTimer(d)
+	}
+	assert.NoError(t, s.UpsertServer(&url.URL{Scheme: "http", Host: "10.0.0.1:80"}))
+
+	s.Stop()
+	raise()
+	assert.Equal(t, map[string]int{"10.0.0.1:80": 1}, weights(b), "a stopped slow start should not raise its servers")
+}
+
+func TestSlowStartDisabled(t *testing.T) {
+	b := NewLeastConnBalancer(http.NotFoundHandler())
+	s := NewSlowStart("backend-disabled", 0, b, nil)
+	assert.NoError(t, s.UpsertServer(&url.URL{Scheme: "http", Host: "10.0.0.1:80"}, roundrobin.Weight(3)))
+	assert.Equal(t, map[string]int{"10.0.0.1:80": 3}, weights(b), "the weights should be kept without slow start")
+}
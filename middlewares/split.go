@@ -0,0 +1,105 @@
+package middlewares
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"net/http"
+	"sort"
+
+	"github.com/containous/traefik/types"
+)
+
+// Split is a middleware sharing the requests of a frontend between weighted backends.
+// A client can be pinned to a backend by a cookie, or by the value of a header.
+// The requests go to the next handler, i.e. the backend of the frontend, when all the weights are 0.
+type Split struct {
+	backends []*splitBackend
+	total    int
+	cookie   string
+	header   string
+	random   func(n int) int
+}
+
+type splitBackend struct {
+	name    string
+	weight  int
+	handler http.Handler
+}
+
+// NewSplit builds a new Split given a config and the handlers of its backends
+func NewSplit(config *types.Split, handlers map[string]http.Handler) (*Split, error) {
+	split := &Split{
+		cookie: config.Cookie,
+		header: http.CanonicalHeaderKey(config.Header),
+		random: rand.Intn,
+	}
+	// the backends are sorted so that the same header value always gives the same backend
+	var names []string
+	for name := range config.Backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		weight := config.Backends[name]
+		if weight < 0 {
+			return nil, fmt.Errorf("Error creating Split: negative weight %d for backend %s", weight, name)
+		}
+		handler, ok := handlers[name]
+		if !ok {
+			return nil, fmt.Errorf("Error creating Split: undefined backend %s", name)
+		}
+		split.backends = append(split.backends, &splitBackend{name: name, weight: weight, handler: handler})
+		split.total += weight
+	}
+	return split, nil
+}
+
+func (s *Split) ServeHTTP(rw http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	if s.total == 0 {
+		next(rw, r)
+		return
+	}
+	if s.header != "" {
+		if value := r.Header.Get(s.header); value != "" {
+			hash := fnv.New32a()
+			hash.Write([]byte(value))
+			s.pick(int(hash.Sum32()%uint32(s.total))).handler.ServeHTTP(rw, r)
+			return
+		}
+	}
+	if s.cookie != "" {
+		if cookie, err := r.Cookie(s.cookie); err == nil {
+			if backend := s.get(cookie.Value); backend != nil {
+				backend.handler.ServeHTTP(rw, r)
+				return
+			}
+		}
+	}
+	backend := s.pick(s.random(s.total))
+	if s.cookie != "" {
+		http.SetCookie(rw, &http.Cookie{Name: s.cookie, Value: backend.name, Path: "/"})
+	}
+	backend.handler.ServeHTTP(rw, r)
+}
+
+// pick returns the backend owning the point n of [0, total)
+func (s *Split) pick(n int) *splitBackend {
+	for _, backend := range s.backends {
+		if n < backend.weight {
+			return backend
+		}
+		n -= backend.weight
+	}
+	return nil
+}
+
+// get returns the backend named name, if it still receives requests
+func (s *Split) get(name string) *splitBackend {
+	for _, backend := range s.backends {
+		if backend.name == name && backend.weight > 0 {
+			return backend
+		}
+	}
+	return nil
+}
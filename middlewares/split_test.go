@@ -0,0 +1,122 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/codegangsta/negroni"
+	"github.com/containous/traefik/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func namedHandler(name string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(name))
+	})
+}
+
+var splitBackends = map[string]http.Handler{
+	"stable": namedHandler("stable"),
+	"canary": namedHandler("canary"),
+}
+
+func TestSplitWeights(t *testing.T) {
+	split, err := NewSplit(&types.Split{Backends: map[string]int{"stable": 90, "canary": 10}}, splitBackends)
+	assert.NoError(t, err, "there should be no error")
+	handler := negroni.New(split)
+	handler.UseHandler(namedHandler("default"))
+	draws := []int{0, 9, 10, 99}
+	split.random = func(n int) int {
+		assert.Equal(t, 100, n)
+		draw := draws[0]
+		draws = draws[1:]
+		return draw
+	}
+
+	for _, expected := range []string{"canary", "canary", "stable", "stable"} {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+		assert.Equal(t, expected, recorder.Body.String())
+	}
+}
+
+func TestSplitNoWeight(t *testing.T) {
+	split, err := NewSplit(&types.Split{Backends: map[string]int{"stable": 0, "canary": 0}}, splitBackends)
+	assert.NoError(t, err, "there should be no error")
+	handler := negroni.New(split)
+	handler.UseHandler(namedHandler("default"))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, "default", recorder.Body.String(), "the backend of the frontend should be used")
+}
+
+func TestSplitCookie(t *testing.T) {
+	split, err := NewSplit(&types.Split{Backends: map[string]int{"stable": 90, "canary": 10}, Cookie: "_split"}, splitBackends)
+	assert.NoError(t, err, "there should be no error")
+	handler := negroni.New(split)
+	handler.UseHandler(namedHandler("default"))
+	split.random = func(n int) int {
+		return 0
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, "canary", recorder.Body.String())
+	assert.Equal(t, "_split=canary; Path=/", recorder.Header().Get("Set-Cookie"), "the client should be pinned to its backend")
+
+	split.random = func(n int) int {
+		return 99
+	}
+	req := httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(&http.Cookie{Name: "_split", Value: "canary"})
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	assert.Equal(t, "canary", recorder.Body.String(), "the cookie should pin the client")
+	assert.Empty(t, recorder.Header().Get("Set-Cookie"))
+
+	split, err = NewSplit(&types.Split{Backends: map[string]int{"stable": 100, "canary": 0}, Cookie: "_split"}, splitBackends)
+	assert.NoError(t, err, "there should be no error")
+	handler = negroni.New(split)
+	handler.UseHandler(namedHandler("default"))
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	assert.Equal(t, "stable", recorder.Body.String(), "a backend without weight should not receive pinned clients")
+	assert.Equal(t, "_split=stable; Path=/", recorder.Header().Get("Set-Cookie"))
+}
+
+func TestSplitHeader(t *testing.T) {
+	split, err := NewSplit(&types.Split{Backends: map[string]int{"stable": 50, "canary": 50}, Header: "x-user-id"}, splitBackends)
+	assert.NoError(t, err, "there should be no error")
+	handler := negroni.New(split)
+	handler.UseHandler(namedHandler("default"))
+	split.random = func(n int) int {
+		t.Fatal("a client with the header should not be balanced randomly")
+		return 0
+	}
+
+	backends := map[string]bool{}
+	for _, user := range []string{"1", "2", "3", "4", "5", "6", "7", "8"} {
+		var backend string
+		for i := 0; i < 3; i++ {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("X-User-Id", user)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+			if i > 0 {
+				assert.Equal(t, backend, recorder.Body.String(), "user %s should stay on its backend", user)
+			}
+			backend = recorder.Body.String()
+		}
+		backends[backend] = true
+	}
+	assert.Len(t, backends, 2, "the users should be shared between the backends")
+}
+
+func TestSplitInvalidConfig(t *testing.T) {
+	_, err := NewSplit(&types.Split{Backends: map[string]int{"stable": -1}}, map[string]http.Handler{"stable": namedHandler("stable")})
+	assert.Error(t, err)
+	_, err = NewSplit(&types.Split{Backends: map[string]int{"other": 1}}, map[string]http.Handler{"stable": namedHandler("stable")})
+	assert.Error(t, err)
+}
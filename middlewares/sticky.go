@@ -0,0 +1,144 @@
+package middlewares
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/types"
+)
+
+// StickySession sends the requests of a client to the server of its first request, with a cookie holding an opaque hash
+// of the server. It sits in front of the load balancer of a backend, the requests with a cookie of one of its servers
+// going straight to the forwarder.
+type StickySession struct {
+	backend  string
+	cookie   types.StickyCookie
+	next     http.Handler
+	lock     sync.RWMutex
+	lb       stickyLoadBalancer
+	drainer  *Drainer
+	sameSite string
+}
+
+type stickyLoadBalancer interface {
+	http.Handler
+	Servers() []*url.URL
+}
+
+// NewStickySession returns a new StickySession of a backend sending the stuck requests to next,
+// the forwarder of its load balancer set with SetLoadBalancer
+func NewStickySession(backend string, config *types.StickyCookie, next http.Handler) (*StickySession, error) {
+	s := &StickySession{backend: backend, next: next}
+	if config != nil {
+		s.cookie = *config
+	}
+	switch strings.ToLower(s.cookie.SameSite) {
+	case "":
+	case "lax":
+		s.sameSite = "Lax"
+	case "strict":
+		s.sameSite = "Strict"
+	case "none":
+		s.sameSite = "None"
+	default:
+		return nil, fmt.Errorf("Error creating StickySession: invalid SameSite %s", s.cookie.SameSite)
+	}
+	if s.cookie.MaxAge < 0 {
+		return nil, fmt.Errorf("Error creating StickySession: negative MaxAge %d", s.cookie.MaxAge)
+	}
+	if s.cookie.Name == "" {
+		s.cookie.Name = StickyCookieName(backend)
+	}
+	if s.cookie.Path == "" {
+		s.cookie.Path = "/"
+	}
+	return s, nil
+}
+
+// StickyCookieName returns the default name of the sticky cookie of a backend
+func StickyCookieName(backend string) string {
+	hash := sha256.Sum256([]byte(backend))
+	return "_TRAEFIK_BACKEND_" + hex.EncodeToString(hash[:4])
+}
+
+// SetLoadBalancer sets the load balancer choosing the server of the requests without cookie,
+// which must send them to the forwarder returned by Forwarder
+func (s *StickySession) SetLoadBalancer(lb stickyLoadBalancer) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.lb = lb
+}
+
+// SetDrainer sets the drainer of the backend, whose drained servers still get the requests of their sticky sessions
+func (s *StickySession) SetDrainer(drainer *Drainer) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.drainer = drainer
+}
+
+// Forwarder returns the forwarder of the load balancer, setting the cookie of the server chosen for a request
+func (s *StickySession) Forwarder() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Add("Set-Cookie", s.newCookie(r.URL))
+		s.next.ServeHTTP(rw, r)
+	})
+}
+
+func (s *StickySession) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	s.lock.RLock()
+	lb := s.lb
+	drainer := s.drainer
+	s.lock.RUnlock()
+	if cookie, err := r.Cookie(s.cookie.Name); err == nil {
+		// the server may have been removed since, by its provider or a health check, the drained servers
+		// still getting the requests of their sticky sessions
+		servers := lb.Servers()
+		if drainer != nil {
+			servers = append(servers, drainer.draining()...)
+		}
+		for _, server := range servers {
+			if s.cookieValue(server) == cookie.Value {
+				newReq := *r
+				newReq.URL = server
+				s.next.ServeHTTP(rw, &newReq)
+				return
+			}
+		}
+	}
+	lb.ServeHTTP(rw, r)
+}
+
+// cookieValue returns the opaque value of the cookie of a server, different for each backend
+func (s *StickySession) cookieValue(server *url.URL) string {
+	hash := sha256.Sum256([]byte(s.backend + "\n" + server.Scheme + "://" + server.Host))
+	return hex.EncodeToString(hash[:16])
+}
+
+// newCookie returns the Set-Cookie header of a server
+func (s *StickySession) newCookie(server *url.URL) string {
+	cookie := &http.Cookie{
+		Name:     s.cookie.Name,
+		Value:    s.cookieValue(server),
+		Path:     s.cookie.Path,
+		Domain:   s.cookie.Domain,
+		Secure:   s.cookie.Secure,
+		HttpOnly: s.cookie.HTTPOnly,
+		MaxAge:   s.cookie.MaxAge,
+	}
+	if s.cookie.MaxAge > 0 {
+		// for the clients not supporting Max-Age
+		cookie.Expires = time.Now().Add(time.Duration(s.cookie.MaxAge) * time.Second).UTC()
+	}
+	value := cookie.String()
+	// http.Cookie has no SameSite attribute before Go 1.11
+	if s.sameSite != "" {
+		value += "; SameSite=" + s.sameSite
+	}
+	return value
+}
@@ -0,0 +1,100 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/containous/traefik/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// stickyCookie returns the sticky cookie set by a response
+func stickyCookie(t *testing.T, recorder *httptest.ResponseRecorder) *http.Cookie {
+	cookies := (&http.Response{Header: recorder.Header()}).Cookies()
+	if !assert.Len(t, cookies, 1, "the response should set the sticky cookie") {
+		return nil
+	}
+	return cookies[0]
+}
+
+func TestStickySession(t *testing.T) {
+	counter := newRequestCounter(nil)
+	s, err := NewStickySession("backend1", nil, counter)
+	assert.NoError(t, err, "there should be no error")
+	b := NewLeastConnBalancer(s.Forwarder())
+	upsertServers(t, b, map[string]int{"10.0.0.1:80": 1, "10.0.0.2:80": 1, "10.0.0.3:80": 1})
+	s.SetLoadBalancer(b)
+
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, httptest.NewRequest("GET", "http://traefik/", nil))
+	cookie := stickyCookie(t, recorder)
+	assert.Equal(t, StickyCookieName("backend1"), cookie.Name)
+	assert.Equal(t, "/", cookie.Path)
+	assert.Len(t, cookie.Value, 32)
+	for host := range counter.requests {
+		assert.NotContains(t, cookie.Value, host, "the cookie should not expose the server")
+	}
+
+	for i := 0; i < 10; i++ {
+		req := httptest.NewRequest("GET", "http://traefik/", nil)
+		req.AddCookie(cookie)
+		recorder := httptest.NewRecorder()
+		s.ServeHTTP(recorder, req)
+		assert.Empty(t, recorder.Header().Get("Set-Cookie"), "a stuck request should not set the cookie again")
+	}
+	assert.Len(t, counter.requests, 1, "the requests with the cookie should all go to the same server")
+
+	for host := range counter.requests {
+		assert.NoError(t, b.RemoveServer(&url.URL{Scheme: "http", Host: host}))
+	}
+	req := httptest.NewRequest("GET", "http://traefik/", nil)
+	req.AddCookie(cookie)
+	recorder = httptest.NewRecorder()
+	s.ServeHTTP(recorder, req)
+	assert.Len(t, counter.requests, 2, "the requests stuck to a removed server should be balanced again")
+	assert.NotEqual(t, cookie.Value, stickyCookie(t, recorder).Value, "the cookie should stick to the new server")
+}
+
+func TestStickySessionBackends(t *testing.T) {
+	server := &url.URL{Scheme: "http", Host: "10.0.0.1:80"}
+	s1, err := NewStickySession("backend1", nil, http.NotFoundHandler())
+	assert.NoError(t, err, "there should be no error")
+	s2, err := NewStickySession("backend2", nil, http.NotFoundHandler())
+	assert.NoError(t, err, "there should be no error")
+	assert.NotEqual(t, s1.cookie.Name, s2.cookie.Name, "the cookies of two backends should not collide")
+	assert.NotEqual(t, s1.cookieValue(server), s2.cookieValue(server), "the cookies of a server should differ between backends")
+}
+
+func TestStickySessionCookieAttributes(t *testing.T) {
+	s, err := NewStickySession("backend1", &types.StickyCookie{
+		Name:     "affinity",
+		Secure:   true,
+		HTTPOnly: true,
+		SameSite: "strict",
+		Path:     "/app",
+		Domain:   "example.com",
+		MaxAge:   3600,
+	}, http.NotFoundHandler())
+	assert.NoError(t, err, "there should be no error")
+	b := NewLeastConnBalancer(s.Forwarder())
+	upsertServers(t, b, map[string]int{"10.0.0.1:80": 1})
+	s.SetLoadBalancer(b)
+
+	recorder := httptest.NewRecorder()
+	s.ServeHTTP(recorder, httptest.NewRequest("GET", "http://traefik/", nil))
+	header := recorder.Header().Get("Set-Cookie")
+	assert.True(t, strings.HasPrefix(header, "affinity="), header)
+	for _, attribute := range []string{"; Path=/app", "; Domain=example.com", "; Max-Age=3600", "; Expires=", "; HttpOnly", "; Secure", "; SameSite=Strict"} {
+		assert.Contains(t, header, attribute)
+	}
+}
+
+func TestNewStickySessionInvalid(t *testing.T) {
+	_, err := NewStickySession("backend1", &types.StickyCookie{SameSite: "loose"}, http.NotFoundHandler())
+	assert.Error(t, err)
+	_, err = NewStickySession("backend1", &types.StickyCookie{MaxAge: -1}, http.NotFoundHandler())
+	assert.Error(t, err)
+}
@@ -0,0 +1,117 @@
+package middlewares
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/containous/traefik/types"
+)
+
+const (
+	defaultDialTimeout         = 30 * time.Second
+	defaultTLSHandshakeTimeout = 10 * time.Second
+	defaultIdleConnTimeout     = 90 * time.Second
+)
+
+// NewTransport returns the transport of the connections of a backend to its servers, with the global
+// MaxIdleConnsPerHost and InsecureSkipVerify unless the backend sets its own
+func NewTransport(config *types.Transport, maxIdleConnsPerHost int, insecureSkipVerify bool) (*http.Transport, error) {
+	if config.DialTimeout < 0 || config.TLSHandshakeTimeout < 0 || config.ResponseHeaderTimeout < 0 || config.IdleConnTimeout < 0 ||
+		config.MaxIdleConnsPerHost < 0 || config.MaxConnsPerHost < 0 {
+		return nil, fmt.Errorf("Error creating transport: negative option in %+v", config)
+	}
+	dialer := &net.Dialer{
+		Timeout:   time.Duration(config.DialTimeout),
+		KeepAlive: 30 * time.Second,
+	}
+	if dialer.Timeout == 0 {
+		dialer.Timeout = defaultDialTimeout
+	}
+	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   config.MaxIdleConnsPerHost,
+		IdleConnTimeout:       time.Duration(config.IdleConnTimeout),
+		TLSHandshakeTimeout:   time.Duration(config.TLSHandshakeTimeout),
+		ResponseHeaderTimeout: time.Duration(config.ResponseHeaderTimeout),
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	if transport.MaxIdleConnsPerHost == 0 {
+		transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	}
+	if transport.IdleConnTimeout == 0 {
+		transport.IdleConnTimeout = defaultIdleConnTimeout
+	}
+	if transport.TLSHandshakeTimeout == 0 {
+		transport.TLSHandshakeTimeout = defaultTLSHandshakeTimeout
+	}
+	if config.MaxConnsPerHost > 0 {
+		limiter := &hostConnLimiter{max: config.MaxConnsPerHost, hosts: make(map[string]chan struct{})}
+		transport.DialContext = limiter.dialContext(dialer.DialContext)
+	}
+	if config.TLS != nil {
+		tlsConfig, err := config.TLS.CreateTLSConfig()
+		if err != nil {
+			return nil, fmt.Errorf("Error creating transport: %v", err)
+		}
+		if config.TLS.CA == "" {
+			// the servers are checked against the system CAs without CA
+			tlsConfig.RootCAs = nil
+		}
+		transport.TLSClientConfig = tlsConfig
+	} else if insecureSkipVerify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	return transport, nil
+}
+
+// hostConnLimiter limits the connections opened to each host, a dial beyond the limit waiting for a connection to close
+type hostConnLimiter struct {
+	max   int
+	lock  sync.Mutex
+	hosts map[string]chan struct{}
+}
+
+// dialContext returns dial limited to max connections by host
+func (l *hostConnLimiter) dialContext(dial func(ctx context.Context, network, address string) (net.Conn, error)) func(ctx context.Context, network, address string) (net.Conn, error) {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		l.lock.Lock()
+		slots, ok := l.hosts[address]
+		if !ok {
+			slots = make(chan struct{}, l.max)
+			l.hosts[address] = slots
+		}
+		l.lock.Unlock()
+
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		conn, err := dial(ctx, network, address)
+		if err != nil {
+			<-slots
+			return nil, err
+		}
+		return &limitedConn{Conn: conn, release: func() { <-slots }}, nil
+	}
+}
+
+// limitedConn is a connection releasing its slot in the hostConnLimiter once closed
+type limitedConn struct {
+	net.Conn
+	once    sync.Once
+	release func()
+}
+
+func (c *limitedConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(c.release)
+	return err
+}
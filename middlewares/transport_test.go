@@ -0,0 +1,106 @@
+package middlewares
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/containous/traefik/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewTransport(t *testing.T) {
+	transport, err := NewTransport(&types.Transport{}, 200, false)
+	assert.NoError(t, err)
+	assert.Equal(t, 200, transport.MaxIdleConnsPerHost, "the global MaxIdleConnsPerHost should be used by default")
+	assert.Equal(t, 10*time.Second, transport.TLSHandshakeTimeout)
+	assert.Equal(t, 90*time.Second, transport.IdleConnTimeout)
+	assert.Equal(t, time.Duration(0), transport.ResponseHeaderTimeout)
+	assert.Nil(t, transport.TLSClientConfig)
+
+	transport, err = NewTransport(&types.Transport{
+		TLSHandshakeTimeout:   types.Duration(3 * time.Second),
+		ResponseHeaderTimeout: types.Duration(time.Minute),
+		IdleConnTimeout:       types.Duration(30 * time.Second),
+		MaxIdleConnsPerHost:   10,
+	}, 200, true)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, 3*time.Second, transport.TLSHandshakeTimeout)
+	assert.Equal(t, time.Minute, transport.ResponseHeaderTimeout)
+	assert.Equal(t, 30*time.Second, transport.IdleConnTimeout)
+	if assert.NotNil(t, transport.TLSClientConfig) {
+		assert.True(t, transport.TLSClientConfig.InsecureSkipVerify, "the global InsecureSkipVerify should be used without TLS")
+	}
+
+	_, err = NewTransport(&types.Transport{MaxConnsPerHost: -1}, 200, false)
+	assert.Error(t, err)
+}
+
+func TestTransportResponseHeaderTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	transport, err := NewTransport(&types.Transport{ResponseHeaderTimeout: types.Duration(50 * time.Millisecond)}, 0, false)
+	assert.NoError(t, err)
+	_, err = (&http.Client{Transport: transport}).Get(server.URL)
+	assert.Error(t, err, "a server slower than the response header timeout should fail")
+}
+
+func TestTransportTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	transport, err := NewTransport(&types.Transport{}, 0, false)
+	assert.NoError(t, err)
+	_, err = (&http.Client{Transport: transport}).Get(server.URL)
+	assert.Error(t, err, "a server with an unknown CA should be rejected")
+
+	transport, err = NewTransport(&types.Transport{TLS: &types.ClientTLS{InsecureSkipVerify: true, ServerName: "backend.local"}}, 0, false)
+	assert.NoError(t, err)
+	assert.Equal(t, "backend.local", transport.TLSClientConfig.ServerName)
+	assert.Nil(t, transport.TLSClientConfig.RootCAs, "the system CAs should be used without CA")
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestHostConnLimiter(t *testing.T) {
+	limiter := &hostConnLimiter{max: 1, hosts: make(map[string]chan struct{})}
+	dial := limiter.dialContext(func(ctx context.Context, network, address string) (net.Conn, error) {
+		client, _ := net.Pipe()
+		return client, nil
+	})
+
+	conn, err := dial(context.Background(), "tcp", "10.0.0.1:80")
+	assert.NoError(t, err)
+	other, err := dial(context.Background(), "tcp", "10.0.0.2:80")
+	assert.NoError(t, err, "the connections should be limited by host")
+	other.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = dial(ctx, "tcp", "10.0.0.1:80")
+	assert.Equal(t, context.DeadlineExceeded, err, "a connection beyond the limit should wait for one to close")
+
+	dialed := make(chan net.Conn)
+	go func() {
+		conn, _ := dial(context.Background(), "tcp", "10.0.0.1:80")
+		dialed <- conn
+	}()
+	conn.Close()
+	conn.Close()
+	select {
+	case conn := <-dialed:
+		assert.NotNil(t, conn, "a closed connection should let another one open")
+	case <-time.After(time.Second):
+		t.Fatal("a closed connection should let another one open")
+	}
+}
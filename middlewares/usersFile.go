@@ -0,0 +1,133 @@
+package middlewares
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/containous/traefik/log"
+	"github.com/containous/traefik/safe"
+	"github.com/containous/traefik/types"
+	"gopkg.in/fsnotify.v1"
+)
+
+// usersFile holds the users of a htpasswd like file, reloaded when the file changes
+type usersFile struct {
+	key     string
+	path    string
+	parse   func(types.Users) (map[string]string, error)
+	content []byte
+	users   *safe.Safe
+	watcher *fsnotify.Watcher
+	refs    int
+}
+
+var (
+	usersFilesLock sync.Mutex
+	usersFiles     = map[string]*usersFile{}
+)
+
+// watchUsersFile returns the users of a file, parsed with parse and kept up to date with the file.
+// The authenticators are created again on each configuration reload, so a file is watched only once
+// for a given kind of users, and its watcher is shared by all the authenticators using it until they release it.
+func watchUsersFile(kind string, path string, parse func(types.Users) (map[string]string, error)) (*usersFile, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	usersFilesLock.Lock()
+	defer usersFilesLock.Unlock()
+	key := kind + ":" + path
+	if file, ok := usersFiles[key]; ok {
+		file.refs++
+		return file, nil
+	}
+
+	file := &usersFile{key: key, path: path, parse: parse, users: safe.New(map[string]string{})}
+	if err := file.load(); err != nil {
+		return nil, err
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	// watch the directory, as editors and Kubernetes secrets replace files rather than write them
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+	safe.Go(func() {
+		for {
+			select {
+			case _, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if err := file.load(); err != nil {
+					log.Errorf("Error reloading users file %s, keeping the previous users: %v", path, err)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Errorf("Error watching users file %s: %v", path, err)
+			}
+		}
+	})
+	file.watcher = watcher
+	file.refs = 1
+	usersFiles[key] = file
+	return file, nil
+}
+
+// release releases the file for an authenticator, and stops watching it once no authenticator uses it anymore
+func (f *usersFile) release() {
+	usersFilesLock.Lock()
+	defer usersFilesLock.Unlock()
+	f.refs--
+	if f.refs > 0 {
+		return
+	}
+	delete(usersFiles, f.key)
+	// closing the watcher closes its channels, which ends its goroutine
+	if err := f.watcher.Close(); err != nil {
+		log.Errorf("Error closing the watcher of users file %s: %v", f.path, err)
+	}
+}
+
+// load reads the file and swaps its users if it has changed. Empty lines and # comments are ignored.
+func (f *usersFile) load() error {
+	content, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		return err
+	}
+	if f.content != nil && bytes.Equal(content, f.content) {
+		return nil
+	}
+	var lines types.Users
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	users, err := f.parse(lines)
+	if err != nil {
+		return fmt.Errorf("Error parsing users file %s: %v", f.path, err)
+	}
+	f.content = content
+	f.users.Set(users)
+	log.Debugf("Loaded %d users from %s", len(users), f.path)
+	return nil
+}
+
+// get returns the secret of a user, if the file holds it
+func (f *usersFile) get(user string) (string, bool) {
+	secret, ok := f.users.Get().(map[string]string)[user]
+	return secret, ok
+}
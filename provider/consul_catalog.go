@@ -213,13 +213,20 @@ func (provider *ConsulCatalog) getContraintTags(tags []string) []string {
 
 func (provider *ConsulCatalog) buildConfig(catalog []catalogUpdate) *types.Configuration {
 	var FuncMap = template.FuncMap{
-		"getBackend":           provider.getBackend,
-		"getFrontendRule":      provider.getFrontendRule,
-		"getBackendName":       provider.getBackendName,
-		"getBackendAddress":    provider.getBackendAddress,
-		"getAttribute":         provider.getAttribute,
-		"getEntryPoints":       provider.getEntryPoints,
-		"hasMaxconnAttributes": provider.hasMaxconnAttributes,
+		"getBackend":                 provider.getBackend,
+		"getFrontendRule":            provider.getFrontendRule,
+		"getBackendName":             provider.getBackendName,
+		"getBackendAddress":          provider.getBackendAddress,
+		"getAttribute":               provider.getAttribute,
+		"getEntryPoints":             provider.getEntryPoints,
+		"hasMaxconnAttributes":       provider.hasMaxconnAttributes,
+		"getHealthCheck":             provider.getHealthCheck,
+		"getOutlierDetection":        provider.getOutlierDetection,
+		"getTransport":               provider.getTransport,
+		"getFrontendSecurityHeaders": provider.getFrontendSecurityHeaders,
+		"getSplit":                   provider.getSplit,
+		"getHashKey":                 provider.getHashKey,
+		"getStickyCookie":            provider.getStickyCookie,
 	}
 
 	allNodes := []*api.ServiceEntry{}
@@ -263,6 +270,52 @@ func (provider *ConsulCatalog) hasMaxconnAttributes(attributes []string) bool {
 	return false
 }
 
+// getHealthCheck parses the traefik.backend.healthcheck.<option>=value tags
+func (provider *ConsulCatalog) getHealthCheck(attributes []string) *types.HealthCheck {
+	return getHealthCheck(getAttributeLabels(attributes), DefaultConsulCatalogTagPrefix+".backend.healthcheck")
+}
+
+// getOutlierDetection parses the traefik.backend.outlierdetection.<option>=value tags
+func (provider *ConsulCatalog) getOutlierDetection(attributes []string) *types.OutlierDetection {
+	return getOutlierDetection(getAttributeLabels(attributes), DefaultConsulCatalogTagPrefix+".backend.outlierdetection")
+}
+
+// getFrontendSecurityHeaders parses the traefik.frontend.securityHeaders.<option>=value tags
+func (provider *ConsulCatalog) getFrontendSecurityHeaders(attributes []string) *types.SecurityHeaders {
+	return getSecurityHeaders(getAttributeLabels(attributes), DefaultConsulCatalogTagPrefix+".frontend.securityHeaders")
+}
+
+// getSplit parses the traefik.frontend.split.backends=service1:weight1,service2:weight2, cookie and header tags
+func (provider *ConsulCatalog) getSplit(attributes []string) *types.Split {
+	return getSplit(getAttributeLabels(attributes), DefaultConsulCatalogTagPrefix+".frontend.split")
+}
+
+// getTransport parses the traefik.backend.transport.<option>=value tags
+func (provider *ConsulCatalog) getTransport(attributes []string) *types.Transport {
+	return getTransport(getAttributeLabels(attributes), DefaultConsulCatalogTagPrefix+".backend.transport")
+}
+
+// getHashKey parses the traefik.backend.loadbalancer.hash.<option>=value tags
+func (provider *ConsulCatalog) getHashKey(attributes []string) *types.HashKey {
+	return getHashKey(getAttributeLabels(attributes), DefaultConsulCatalogTagPrefix+".backend.loadbalancer.hash")
+}
+
+// getStickyCookie parses the traefik.backend.loadbalancer.stickycookie.<option>=value tags
+func (provider *ConsulCatalog) getStickyCookie(attributes []string) *types.StickyCookie {
+	return getStickyCookie(getAttributeLabels(attributes), DefaultConsulCatalogTagPrefix+".backend.loadbalancer.stickycookie")
+}
+
+// getAttributeLabels returns the key=value tags as labels
+func getAttributeLabels(attributes []string) map[string]string {
+	labels := map[string]string{}
+	for _, attribute := range attributes {
+		if kv := strings.SplitN(attribute, "=", 2); len(kv) == 2 {
+			labels[kv[0]] = kv[1]
+		}
+	}
+	return labels
+}
+
 func (provider *ConsulCatalog) getNodes(index map[string][]string) ([]catalogUpdate, error) {
 	visited := make(map[string]bool)
 
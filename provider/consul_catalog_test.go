@@ -83,6 +83,38 @@ func TestConsulCatalogGetAttribute(t *testing.T) {
 	}
 }
 
+func TestConsulCatalogGetSplit(t *testing.T) {
+	provider := &ConsulCatalog{
+		Domain: "localhost",
+	}
+
+	services := []struct {
+		attributes []string
+		expected   *types.Split
+	}{
+		{
+			attributes: []string{
+				"traefik.backend.weight=42",
+			},
+			expected: nil,
+		},
+		{
+			attributes: []string{
+				"traefik.frontend.split.backends=stable:90,canary:10",
+				"traefik.frontend.split.header=X-User-Id",
+			},
+			expected: &types.Split{Backends: map[string]int{"stable": 90, "canary": 10}, Header: "X-User-Id"},
+		},
+	}
+
+	for _, e := range services {
+		actual := provider.getSplit(e.attributes)
+		if !reflect.DeepEqual(actual, e.expected) {
+			t.Fatalf("expected %+v, got %+v", e.expected, actual)
+		}
+	}
+}
+
 func TestConsulCatalogGetBackendAddress(t *testing.T) {
 	provider := &ConsulCatalog{
 		Domain: "localhost",
@@ -239,25 +239,52 @@ func (provider *Docker) Provide(configurationChan chan<- types.ConfigMessage, po
 
 func (provider *Docker) loadDockerConfig(containersInspected []dockerData) *types.Configuration {
 	var DockerFuncMap = template.FuncMap{
-		"getBackend":                  provider.getBackend,
-		"getIPAddress":                provider.getIPAddress,
-		"getPort":                     provider.getPort,
-		"getWeight":                   provider.getWeight,
-		"getDomain":                   provider.getDomain,
-		"getProtocol":                 provider.getProtocol,
-		"getPassHostHeader":           provider.getPassHostHeader,
-		"getPriority":                 provider.getPriority,
-		"getEntryPoints":              provider.getEntryPoints,
-		"getFrontendRule":             provider.getFrontendRule,
-		"hasCircuitBreakerLabel":      provider.hasCircuitBreakerLabel,
-		"getCircuitBreakerExpression": provider.getCircuitBreakerExpression,
-		"hasLoadBalancerLabel":        provider.hasLoadBalancerLabel,
-		"getLoadBalancerMethod":       provider.getLoadBalancerMethod,
-		"hasMaxConnLabels":            provider.hasMaxConnLabels,
-		"getMaxConnAmount":            provider.getMaxConnAmount,
-		"getMaxConnExtractorFunc":     provider.getMaxConnExtractorFunc,
-		"getSticky":                   provider.getSticky,
-		"getIsBackendLBSwarm":         provider.getIsBackendLBSwarm,
+		"getBackend":                   provider.getBackend,
+		"getIPAddress":                 provider.getIPAddress,
+		"getPort":                      provider.getPort,
+		"getWeight":                    provider.getWeight,
+		"getDomain":                    provider.getDomain,
+		"getProtocol":                  provider.getProtocol,
+		"getPassHostHeader":            provider.getPassHostHeader,
+		"getPriority":                  provider.getPriority,
+		"getEntryPoints":               provider.getEntryPoints,
+		"getFrontendRule":              provider.getFrontendRule,
+		"hasCircuitBreakerLabel":       provider.hasCircuitBreakerLabel,
+		"getCircuitBreakerExpression":  provider.getCircuitBreakerExpression,
+		"hasLoadBalancerLabel":         provider.hasLoadBalancerLabel,
+		"getLoadBalancerMethod":        provider.getLoadBalancerMethod,
+		"hasMaxConnLabels":             provider.hasMaxConnLabels,
+		"getMaxConnAmount":             provider.getMaxConnAmount,
+		"getMaxConnExtractorFunc":      provider.getMaxConnExtractorFunc,
+		"getBuffering":                 provider.getBuffering,
+		"getSticky":                    provider.getSticky,
+		"getSlowStart":                 provider.getSlowStart,
+		"getIsBackendLBSwarm":          provider.getIsBackendLBSwarm,
+		"hasRateLimitLabels":           provider.hasRateLimitLabels,
+		"getRateLimitExtractorFunc":    provider.getRateLimitExtractorFunc,
+		"getRateLimitTrustedProxies":   provider.getRateLimitTrustedProxies,
+		"getRateLimits":                provider.getRateLimits,
+		"hasIPFilterLabels":            provider.hasIPFilterLabels,
+		"getIPFilterWhitelist":         provider.getIPFilterWhitelist,
+		"getIPFilterBlacklist":         provider.getIPFilterBlacklist,
+		"getIPFilterForwardedForDepth": provider.getIPFilterForwardedForDepth,
+		"getRequestHeaders":            provider.getRequestHeaders,
+		"getResponseHeaders":           provider.getResponseHeaders,
+		"getFrontendSecurityHeaders":   provider.getFrontendSecurityHeaders,
+		"getCORS":                      provider.getCORS,
+		"getBasicAuth":                 provider.getBasicAuth,
+		"getDigestAuth":                provider.getDigestAuth,
+		"getForwardAuth":               provider.getForwardAuth,
+		"getJWTAuth":                   provider.getJWTAuth,
+		"getMirror":                    provider.getMirror,
+		"getSplit":                     provider.getSplit,
+		"getErrorPages":                provider.getErrorPages,
+		"getCircuitBreakerFallback":    provider.getCircuitBreakerFallback,
+		"getHealthCheck":               provider.getHealthCheck,
+		"getOutlierDetection":          provider.getOutlierDetection,
+		"getTransport":                 provider.getTransport,
+		"getHashKey":                   provider.getHashKey,
+		"getStickyCookie":              provider.getStickyCookie,
 	}
 	// filter containers
 	filteredContainers := fun.Filter(func(container dockerData) bool {
@@ -306,7 +333,8 @@ func (provider *Docker) hasCircuitBreakerLabel(container dockerData) bool {
 func (provider *Docker) hasLoadBalancerLabel(container dockerData) bool {
 	_, errMethod := getLabel(container, "traefik.backend.loadbalancer.method")
 	_, errSticky := getLabel(container, "traefik.backend.loadbalancer.sticky")
-	if errMethod != nil && errSticky != nil {
+	_, errSlowStart := getLabel(container, "traefik.backend.loadbalancer.slowstart")
+	if errMethod != nil && errSticky != nil && errSlowStart != nil && provider.getStickyCookie(container) == nil && provider.getHashKey(container) == nil {
 		return false
 	}
 	return true
@@ -322,6 +350,10 @@ func (provider *Docker) hasMaxConnLabels(container dockerData) bool {
 	return true
 }
 
+func (provider *Docker) hasRateLimitLabels(container dockerData) bool {
+	return len(provider.getRateLimits(container)) > 0
+}
+
 func (provider *Docker) getCircuitBreakerExpression(container dockerData) string {
 	if label, err := getLabel(container, "traefik.backend.circuitbreaker.expression"); err == nil {
 		return label
@@ -348,6 +380,167 @@ func (provider *Docker) getMaxConnAmount(container dockerData) int64 {
 	return math.MaxInt64
 }
 
+func (provider *Docker) getRateLimitExtractorFunc(container dockerData) string {
+	if label, err := getLabel(container, "traefik.frontend.rateLimit.extractorFunc"); err == nil {
+		return label
+	}
+	return "client.ip"
+}
+
+func (provider *Docker) getRateLimitTrustedProxies(container dockerData) []string {
+	if label, err := getLabel(container, "traefik.frontend.rateLimit.trustedProxies"); err == nil {
+		return strings.Split(label, ",")
+	}
+	return []string{}
+}
+
+// getRateLimits returns the rates defined with traefik.frontend.rateLimit.rateSet.<name>.{period,average,burst} labels
+func (provider *Docker) getRateLimits(container dockerData) map[string]*types.Rate {
+	prefix := "traefik.frontend.rateLimit.rateSet."
+	rates := map[string]*types.Rate{}
+	for label, value := range container.Labels {
+		if !strings.HasPrefix(label, prefix) {
+			continue
+		}
+		parts := strings.SplitN(strings.TrimPrefix(label, prefix), ".", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		rate, ok := rates[parts[0]]
+		if !ok {
+			rate = &types.Rate{}
+			rates[parts[0]] = rate
+		}
+		var err error
+		switch parts[1] {
+		case "period":
+			err = rate.Period.UnmarshalText([]byte(value))
+		case "average":
+			rate.Average, err = strconv.ParseInt(value, 10, 64)
+		case "burst":
+			rate.Burst, err = strconv.ParseInt(value, 10, 64)
+		}
+		if err != nil {
+			log.Errorf("Unable to parse %s %s", label, value)
+		}
+	}
+	return rates
+}
+
+func (provider *Docker) hasIPFilterLabels(container dockerData) bool {
+	return len(provider.getIPFilterWhitelist(container)) > 0 || len(provider.getIPFilterBlacklist(container)) > 0
+}
+
+func (provider *Docker) getIPFilterWhitelist(container dockerData) []string {
+	if label, err := getLabel(container, "traefik.frontend.ipFilter.whitelist"); err == nil {
+		return strings.Split(label, ",")
+	}
+	return []string{}
+}
+
+func (provider *Docker) getIPFilterBlacklist(container dockerData) []string {
+	if label, err := getLabel(container, "traefik.frontend.ipFilter.blacklist"); err == nil {
+		return strings.Split(label, ",")
+	}
+	return []string{}
+}
+
+func (provider *Docker) getIPFilterForwardedForDepth(container dockerData) int {
+	if label, err := getLabel(container, "traefik.frontend.ipFilter.forwardedForDepth"); err == nil {
+		i, errConv := strconv.Atoi(label)
+		if errConv != nil {
+			log.Errorf("Unable to parse traefik.frontend.ipFilter.forwardedForDepth %s", label)
+			return 0
+		}
+		return i
+	}
+	return 0
+}
+
+func (provider *Docker) getRequestHeaders(container dockerData) *types.HeaderOperations {
+	return getHeaderOperations(container.Labels, "traefik.frontend.headers.request")
+}
+
+func (provider *Docker) getResponseHeaders(container dockerData) *types.HeaderOperations {
+	return getHeaderOperations(container.Labels, "traefik.frontend.headers.response")
+}
+
+func (provider *Docker) getFrontendSecurityHeaders(container dockerData) *types.SecurityHeaders {
+	return getSecurityHeaders(container.Labels, "traefik.frontend.securityHeaders")
+}
+
+func (provider *Docker) getCORS(container dockerData) *types.CORS {
+	return getCORS(container.Labels, "traefik.frontend.cors")
+}
+
+func (provider *Docker) getForwardAuth(container dockerData) *types.Forward {
+	return getForwardAuth(container.Labels, "traefik.frontend.auth.forward")
+}
+
+func (provider *Docker) getBuffering(container dockerData) *types.Buffering {
+	return getBuffering(container.Labels, "traefik.backend.buffering")
+}
+
+func (provider *Docker) getHealthCheck(container dockerData) *types.HealthCheck {
+	return getHealthCheck(container.Labels, "traefik.backend.healthcheck")
+}
+
+func (provider *Docker) getOutlierDetection(container dockerData) *types.OutlierDetection {
+	return getOutlierDetection(container.Labels, "traefik.backend.outlierdetection")
+}
+
+func (provider *Docker) getTransport(container dockerData) *types.Transport {
+	return getTransport(container.Labels, "traefik.backend.transport")
+}
+
+func (provider *Docker) getHashKey(container dockerData) *types.HashKey {
+	return getHashKey(container.Labels, "traefik.backend.loadbalancer.hash")
+}
+
+func (provider *Docker) getStickyCookie(container dockerData) *types.StickyCookie {
+	return getStickyCookie(container.Labels, "traefik.backend.loadbalancer.stickycookie")
+}
+
+func (provider *Docker) getBasicAuth(container dockerData) *types.Basic {
+	return getBasicAuth(container.Labels, "traefik.frontend.auth.basic")
+}
+
+func (provider *Docker) getDigestAuth(container dockerData) *types.Digest {
+	return getDigestAuth(container.Labels, "traefik.frontend.auth.digest")
+}
+
+func (provider *Docker) getJWTAuth(container dockerData) *types.JWT {
+	return getJWTAuth(container.Labels, "traefik.frontend.auth.jwt")
+}
+
+func (provider *Docker) getMirror(container dockerData) *types.Mirror {
+	mirror := getMirror(container.Labels, "traefik.frontend.mirror")
+	if mirror != nil {
+		mirror.Backend = normalize(mirror.Backend)
+	}
+	return mirror
+}
+
+func (provider *Docker) getErrorPages(container dockerData) map[string]*types.ErrorPage {
+	errorPages := getErrorPages(container.Labels, "traefik.frontend.errors")
+	for _, errorPage := range errorPages {
+		errorPage.Backend = normalize(errorPage.Backend)
+	}
+	return errorPages
+}
+
+func (provider *Docker) getCircuitBreakerFallback(container dockerData) *types.Fallback {
+	fallback := getFallback(container.Labels, "traefik.backend.circuitbreaker.fallback")
+	if fallback != nil && len(fallback.Backend) > 0 {
+		fallback.Backend = normalize(fallback.Backend)
+	}
+	return fallback
+}
+
+func (provider *Docker) getSplit(container dockerData) *types.Split {
+	return normalizeSplit(getSplit(container.Labels, "traefik.frontend.split"))
+}
+
 func (provider *Docker) getMaxConnExtractorFunc(container dockerData) string {
 	if label, err := getLabel(container, "traefik.backend.maxconn.extractorfunc"); err == nil {
 		return label
@@ -462,6 +655,13 @@ func (provider *Docker) getSticky(container dockerData) string {
 	return "false"
 }
 
+func (provider *Docker) getSlowStart(container dockerData) string {
+	if label, err := getLabel(container, "traefik.backend.loadbalancer.slowstart"); err == nil {
+		return label
+	}
+	return "0s"
+}
+
 func (provider *Docker) getIsBackendLBSwarm(container dockerData) string {
 	if label, err := getLabel(container, "traefik.backend.loadbalancer.swarm"); err == nil {
 		return label
@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/containous/traefik/types"
 	docker "github.com/docker/engine-api/types"
@@ -582,6 +583,111 @@ func TestDockerGetPassHostHeader(t *testing.T) {
 	}
 }
 
+func TestDockerGetRateLimits(t *testing.T) {
+	provider := &Docker{}
+	containers := []struct {
+		container docker.ContainerJSON
+		expected  map[string]*types.Rate
+	}{
+		{
+			container: docker.ContainerJSON{
+				ContainerJSONBase: &docker.ContainerJSONBase{
+					Name: "foo",
+				},
+				Config: &container.Config{},
+			},
+			expected: map[string]*types.Rate{},
+		},
+		{
+			container: docker.ContainerJSON{
+				ContainerJSONBase: &docker.ContainerJSONBase{
+					Name: "test",
+				},
+				Config: &container.Config{
+					Labels: map[string]string{
+						"traefik.frontend.rateLimit.rateSet.short.period":  "1s",
+						"traefik.frontend.rateLimit.rateSet.short.average": "10",
+						"traefik.frontend.rateLimit.rateSet.short.burst":   "20",
+						"traefik.frontend.rateLimit.rateSet.long.period":   "1m",
+						"traefik.frontend.rateLimit.rateSet.long.average":  "300",
+					},
+				},
+			},
+			expected: map[string]*types.Rate{
+				"short": {Period: types.Duration(time.Second), Average: 10, Burst: 20},
+				"long":  {Period: types.Duration(time.Minute), Average: 300},
+			},
+		},
+	}
+
+	for _, e := range containers {
+		dockerData := parseContainer(e.container)
+		actual := provider.getRateLimits(dockerData)
+		if !reflect.DeepEqual(actual, e.expected) {
+			t.Fatalf("expected %+v, got %+v", e.expected, actual)
+		}
+	}
+}
+
+func TestDockerGetIPFilter(t *testing.T) {
+	provider := &Docker{}
+	containers := []struct {
+		container         docker.ContainerJSON
+		expectedWhitelist []string
+		expectedBlacklist []string
+		expectedDepth     int
+	}{
+		{
+			container: docker.ContainerJSON{
+				ContainerJSONBase: &docker.ContainerJSONBase{
+					Name: "foo",
+				},
+				Config: &container.Config{},
+			},
+			expectedWhitelist: []string{},
+			expectedBlacklist: []string{},
+			expectedDepth:     0,
+		},
+		{
+			container: docker.ContainerJSON{
+				ContainerJSONBase: &docker.ContainerJSONBase{
+					Name: "test",
+				},
+				Config: &container.Config{
+					Labels: map[string]string{
+						"traefik.frontend.ipFilter.whitelist":         "10.0.0.0/8,2001:db8::/32",
+						"traefik.frontend.ipFilter.blacklist":         "10.0.0.1",
+						"traefik.frontend.ipFilter.forwardedForDepth": "1",
+					},
+				},
+			},
+			expectedWhitelist: []string{"10.0.0.0/8", "2001:db8::/32"},
+			expectedBlacklist: []string{"10.0.0.1"},
+			expectedDepth:     1,
+		},
+	}
+
+	for _, e := range containers {
+		dockerData := parseContainer(e.container)
+		whitelist := provider.getIPFilterWhitelist(dockerData)
+		if !reflect.DeepEqual(whitelist, e.expectedWhitelist) {
+			t.Fatalf("expected %q, got %q", e.expectedWhitelist, whitelist)
+		}
+		blacklist := provider.getIPFilterBlacklist(dockerData)
+		if !reflect.DeepEqual(blacklist, e.expectedBlacklist) {
+			t.Fatalf("expected %q, got %q", e.expectedBlacklist, blacklist)
+		}
+		depth := provider.getIPFilterForwardedForDepth(dockerData)
+		if depth != e.expectedDepth {
+			t.Fatalf("expected %d, got %d", e.expectedDepth, depth)
+		}
+		hasIPFilter := provider.hasIPFilterLabels(dockerData)
+		if hasIPFilter != (len(e.expectedWhitelist)+len(e.expectedBlacklist) > 0) {
+			t.Fatalf("unexpected hasIPFilterLabels %t", hasIPFilter)
+		}
+	}
+}
+
 func TestDockerGetLabel(t *testing.T) {
 	containers := []struct {
 		container docker.ContainerJSON
@@ -1127,6 +1233,110 @@ func TestDockerLoadDockerConfig(t *testing.T) {
 				},
 			},
 		},
+		{
+			containers: []docker.ContainerJSON{
+				{
+					ContainerJSONBase: &docker.ContainerJSONBase{
+						Name: "test1",
+					},
+					Config: &container.Config{
+						Labels: map[string]string{
+							"traefik.backend": "sticky",
+							"traefik.backend.loadbalancer.stickycookie.name":   "session",
+							"traefik.backend.loadbalancer.stickycookie.secure": "true",
+						},
+					},
+					NetworkSettings: &docker.NetworkSettings{
+						NetworkSettingsBase: docker.NetworkSettingsBase{
+							Ports: nat.PortMap{
+								"80/tcp": {},
+							},
+						},
+						Networks: map[string]*network.EndpointSettings{
+							"bridge": {
+								IPAddress: "127.0.0.1",
+							},
+						},
+					},
+				},
+				{
+					ContainerJSONBase: &docker.ContainerJSONBase{
+						Name: "test2",
+					},
+					Config: &container.Config{
+						Labels: map[string]string{
+							"traefik.backend":                          "hash",
+							"traefik.backend.loadbalancer.hash.header": "X-User",
+						},
+					},
+					NetworkSettings: &docker.NetworkSettings{
+						NetworkSettingsBase: docker.NetworkSettingsBase{
+							Ports: nat.PortMap{
+								"80/tcp": {},
+							},
+						},
+						Networks: map[string]*network.EndpointSettings{
+							"bridge": {
+								IPAddress: "127.0.0.2",
+							},
+						},
+					},
+				},
+			},
+			expectedFrontends: map[string]*types.Frontend{
+				"frontend-Host-test1-docker-localhost": {
+					Backend:        "backend-sticky",
+					PassHostHeader: true,
+					EntryPoints:    []string{},
+					Routes: map[string]types.Route{
+						"route-frontend-Host-test1-docker-localhost": {
+							Rule: "Host:test1.docker.localhost",
+						},
+					},
+				},
+				"frontend-Host-test2-docker-localhost": {
+					Backend:        "backend-hash",
+					PassHostHeader: true,
+					EntryPoints:    []string{},
+					Routes: map[string]types.Route{
+						"route-frontend-Host-test2-docker-localhost": {
+							Rule: "Host:test2.docker.localhost",
+						},
+					},
+				},
+			},
+			expectedBackends: map[string]*types.Backend{
+				"backend-sticky": {
+					Servers: map[string]types.Server{
+						"server-test1": {
+							URL:    "http://127.0.0.1:80",
+							Weight: 0,
+						},
+					},
+					LoadBalancer: &types.LoadBalancer{
+						Method: "wrr",
+						StickyCookie: &types.StickyCookie{
+							Name:   "session",
+							Secure: true,
+						},
+					},
+				},
+				"backend-hash": {
+					Servers: map[string]types.Server{
+						"server-test2": {
+							URL:    "http://127.0.0.2:80",
+							Weight: 0,
+						},
+					},
+					LoadBalancer: &types.LoadBalancer{
+						Method: "wrr",
+						Hash: &types.HashKey{
+							Header: "X-User",
+						},
+					},
+				},
+			},
+		},
 	}
 
 	provider := &Docker{
@@ -171,8 +171,11 @@ func wrapAws(ctx context.Context, req *request.Request) error {
 
 func (provider *ECS) loadECSConfig(ctx context.Context, client *awsClient) (*types.Configuration, error) {
 	var ecsFuncMap = template.FuncMap{
-		"filterFrontends": provider.filterFrontends,
-		"getFrontendRule": provider.getFrontendRule,
+		"filterFrontends":            provider.filterFrontends,
+		"getFrontendRule":            provider.getFrontendRule,
+		"getHealthCheck":             provider.getHealthCheck,
+		"getFrontendSecurityHeaders": provider.getFrontendSecurityHeaders,
+		"getSplit":                   provider.getSplit,
 	}
 
 	instances, err := provider.listInstances(ctx, client)
@@ -327,6 +330,17 @@ func (provider *ECS) lookupTaskDefinitions(ctx context.Context, client *awsClien
 	return taskDefinitions, nil
 }
 
+// labels returns the docker labels of the container of an instance by key
+func (i ecsInstance) labels() map[string]string {
+	labels := make(map[string]string)
+	for key, value := range i.containerDefinition.DockerLabels {
+		if value != nil {
+			labels[key] = *value
+		}
+	}
+	return labels
+}
+
 func (i ecsInstance) label(k string) string {
 	if v, found := i.containerDefinition.DockerLabels[k]; found {
 		return *v
@@ -370,6 +384,18 @@ func (provider *ECS) getFrontendRule(i ecsInstance) string {
 	return "Host:" + strings.ToLower(strings.Replace(i.Name, "_", "-", -1)) + "." + provider.Domain
 }
 
+func (provider *ECS) getHealthCheck(i ecsInstance) *types.HealthCheck {
+	return getHealthCheck(i.labels(), "traefik.backend.healthcheck")
+}
+
+func (provider *ECS) getFrontendSecurityHeaders(i ecsInstance) *types.SecurityHeaders {
+	return getSecurityHeaders(i.labels(), "traefik.frontend.securityHeaders")
+}
+
+func (provider *ECS) getSplit(i ecsInstance) *types.Split {
+	return getSplit(i.labels(), "traefik.frontend.split")
+}
+
 func (i ecsInstance) Protocol() string {
 	if label := i.label("traefik.protocol"); label != "" {
 		return label
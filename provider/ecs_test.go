@@ -7,6 +7,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/containous/traefik/types"
 )
 
 func makeEcsInstance(containerDef *ecs.ContainerDefinition) ecsInstance {
@@ -221,3 +222,84 @@ func TestEcsEntryPoints(t *testing.T) {
 		}
 	}
 }
+
+func TestEcsHealthCheck(t *testing.T) {
+	cases := []struct {
+		expected     *types.HealthCheck
+		instanceInfo ecsInstance
+	}{
+		{
+			expected:     nil,
+			instanceInfo: simpleEcsInstance(map[string]*string{}),
+		},
+		{
+			expected: &types.HealthCheck{URL: "/health", Rise: 2},
+			instanceInfo: simpleEcsInstance(map[string]*string{
+				"traefik.backend.healthcheck.url":  aws.String("/health"),
+				"traefik.backend.healthcheck.rise": aws.String("2"),
+			}),
+		},
+	}
+
+	provider := &ECS{}
+	for _, c := range cases {
+		value := provider.getHealthCheck(c.instanceInfo)
+		if !reflect.DeepEqual(value, c.expected) {
+			t.Fatalf("Should have been %+v, got %+v", c.expected, value)
+		}
+	}
+}
+
+func TestEcsGetFrontendSecurityHeaders(t *testing.T) {
+	cases := []struct {
+		expected     *types.SecurityHeaders
+		instanceInfo ecsInstance
+	}{
+		{
+			expected:     nil,
+			instanceInfo: simpleEcsInstance(map[string]*string{}),
+		},
+		{
+			expected: &types.SecurityHeaders{FrameDeny: true, STSSeconds: 315360000},
+			instanceInfo: simpleEcsInstance(map[string]*string{
+				"traefik.frontend.securityHeaders.frameDeny":  aws.String("true"),
+				"traefik.frontend.securityHeaders.stsSeconds": aws.String("315360000"),
+			}),
+		},
+	}
+
+	provider := &ECS{}
+	for _, c := range cases {
+		value := provider.getFrontendSecurityHeaders(c.instanceInfo)
+		if !reflect.DeepEqual(value, c.expected) {
+			t.Fatalf("Should have been %+v, got %+v", c.expected, value)
+		}
+	}
+}
+
+func TestEcsGetSplit(t *testing.T) {
+	cases := []struct {
+		expected     *types.Split
+		instanceInfo ecsInstance
+	}{
+		{
+			expected:     nil,
+			instanceInfo: simpleEcsInstance(map[string]*string{}),
+		},
+		{
+			expected: &types.Split{Backends: map[string]int{"stable": 90, "canary": 10}, Header: "X-User-Id"},
+			instanceInfo: simpleEcsInstance(map[string]*string{
+				"traefik.frontend.split.backends": aws.String("stable:90,canary:10"),
+				"traefik.frontend.split.header":   aws.String("X-User-Id"),
+			}),
+		},
+	}
+
+	provider := &ECS{}
+	for _, c := range cases {
+		value := provider.getSplit(c.instanceInfo)
+		if !reflect.DeepEqual(value, c.expected) {
+			t.Fatalf("Should have been %+v, got %+v", c.expected, value)
+		}
+	}
+}
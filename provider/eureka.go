@@ -85,10 +85,13 @@ func (provider *Eureka) Provide(configurationChan chan<- types.ConfigMessage, po
 // Build the configuration from Eureka server
 func (provider *Eureka) buildConfiguration() (*types.Configuration, error) {
 	var EurekaFuncMap = template.FuncMap{
-		"getPort":       provider.getPort,
-		"getProtocol":   provider.getProtocol,
-		"getWeight":     provider.getWeight,
-		"getInstanceID": provider.getInstanceID,
+		"getPort":                    provider.getPort,
+		"getProtocol":                provider.getProtocol,
+		"getWeight":                  provider.getWeight,
+		"getInstanceID":              provider.getInstanceID,
+		"getHealthCheck":             provider.getHealthCheck,
+		"getFrontendSecurityHeaders": provider.getFrontendSecurityHeaders,
+		"getSplit":                   provider.getSplit,
 	}
 
 	eureka.GetLogger().SetOutput(ioutil.Discard)
@@ -136,6 +139,29 @@ func (provider *Eureka) getWeight(instance eureka.InstanceInfo) string {
 	return "0"
 }
 
+// applicationMetadata returns the metadata of the first instance of an application, which holds its labels
+func applicationMetadata(application eureka.Application) map[string]string {
+	if len(application.Instances) == 0 || application.Instances[0].Metadata == nil {
+		return nil
+	}
+	return application.Instances[0].Metadata.Map
+}
+
+// getHealthCheck returns the health check of an application, set in the metadata of its first instance
+func (provider *Eureka) getHealthCheck(application eureka.Application) *types.HealthCheck {
+	return getHealthCheck(applicationMetadata(application), "traefik.backend.healthcheck")
+}
+
+// getFrontendSecurityHeaders returns the security headers of an application, set in the metadata of its first instance
+func (provider *Eureka) getFrontendSecurityHeaders(application eureka.Application) *types.SecurityHeaders {
+	return getSecurityHeaders(applicationMetadata(application), "traefik.frontend.securityHeaders")
+}
+
+// getSplit returns the split of the requests of an application between backends, set in the metadata of its first instance
+func (provider *Eureka) getSplit(application eureka.Application) *types.Split {
+	return getSplit(applicationMetadata(application), "traefik.frontend.split")
+}
+
 func (provider *Eureka) getInstanceID(instance eureka.InstanceInfo) string {
 	if val, ok := instance.Metadata.Map["traefik.backend.id"]; ok {
 		return val
@@ -1,9 +1,11 @@
 package provider
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/ArthurHlt/go-eureka-client/eureka"
+	"github.com/containous/traefik/types"
 )
 
 func TestEurekaGetPort(t *testing.T) {
@@ -169,3 +171,110 @@ func TestEurekaGetInstanceId(t *testing.T) {
 		}
 	}
 }
+
+func TestEurekaGetHealthCheck(t *testing.T) {
+	cases := []struct {
+		expected    *types.HealthCheck
+		application eureka.Application
+	}{
+		{
+			expected:    nil,
+			application: eureka.Application{Name: "app"},
+		},
+		{
+			expected: &types.HealthCheck{URL: "/health"},
+			application: eureka.Application{
+				Name: "app",
+				Instances: []eureka.InstanceInfo{
+					{
+						Metadata: &eureka.MetaData{
+							Map: map[string]string{
+								"traefik.backend.healthcheck.url": "/health",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	eurekaProvider := &Eureka{}
+	for _, c := range cases {
+		healthCheck := eurekaProvider.getHealthCheck(c.application)
+		if !reflect.DeepEqual(healthCheck, c.expected) {
+			t.Fatalf("Should have been %+v, got %+v", c.expected, healthCheck)
+		}
+	}
+}
+
+func TestEurekaGetFrontendSecurityHeaders(t *testing.T) {
+	cases := []struct {
+		expected    *types.SecurityHeaders
+		application eureka.Application
+	}{
+		{
+			expected:    nil,
+			application: eureka.Application{Name: "app"},
+		},
+		{
+			expected: &types.SecurityHeaders{FrameDeny: true, STSSeconds: 315360000},
+			application: eureka.Application{
+				Name: "app",
+				Instances: []eureka.InstanceInfo{
+					{
+						Metadata: &eureka.MetaData{
+							Map: map[string]string{
+								"traefik.frontend.securityHeaders.frameDeny":  "true",
+								"traefik.frontend.securityHeaders.stsSeconds": "315360000",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	eurekaProvider := &Eureka{}
+	for _, c := range cases {
+		securityHeaders := eurekaProvider.getFrontendSecurityHeaders(c.application)
+		if !reflect.DeepEqual(securityHeaders, c.expected) {
+			t.Fatalf("Should have been %+v, got %+v", c.expected, securityHeaders)
+		}
+	}
+}
+
+func TestEurekaGetSplit(t *testing.T) {
+	cases := []struct {
+		expected    *types.Split
+		application eureka.Application
+	}{
+		{
+			expected:    nil,
+			application: eureka.Application{Name: "app"},
+		},
+		{
+			expected: &types.Split{Backends: map[string]int{"STABLE": 90, "CANARY": 10}, Cookie: "_canary"},
+			application: eureka.Application{
+				Name: "app",
+				Instances: []eureka.InstanceInfo{
+					{
+						Metadata: &eureka.MetaData{
+							Map: map[string]string{
+								"traefik.frontend.split.backends": "STABLE:90,CANARY:10",
+								"traefik.frontend.split.cookie":   "_canary",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	eurekaProvider := &Eureka{}
+	for _, c := range cases {
+		split := eurekaProvider.getSplit(c.application)
+		if !reflect.DeepEqual(split, c.expected) {
+			t.Fatalf("Should have been %+v, got %+v", c.expected, split)
+		}
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/containous/traefik/safe"
 	"github.com/containous/traefik/types"
 	"k8s.io/client-go/1.5/pkg/api/v1"
+	"k8s.io/client-go/1.5/pkg/apis/extensions/v1beta1"
 	"k8s.io/client-go/1.5/pkg/util/intstr"
 )
 
@@ -116,20 +117,18 @@ func (provider *Kubernetes) loadIngresses(k8sClient k8s.Client) (*types.Configur
 			}
 			for _, pa := range r.HTTP.Paths {
 				if _, exists := templateObjects.Backends[r.Host+pa.Path]; !exists {
-					templateObjects.Backends[r.Host+pa.Path] = &types.Backend{
-						Servers: make(map[string]types.Server),
-						LoadBalancer: &types.LoadBalancer{
-							Sticky: false,
-							Method: "wrr",
-						},
-					}
+					templateObjects.Backends[r.Host+pa.Path] = newIngressBackend()
 				}
 				if _, exists := templateObjects.Frontends[r.Host+pa.Path]; !exists {
 					templateObjects.Frontends[r.Host+pa.Path] = &types.Frontend{
-						Backend:        r.Host + pa.Path,
-						PassHostHeader: PassHostHeader,
-						Routes:         make(map[string]types.Route),
-						Priority:       len(pa.Path),
+						Backend:         r.Host + pa.Path,
+						PassHostHeader:  PassHostHeader,
+						Routes:          make(map[string]types.Route),
+						Priority:        len(pa.Path),
+						Headers:         getIngressHeaders(i),
+						SecurityHeaders: getSecurityHeaders(i.Annotations, "traefik.frontend.securityHeaders"),
+						CORS:            getCORS(i.Annotations, "traefik.frontend.cors"),
+						Auth:            getIngressAuth(i),
 					}
 				}
 				if len(r.Host) > 0 {
@@ -175,57 +174,142 @@ func (provider *Kubernetes) loadIngresses(k8sClient k8s.Client) (*types.Configur
 					continue
 				}
 
-				if expression := service.Annotations["traefik.backend.circuitbreaker"]; expression != "" {
-					templateObjects.Backends[r.Host+pa.Path].CircuitBreaker = &types.CircuitBreaker{
-						Expression: expression,
-					}
-				}
-				if service.Annotations["traefik.backend.loadbalancer.method"] == "drr" {
-					templateObjects.Backends[r.Host+pa.Path].LoadBalancer.Method = "drr"
-				}
-				if service.Annotations["traefik.backend.loadbalancer.sticky"] == "true" {
-					templateObjects.Backends[r.Host+pa.Path].LoadBalancer.Sticky = true
+				loadServiceBackend(k8sClient, service, pa.Backend.ServicePort, templateObjects.Backends[r.Host+pa.Path])
+				if split := getSplit(i.Annotations, "traefik.frontend.split"); split != nil {
+					templateObjects.Frontends[r.Host+pa.Path].Split = loadSplit(k8sClient, i, r.Host+pa.Path, pa, split, &templateObjects)
 				}
+			}
+		}
+	}
+	return &templateObjects, nil
+}
 
-				protocol := "http"
-				for _, port := range service.Spec.Ports {
-					if equalPorts(port, pa.Backend.ServicePort) {
-						if port.Port == 443 {
-							protocol = "https"
-						}
-						endpoints, exists, err := k8sClient.GetEndpoints(service.ObjectMeta.Namespace, service.ObjectMeta.Name)
-						if err != nil || !exists {
-							log.Errorf("Error retrieving endpoints %s/%s: %v", service.ObjectMeta.Namespace, service.ObjectMeta.Name, err)
-							continue
+// getIngressHeaders returns the header manipulations set by the traefik.frontend.headers.* annotations of an ingress
+func getIngressHeaders(ingress *v1beta1.Ingress) *types.Headers {
+	requestHeaders := getHeaderOperations(ingress.Annotations, "traefik.frontend.headers.request")
+	responseHeaders := getHeaderOperations(ingress.Annotations, "traefik.frontend.headers.response")
+	if requestHeaders == nil && responseHeaders == nil {
+		return nil
+	}
+	return &types.Headers{
+		Request:  requestHeaders,
+		Response: responseHeaders,
+	}
+}
+
+// getIngressAuth returns the authentication set by the traefik.frontend.auth.basic.*, traefik.frontend.auth.digest.*,
+// traefik.frontend.auth.forward.* or traefik.frontend.auth.jwt.* annotations of an ingress
+func getIngressAuth(ingress *v1beta1.Ingress) *types.Auth {
+	if basic := getBasicAuth(ingress.Annotations, "traefik.frontend.auth.basic"); basic != nil {
+		return &types.Auth{Basic: basic}
+	}
+	if digest := getDigestAuth(ingress.Annotations, "traefik.frontend.auth.digest"); digest != nil {
+		return &types.Auth{Digest: digest}
+	}
+	if forward := getForwardAuth(ingress.Annotations, "traefik.frontend.auth.forward"); forward != nil {
+		return &types.Auth{Forward: forward}
+	}
+	if jwt := getJWTAuth(ingress.Annotations, "traefik.frontend.auth.jwt"); jwt != nil {
+		return &types.Auth{JWT: jwt}
+	}
+	return nil
+}
+
+// loadSplit returns the split of the traefik.frontend.split.* annotations of an ingress, its backends being
+// the services named in the annotation, served on the port of the path
+func loadSplit(k8sClient k8s.Client, ingress *v1beta1.Ingress, backendName string, path v1beta1.HTTPIngressPath, split *types.Split, templateObjects *types.Configuration) *types.Split {
+	backends := map[string]int{}
+	for serviceName, weight := range split.Backends {
+		if serviceName == path.Backend.ServiceName {
+			backends[backendName] = weight
+			continue
+		}
+		service, exists, err := k8sClient.GetService(ingress.ObjectMeta.Namespace, serviceName)
+		if err != nil || !exists {
+			log.Warnf("Error retrieving split service %s/%s: %v", ingress.ObjectMeta.Namespace, serviceName, err)
+			continue
+		}
+		// the service may be split from several paths, each one having its own backend
+		splitBackendName := backendName + "#" + serviceName
+		templateObjects.Backends[splitBackendName] = newIngressBackend()
+		loadServiceBackend(k8sClient, service, path.Backend.ServicePort, templateObjects.Backends[splitBackendName])
+		backends[splitBackendName] = weight
+	}
+	split.Backends = backends
+	return split
+}
+
+func newIngressBackend() *types.Backend {
+	return &types.Backend{
+		Servers: make(map[string]types.Server),
+		LoadBalancer: &types.LoadBalancer{
+			Sticky: false,
+			Method: "wrr",
+		},
+	}
+}
+
+// loadServiceBackend sets the options of the traefik.backend.* annotations of a service and its servers on a backend
+func loadServiceBackend(k8sClient k8s.Client, service *v1.Service, servicePort intstr.IntOrString, backend *types.Backend) {
+	if expression := service.Annotations["traefik.backend.circuitbreaker"]; expression != "" {
+		backend.CircuitBreaker = &types.CircuitBreaker{
+			Expression: expression,
+		}
+	}
+	backend.Buffering = getBuffering(service.Annotations, "traefik.backend.buffering")
+	backend.HealthCheck = getHealthCheck(service.Annotations, "traefik.backend.healthcheck")
+	backend.OutlierDetection = getOutlierDetection(service.Annotations, "traefik.backend.outlierdetection")
+	backend.Transport = getTransport(service.Annotations, "traefik.backend.transport")
+	switch method := service.Annotations["traefik.backend.loadbalancer.method"]; method {
+	case "drr", "leastconn", "p2c", "hash":
+		backend.LoadBalancer.Method = method
+	}
+	backend.LoadBalancer.Hash = getHashKey(service.Annotations, "traefik.backend.loadbalancer.hash")
+	if service.Annotations["traefik.backend.loadbalancer.sticky"] == "true" {
+		backend.LoadBalancer.Sticky = true
+	}
+	backend.LoadBalancer.StickyCookie = getStickyCookie(service.Annotations, "traefik.backend.loadbalancer.stickycookie")
+	if slowStart, ok := service.Annotations["traefik.backend.loadbalancer.slowstart"]; ok {
+		if err := backend.LoadBalancer.SlowStart.Set(slowStart); err != nil {
+			log.Errorf("Unable to parse traefik.backend.loadbalancer.slowstart %s: %v", slowStart, err)
+		}
+	}
+
+	protocol := "http"
+	for _, port := range service.Spec.Ports {
+		if equalPorts(port, servicePort) {
+			if port.Port == 443 {
+				protocol = "https"
+			}
+			endpoints, exists, err := k8sClient.GetEndpoints(service.ObjectMeta.Namespace, service.ObjectMeta.Name)
+			if err != nil || !exists {
+				log.Errorf("Error retrieving endpoints %s/%s: %v", service.ObjectMeta.Namespace, service.ObjectMeta.Name, err)
+				continue
+			}
+			if len(endpoints.Subsets) == 0 {
+				log.Warnf("Endpoints not found for %s/%s, falling back to Service ClusterIP", service.ObjectMeta.Namespace, service.ObjectMeta.Name)
+				backend.Servers[string(service.UID)] = types.Server{
+					URL:    protocol + "://" + service.Spec.ClusterIP + ":" + strconv.Itoa(int(port.Port)),
+					Weight: 1,
+				}
+			} else {
+				for _, subset := range endpoints.Subsets {
+					for _, address := range subset.Addresses {
+						url := protocol + "://" + address.IP + ":" + strconv.Itoa(endpointPortNumber(port, subset.Ports))
+						name := url
+						if address.TargetRef != nil && address.TargetRef.Name != "" {
+							name = address.TargetRef.Name
 						}
-						if len(endpoints.Subsets) == 0 {
-							log.Warnf("Endpoints not found for %s/%s, falling back to Service ClusterIP", service.ObjectMeta.Namespace, service.ObjectMeta.Name)
-							templateObjects.Backends[r.Host+pa.Path].Servers[string(service.UID)] = types.Server{
-								URL:    protocol + "://" + service.Spec.ClusterIP + ":" + strconv.Itoa(int(port.Port)),
-								Weight: 1,
-							}
-						} else {
-							for _, subset := range endpoints.Subsets {
-								for _, address := range subset.Addresses {
-									url := protocol + "://" + address.IP + ":" + strconv.Itoa(endpointPortNumber(port, subset.Ports))
-									name := url
-									if address.TargetRef != nil && address.TargetRef.Name != "" {
-										name = address.TargetRef.Name
-									}
-									templateObjects.Backends[r.Host+pa.Path].Servers[name] = types.Server{
-										URL:    url,
-										Weight: 1,
-									}
-								}
-							}
+						backend.Servers[name] = types.Server{
+							URL:    url,
+							Weight: 1,
 						}
-						break
 					}
 				}
 			}
+			break
 		}
 	}
-	return &templateObjects, nil
 }
 
 func endpointPortNumber(servicePort v1.ServicePort, endpointPorts []v1.EndpointPort) int {
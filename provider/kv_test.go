@@ -447,3 +447,49 @@ func TestKVLoadConfig(t *testing.T) {
 		t.Fatalf("expected %+v, got %+v", expected.Frontends, actual.Frontends)
 	}
 }
+
+func TestKVLoadConfigSplit(t *testing.T) {
+	provider := &Kv{
+		Prefix: "traefik",
+		kvclient: &Mock{
+			KVPairs: []*store.KVPair{
+				{
+					Key:   "traefik/frontends/frontend1",
+					Value: []byte(""),
+				},
+				{
+					Key:   "traefik/frontends/frontend1/backend",
+					Value: []byte("stable"),
+				},
+				{
+					Key:   "traefik/frontends/frontend1/split/backends/stable",
+					Value: []byte("90"),
+				},
+				{
+					Key:   "traefik/frontends/frontend1/split/backends/canary",
+					Value: []byte("10"),
+				},
+				{
+					Key:   "traefik/frontends/frontend1/split/cookie",
+					Value: []byte("_canary"),
+				},
+				{
+					Key:   "traefik/backends/stable",
+					Value: []byte(""),
+				},
+				{
+					Key:   "traefik/backends/stable/servers/server1/url",
+					Value: []byte("http://172.17.0.2:80"),
+				},
+			},
+		},
+	}
+	actual := provider.loadConfig()
+	expected := &types.Split{
+		Backends: map[string]int{"stable": 90, "canary": 10},
+		Cookie:   "_canary",
+	}
+	if !reflect.DeepEqual(actual.Frontends["frontend1"].Split, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, actual.Frontends["frontend1"].Split)
+	}
+}
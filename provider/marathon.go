@@ -148,6 +148,14 @@ func (provider *Marathon) loadMarathonConfig() *types.Configuration {
 		"getLoadBalancerMethod":       provider.getLoadBalancerMethod,
 		"getCircuitBreakerExpression": provider.getCircuitBreakerExpression,
 		"getSticky":                   provider.getSticky,
+		"getSlowStart":                provider.getSlowStart,
+		"getSplit":                    provider.getSplit,
+		"getFrontendSecurityHeaders":  provider.getFrontendSecurityHeaders,
+		"getHealthCheck":              provider.getHealthCheck,
+		"getOutlierDetection":         provider.getOutlierDetection,
+		"getTransport":                provider.getTransport,
+		"getHashKey":                  provider.getHashKey,
+		"getStickyCookie":             provider.getStickyCookie,
 	}
 
 	applications, err := provider.marathonClient.Applications(nil)
@@ -374,6 +382,13 @@ func (provider *Marathon) getSticky(application marathon.Application) string {
 	return "false"
 }
 
+func (provider *Marathon) getSlowStart(application marathon.Application) string {
+	if slowStart, err := provider.getLabel(application, "traefik.backend.loadbalancer.slowstart"); err == nil {
+		return slowStart
+	}
+	return "0s"
+}
+
 func (provider *Marathon) getPassHostHeader(application marathon.Application) string {
 	if passHostHeader, err := provider.getLabel(application, "traefik.frontend.passHostHeader"); err == nil {
 		return passHostHeader
@@ -395,6 +410,34 @@ func (provider *Marathon) getEntryPoints(application marathon.Application) []str
 	return []string{}
 }
 
+func (provider *Marathon) getHealthCheck(application marathon.Application) *types.HealthCheck {
+	return getHealthCheck(*application.Labels, "traefik.backend.healthcheck")
+}
+
+func (provider *Marathon) getOutlierDetection(application marathon.Application) *types.OutlierDetection {
+	return getOutlierDetection(*application.Labels, "traefik.backend.outlierdetection")
+}
+
+func (provider *Marathon) getTransport(application marathon.Application) *types.Transport {
+	return getTransport(*application.Labels, "traefik.backend.transport")
+}
+
+func (provider *Marathon) getHashKey(application marathon.Application) *types.HashKey {
+	return getHashKey(*application.Labels, "traefik.backend.loadbalancer.hash")
+}
+
+func (provider *Marathon) getStickyCookie(application marathon.Application) *types.StickyCookie {
+	return getStickyCookie(*application.Labels, "traefik.backend.loadbalancer.stickycookie")
+}
+
+func (provider *Marathon) getFrontendSecurityHeaders(application marathon.Application) *types.SecurityHeaders {
+	return getSecurityHeaders(*application.Labels, "traefik.frontend.securityHeaders")
+}
+
+func (provider *Marathon) getSplit(application marathon.Application) *types.Split {
+	return getSplit(*application.Labels, "traefik.frontend.split")
+}
+
 // getFrontendRule returns the frontend rule for the specified application, using
 // it's label. It returns a default one (Host) if the label is not present.
 func (provider *Marathon) getFrontendRule(application marathon.Application) string {
@@ -445,7 +488,8 @@ func (provider *Marathon) hasCircuitBreakerLabels(application marathon.Applicati
 func (provider *Marathon) hasLoadBalancerLabels(application marathon.Application) bool {
 	_, errMethod := provider.getLabel(application, "traefik.backend.loadbalancer.method")
 	_, errSticky := provider.getLabel(application, "traefik.backend.loadbalancer.sticky")
-	if errMethod != nil && errSticky != nil {
+	_, errSlowStart := provider.getLabel(application, "traefik.backend.loadbalancer.slowstart")
+	if errMethod != nil && errSticky != nil && errSlowStart != nil && provider.getStickyCookie(application) == nil && provider.getHashKey(application) == nil {
 		return false
 	}
 	return true
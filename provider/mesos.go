@@ -122,19 +122,22 @@ func (provider *Mesos) Provide(configurationChan chan<- types.ConfigMessage, poo
 
 func (provider *Mesos) loadMesosConfig() *types.Configuration {
 	var mesosFuncMap = template.FuncMap{
-		"getBackend":         provider.getBackend,
-		"getPort":            provider.getPort,
-		"getHost":            provider.getHost,
-		"getWeight":          provider.getWeight,
-		"getDomain":          provider.getDomain,
-		"getProtocol":        provider.getProtocol,
-		"getPassHostHeader":  provider.getPassHostHeader,
-		"getPriority":        provider.getPriority,
-		"getEntryPoints":     provider.getEntryPoints,
-		"getFrontendRule":    provider.getFrontendRule,
-		"getFrontendBackend": provider.getFrontendBackend,
-		"getID":              provider.getID,
-		"getFrontEndName":    provider.getFrontEndName,
+		"getBackend":                 provider.getBackend,
+		"getPort":                    provider.getPort,
+		"getHost":                    provider.getHost,
+		"getWeight":                  provider.getWeight,
+		"getDomain":                  provider.getDomain,
+		"getProtocol":                provider.getProtocol,
+		"getPassHostHeader":          provider.getPassHostHeader,
+		"getPriority":                provider.getPriority,
+		"getEntryPoints":             provider.getEntryPoints,
+		"getFrontendRule":            provider.getFrontendRule,
+		"getFrontendBackend":         provider.getFrontendBackend,
+		"getID":                      provider.getID,
+		"getFrontEndName":            provider.getFrontEndName,
+		"getHealthCheck":             provider.getHealthCheck,
+		"getFrontendSecurityHeaders": provider.getFrontendSecurityHeaders,
+		"getSplit":                   provider.getSplit,
 	}
 
 	t := records.NewRecordGenerator(time.Duration(provider.StateTimeoutSecond) * time.Second)
@@ -370,6 +373,27 @@ func (provider *Mesos) getFrontendBackend(task state.Task) string {
 	return "-" + cleanupSpecialChars(task.DiscoveryInfo.Name)
 }
 
+// taskLabels returns the labels of a task by key
+func taskLabels(task state.Task) map[string]string {
+	labels := make(map[string]string)
+	for _, label := range task.Labels {
+		labels[label.Key] = label.Value
+	}
+	return labels
+}
+
+func (provider *Mesos) getHealthCheck(task state.Task) *types.HealthCheck {
+	return getHealthCheck(taskLabels(task), "traefik.backend.healthcheck")
+}
+
+func (provider *Mesos) getFrontendSecurityHeaders(task state.Task) *types.SecurityHeaders {
+	return getSecurityHeaders(taskLabels(task), "traefik.frontend.securityHeaders")
+}
+
+func (provider *Mesos) getSplit(task state.Task) *types.Split {
+	return getSplit(taskLabels(task), "traefik.frontend.split")
+}
+
 func (provider *Mesos) getHost(task state.Task) string {
 	return task.IP(strings.Split(provider.IPSources, ",")...)
 }
@@ -245,6 +245,42 @@ func TestMesosLoadConfig(t *testing.T) {
 	}
 }
 
+func TestMesosGetHealthCheck(t *testing.T) {
+	provider := &Mesos{}
+	if healthCheck := provider.getHealthCheck(task(setLabels("traefik.backend", "foo"))); healthCheck != nil {
+		t.Fatalf("Should have been nil, got %+v", healthCheck)
+	}
+	expected := &types.HealthCheck{URL: "/health", Fall: 3}
+	healthCheck := provider.getHealthCheck(task(setLabels("traefik.backend.healthcheck.url", "/health", "traefik.backend.healthcheck.fall", "3")))
+	if !reflect.DeepEqual(healthCheck, expected) {
+		t.Fatalf("Should have been %+v, got %+v", expected, healthCheck)
+	}
+}
+
+func TestMesosGetFrontendSecurityHeaders(t *testing.T) {
+	provider := &Mesos{}
+	if securityHeaders := provider.getFrontendSecurityHeaders(task(setLabels("traefik.backend", "foo"))); securityHeaders != nil {
+		t.Fatalf("Should have been nil, got %+v", securityHeaders)
+	}
+	expected := &types.SecurityHeaders{FrameDeny: true, STSSeconds: 315360000}
+	securityHeaders := provider.getFrontendSecurityHeaders(task(setLabels("traefik.frontend.securityHeaders.frameDeny", "true", "traefik.frontend.securityHeaders.stsSeconds", "315360000")))
+	if !reflect.DeepEqual(securityHeaders, expected) {
+		t.Fatalf("Should have been %+v, got %+v", expected, securityHeaders)
+	}
+}
+
+func TestMesosGetSplit(t *testing.T) {
+	provider := &Mesos{}
+	if split := provider.getSplit(task(setLabels("traefik.backend", "foo"))); split != nil {
+		t.Fatalf("Should have been nil, got %+v", split)
+	}
+	expected := &types.Split{Backends: map[string]int{"stable": 90, "canary": 10}, Cookie: "_canary"}
+	split := provider.getSplit(task(setLabels("traefik.frontend.split.backends", "stable:90,canary:10", "traefik.frontend.split.cookie", "_canary")))
+	if !reflect.DeepEqual(split, expected) {
+		t.Fatalf("Should have been %+v, got %+v", expected, split)
+	}
+}
+
 func TestMesosGetSubDomain(t *testing.T) {
 	providerGroups := &Mesos{GroupsAsSubDomains: true}
 	providerNoGroups := &Mesos{GroupsAsSubDomains: false}
@@ -3,10 +3,9 @@ package provider
 import (
 	"bytes"
 	"crypto/tls"
-	"crypto/x509"
 	"fmt"
 	"io/ioutil"
-	"os"
+	"strconv"
 	"strings"
 	"text/template"
 	"unicode"
@@ -120,70 +119,600 @@ func normalize(name string) string {
 	return strings.Join(strings.FieldsFunc(name, fargs), "-")
 }
 
-func reverseStringSlice(slice *[]string) {
-	for i, j := 0, len(*slice)-1; i < j; i, j = i+1, j-1 {
-		(*slice)[i], (*slice)[j] = (*slice)[j], (*slice)[i]
+// getHeaderOperations parses the <prefix>.remove=Name1,Name2, <prefix>.set.<Name>=value
+// and <prefix>.add.<Name>=value labels, or returns nil if there are none.
+func getHeaderOperations(labels map[string]string, prefix string) *types.HeaderOperations {
+	var operations *types.HeaderOperations
+	for label, value := range labels {
+		if !strings.HasPrefix(label, prefix+".") {
+			continue
+		}
+		if operations == nil {
+			operations = &types.HeaderOperations{Set: map[string]string{}, Add: map[string]string{}}
+		}
+		operation := strings.TrimPrefix(label, prefix+".")
+		switch {
+		case operation == "remove":
+			operations.Remove = strings.Split(value, ",")
+		case strings.HasPrefix(operation, "set."):
+			operations.Set[strings.TrimPrefix(operation, "set.")] = value
+		case strings.HasPrefix(operation, "add."):
+			operations.Add[strings.TrimPrefix(operation, "add.")] = value
+		default:
+			log.Warnf("Unknown header operation %s", label)
+		}
 	}
+	return operations
 }
 
-// ClientTLS holds TLS specific configurations as client
-// CA, Cert and Key can be either path or file contents
-type ClientTLS struct {
-	CA                 string `description:"TLS CA"`
-	Cert               string `description:"TLS cert"`
-	Key                string `description:"TLS key"`
-	InsecureSkipVerify bool   `description:"TLS insecure skip verify"`
+// getSecurityHeaders parses the <prefix>.<option>=value labels, e.g. <prefix>.stsSeconds=31536000,
+// or returns nil if there are none. sslProxyHeaders is a comma separated list of Name:value pairs.
+func getSecurityHeaders(labels map[string]string, prefix string) *types.SecurityHeaders {
+	var securityHeaders *types.SecurityHeaders
+	for label, value := range labels {
+		if !strings.HasPrefix(label, prefix+".") {
+			continue
+		}
+		if securityHeaders == nil {
+			securityHeaders = &types.SecurityHeaders{}
+		}
+		var err error
+		switch option := strings.TrimPrefix(label, prefix+"."); option {
+		case "sslRedirect":
+			securityHeaders.SSLRedirect, err = strconv.ParseBool(value)
+		case "sslTemporaryRedirect":
+			securityHeaders.SSLTemporaryRedirect, err = strconv.ParseBool(value)
+		case "sslHost":
+			securityHeaders.SSLHost = value
+		case "sslProxyHeaders":
+			securityHeaders.SSLProxyHeaders, err = parseKeyValues(value)
+		case "stsSeconds":
+			securityHeaders.STSSeconds, err = strconv.ParseInt(value, 10, 64)
+		case "stsIncludeSubdomains":
+			securityHeaders.STSIncludeSubdomains, err = strconv.ParseBool(value)
+		case "stsPreload":
+			securityHeaders.STSPreload, err = strconv.ParseBool(value)
+		case "forceSTSHeader":
+			securityHeaders.ForceSTSHeader, err = strconv.ParseBool(value)
+		case "frameDeny":
+			securityHeaders.FrameDeny, err = strconv.ParseBool(value)
+		case "customFrameOptionsValue":
+			securityHeaders.CustomFrameOptionsValue = value
+		case "contentTypeNosniff":
+			securityHeaders.ContentTypeNosniff, err = strconv.ParseBool(value)
+		case "contentSecurityPolicy":
+			securityHeaders.ContentSecurityPolicy = value
+		case "referrerPolicy":
+			securityHeaders.ReferrerPolicy = value
+		case "isDevelopment":
+			securityHeaders.IsDevelopment, err = strconv.ParseBool(value)
+		default:
+			log.Warnf("Unknown security header option %s", label)
+		}
+		if err != nil {
+			log.Errorf("Unable to parse %s %s: %v", label, value, err)
+		}
+	}
+	return securityHeaders
 }
 
-// CreateTLSConfig creates a TLS config from ClientTLS structures
-func (clientTLS *ClientTLS) CreateTLSConfig() (*tls.Config, error) {
-	var err error
-	if clientTLS == nil {
-		log.Warnf("clientTLS is nil")
-		return nil, nil
-	}
-	caPool := x509.NewCertPool()
-	if clientTLS.CA != "" {
-		var ca []byte
-		if _, errCA := os.Stat(clientTLS.CA); errCA == nil {
-			ca, err = ioutil.ReadFile(clientTLS.CA)
-			if err != nil {
-				return nil, fmt.Errorf("Failed to read CA. %s", err)
-			}
+// getCORS parses the <prefix>.<option>=value labels, e.g. <prefix>.allowedOrigins=https://*.example.com,
+// or returns nil if there are none. Lists are comma separated.
+func getCORS(labels map[string]string, prefix string) *types.CORS {
+	var cors *types.CORS
+	for label, value := range labels {
+		if !strings.HasPrefix(label, prefix+".") {
+			continue
+		}
+		if cors == nil {
+			cors = &types.CORS{}
+		}
+		var err error
+		switch option := strings.TrimPrefix(label, prefix+"."); option {
+		case "allowedOrigins":
+			cors.AllowedOrigins = strings.Split(value, ",")
+		case "allowedMethods":
+			cors.AllowedMethods = strings.Split(value, ",")
+		case "allowedHeaders":
+			cors.AllowedHeaders = strings.Split(value, ",")
+		case "exposedHeaders":
+			cors.ExposedHeaders = strings.Split(value, ",")
+		case "allowCredentials":
+			cors.AllowCredentials, err = strconv.ParseBool(value)
+		case "maxAge":
+			cors.MaxAge, err = strconv.Atoi(value)
+		default:
+			log.Warnf("Unknown CORS option %s", label)
+		}
+		if err != nil {
+			log.Errorf("Unable to parse %s %s: %v", label, value, err)
+		}
+	}
+	return cors
+}
+
+// getForwardAuth parses the <prefix>.address, <prefix>.authRequestHeaders, <prefix>.authResponseHeaders
+// and <prefix>.timeout labels, or returns nil if there is no address.
+func getForwardAuth(labels map[string]string, prefix string) *types.Forward {
+	address, ok := labels[prefix+".address"]
+	if !ok {
+		return nil
+	}
+	forward := &types.Forward{Address: address}
+	if value, ok := labels[prefix+".authRequestHeaders"]; ok {
+		forward.AuthRequestHeaders = strings.Split(value, ",")
+	}
+	if value, ok := labels[prefix+".authResponseHeaders"]; ok {
+		forward.AuthResponseHeaders = strings.Split(value, ",")
+	}
+	if value, ok := labels[prefix+".timeout"]; ok {
+		if err := forward.Timeout.UnmarshalText([]byte(value)); err != nil {
+			log.Errorf("Unable to parse %s.timeout %s", prefix, value)
+		}
+	}
+	return forward
+}
+
+// getBuffering parses the <prefix>.<option>=value labels, e.g. <prefix>.maxRequestBodyBytes=10485760,
+// or returns nil if there are none.
+func getBuffering(labels map[string]string, prefix string) *types.Buffering {
+	var buffering *types.Buffering
+	for label, value := range labels {
+		if !strings.HasPrefix(label, prefix+".") {
+			continue
+		}
+		if buffering == nil {
+			buffering = &types.Buffering{}
+		}
+		var err error
+		switch option := strings.TrimPrefix(label, prefix+"."); option {
+		case "maxRequestBodyBytes":
+			buffering.MaxRequestBodyBytes, err = strconv.ParseInt(value, 10, 64)
+		case "memRequestBodyBytes":
+			buffering.MemRequestBodyBytes, err = strconv.ParseInt(value, 10, 64)
+		case "maxResponseBodyBytes":
+			buffering.MaxResponseBodyBytes, err = strconv.ParseInt(value, 10, 64)
+		case "memResponseBodyBytes":
+			buffering.MemResponseBodyBytes, err = strconv.ParseInt(value, 10, 64)
+		case "bufferResponses":
+			buffering.BufferResponses, err = strconv.ParseBool(value)
+		default:
+			log.Warnf("Unknown buffering option %s", label)
+		}
+		if err != nil {
+			log.Errorf("Unable to parse %s %s: %v", label, value, err)
+		}
+	}
+	return buffering
+}
+
+// getMirror parses the <prefix>.backend, <prefix>.percent and <prefix>.maxBodyBytes labels,
+// or returns nil if there is no backend.
+func getMirror(labels map[string]string, prefix string) *types.Mirror {
+	backend, ok := labels[prefix+".backend"]
+	if !ok {
+		return nil
+	}
+	mirror := &types.Mirror{Backend: backend}
+	if value, ok := labels[prefix+".percent"]; ok {
+		percent, err := strconv.Atoi(value)
+		if err != nil {
+			log.Errorf("Unable to parse %s.percent %s: %v", prefix, value, err)
 		} else {
-			ca = []byte(clientTLS.CA)
+			mirror.Percent = &percent
+		}
+	}
+	if value, ok := labels[prefix+".maxBodyBytes"]; ok {
+		maxBodyBytes, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			log.Errorf("Unable to parse %s.maxBodyBytes %s: %v", prefix, value, err)
 		}
-		caPool.AppendCertsFromPEM(ca)
+		mirror.MaxBodyBytes = maxBodyBytes
 	}
+	return mirror
+}
 
-	cert := tls.Certificate{}
-	_, errKeyIsFile := os.Stat(clientTLS.Key)
+// getSplit parses the <prefix>.backends=backend1:weight1,backend2:weight2, <prefix>.cookie and <prefix>.header labels,
+// or returns nil if there are no backends.
+func getSplit(labels map[string]string, prefix string) *types.Split {
+	value, ok := labels[prefix+".backends"]
+	if !ok {
+		return nil
+	}
+	backends, err := parseKeyValues(value)
+	if err != nil {
+		log.Errorf("Unable to parse %s.backends %s: %v", prefix, value, err)
+	}
+	split := &types.Split{
+		Backends: map[string]int{},
+		Cookie:   labels[prefix+".cookie"],
+		Header:   labels[prefix+".header"],
+	}
+	for backend, weight := range backends {
+		split.Backends[backend], err = strconv.Atoi(weight)
+		if err != nil {
+			log.Errorf("Unable to parse the weight of backend %s in %s.backends %s: %v", backend, prefix, value, err)
+		}
+	}
+	return split
+}
 
-	if _, errCertIsFile := os.Stat(clientTLS.Cert); errCertIsFile == nil {
-		if errKeyIsFile == nil {
-			cert, err = tls.LoadX509KeyPair(clientTLS.Cert, clientTLS.Key)
+// normalizeSplit normalizes the names of the backends of a split, as the names of the backends of labels are
+func normalizeSplit(split *types.Split) *types.Split {
+	if split == nil {
+		return nil
+	}
+	backends := map[string]int{}
+	for backend, weight := range split.Backends {
+		backends[normalize(backend)] = weight
+	}
+	split.Backends = backends
+	return split
+}
+
+// getErrorPages parses the <prefix>.<name>.status=500-599,404, <prefix>.<name>.backend and <prefix>.<name>.query labels,
+// or returns nil if there are none.
+func getErrorPages(labels map[string]string, prefix string) map[string]*types.ErrorPage {
+	var errorPages map[string]*types.ErrorPage
+	for label, value := range labels {
+		if !strings.HasPrefix(label, prefix+".") {
+			continue
+		}
+		nameOption := strings.SplitN(strings.TrimPrefix(label, prefix+"."), ".", 2)
+		if len(nameOption) != 2 {
+			log.Warnf("Unknown error page option %s", label)
+			continue
+		}
+		if errorPages == nil {
+			errorPages = map[string]*types.ErrorPage{}
+		}
+		errorPage, ok := errorPages[nameOption[0]]
+		if !ok {
+			errorPage = &types.ErrorPage{}
+			errorPages[nameOption[0]] = errorPage
+		}
+		switch nameOption[1] {
+		case "status":
+			errorPage.Status = strings.Split(value, ",")
+		case "backend":
+			errorPage.Backend = value
+		case "query":
+			errorPage.Query = value
+		default:
+			log.Warnf("Unknown error page option %s", label)
+		}
+	}
+	return errorPages
+}
+
+// getFallback parses the <prefix>.<option>=value labels, e.g. <prefix>.statusCode=503 or <prefix>.backend=backend2,
+// or returns nil if there are none.
+func getFallback(labels map[string]string, prefix string) *types.Fallback {
+	var fallback *types.Fallback
+	for label, value := range labels {
+		if !strings.HasPrefix(label, prefix+".") {
+			continue
+		}
+		if fallback == nil {
+			fallback = &types.Fallback{}
+		}
+		switch option := strings.TrimPrefix(label, prefix+"."); option {
+		case "statusCode":
+			statusCode, err := strconv.Atoi(value)
 			if err != nil {
-				return nil, fmt.Errorf("Failed to load TLS keypair: %v", err)
+				log.Errorf("Unable to parse %s %s: %v", label, value, err)
 			}
-		} else {
-			return nil, fmt.Errorf("tls cert is a file, but tls key is not")
+			fallback.StatusCode = statusCode
+		case "contentType":
+			fallback.ContentType = value
+		case "body":
+			fallback.Body = value
+		case "backend":
+			fallback.Backend = value
+		default:
+			log.Warnf("Unknown fallback option %s", label)
 		}
-	} else {
-		if errKeyIsFile != nil {
-			cert, err = tls.X509KeyPair([]byte(clientTLS.Cert), []byte(clientTLS.Key))
-			if err != nil {
-				return nil, fmt.Errorf("Failed to load TLS keypair: %v", err)
+	}
+	return fallback
+}
 
+// getHealthCheck parses the <prefix>.<option>=value labels, e.g. <prefix>.url=/health, and the
+// <prefix>.headers.<Name>=value labels, or returns nil if there are none.
+func getHealthCheck(labels map[string]string, prefix string) *types.HealthCheck {
+	var healthCheck *types.HealthCheck
+	for label, value := range labels {
+		if !strings.HasPrefix(label, prefix+".") {
+			continue
+		}
+		if healthCheck == nil {
+			healthCheck = &types.HealthCheck{}
+		}
+		option := strings.TrimPrefix(label, prefix+".")
+		if strings.HasPrefix(option, "headers.") {
+			if healthCheck.Headers == nil {
+				healthCheck.Headers = map[string]string{}
 			}
-		} else {
-			return nil, fmt.Errorf("tls key is a file, but tls cert is not")
+			healthCheck.Headers[strings.TrimPrefix(option, "headers.")] = value
+			continue
+		}
+		var err error
+		switch option {
+		case "type":
+			healthCheck.Type = value
+		case "url":
+			healthCheck.URL = value
+		case "service":
+			healthCheck.Service = value
+		case "interval":
+			err = healthCheck.Interval.Set(value)
+		case "timeout":
+			err = healthCheck.Timeout.Set(value)
+		case "status":
+			healthCheck.Status = strings.Split(value, ",")
+		case "hostname":
+			healthCheck.Hostname = value
+		case "port":
+			healthCheck.Port, err = strconv.Atoi(value)
+		case "scheme":
+			healthCheck.Scheme = value
+		case "rise":
+			healthCheck.Rise, err = strconv.Atoi(value)
+		case "fall":
+			healthCheck.Fall, err = strconv.Atoi(value)
+		default:
+			log.Warnf("Unknown health check option %s", label)
+		}
+		if err != nil {
+			log.Errorf("Unable to parse %s %s: %v", label, value, err)
+		}
+	}
+	return healthCheck
+}
+
+// getOutlierDetection parses the <prefix>.<option>=value labels, e.g. <prefix>.consecutiveErrors=5,
+// or returns nil if there are none.
+func getOutlierDetection(labels map[string]string, prefix string) *types.OutlierDetection {
+	var outlierDetection *types.OutlierDetection
+	for label, value := range labels {
+		if !strings.HasPrefix(label, prefix+".") {
+			continue
+		}
+		if outlierDetection == nil {
+			outlierDetection = &types.OutlierDetection{}
+		}
+		var err error
+		switch strings.TrimPrefix(label, prefix+".") {
+		case "consecutiveErrors":
+			outlierDetection.ConsecutiveErrors, err = strconv.Atoi(value)
+		case "baseEjectionTime":
+			err = outlierDetection.BaseEjectionTime.Set(value)
+		case "maxEjectionTime":
+			err = outlierDetection.MaxEjectionTime.Set(value)
+		case "maxEjectionPercent":
+			outlierDetection.MaxEjectionPercent, err = strconv.Atoi(value)
+		default:
+			log.Warnf("Unknown outlier detection option %s", label)
+		}
+		if err != nil {
+			log.Errorf("Unable to parse %s %s: %v", label, value, err)
+		}
+	}
+	return outlierDetection
+}
+
+// getHashKey parses the <prefix>.<option>=value labels of the key of the hash load balancer method,
+// e.g. <prefix>.header=X-User, or returns nil if there are none.
+func getHashKey(labels map[string]string, prefix string) *types.HashKey {
+	var hashKey *types.HashKey
+	for label, value := range labels {
+		if !strings.HasPrefix(label, prefix+".") {
+			continue
+		}
+		if hashKey == nil {
+			hashKey = &types.HashKey{}
+		}
+		var err error
+		switch strings.TrimPrefix(label, prefix+".") {
+		case "header":
+			hashKey.Header = value
+		case "cookie":
+			hashKey.Cookie = value
+		case "query":
+			hashKey.Query = value
+		case "path":
+			hashKey.Path, err = strconv.ParseBool(value)
+		case "virtualNodes":
+			hashKey.VirtualNodes, err = strconv.Atoi(value)
+		default:
+			log.Warnf("Unknown hash load balancer option %s", label)
+		}
+		if err != nil {
+			log.Errorf("Unable to parse %s %s: %v", label, value, err)
+		}
+	}
+	return hashKey
+}
+
+// getStickyCookie parses the <prefix>.<option>=value labels of the sticky session cookie,
+// e.g. <prefix>.secure=true, or returns nil if there are none.
+func getStickyCookie(labels map[string]string, prefix string) *types.StickyCookie {
+	var stickyCookie *types.StickyCookie
+	for label, value := range labels {
+		if !strings.HasPrefix(label, prefix+".") {
+			continue
+		}
+		if stickyCookie == nil {
+			stickyCookie = &types.StickyCookie{}
+		}
+		var err error
+		switch strings.TrimPrefix(label, prefix+".") {
+		case "name":
+			stickyCookie.Name = value
+		case "secure":
+			stickyCookie.Secure, err = strconv.ParseBool(value)
+		case "httpOnly":
+			stickyCookie.HTTPOnly, err = strconv.ParseBool(value)
+		case "sameSite":
+			stickyCookie.SameSite = value
+		case "path":
+			stickyCookie.Path = value
+		case "domain":
+			stickyCookie.Domain = value
+		case "maxAge":
+			stickyCookie.MaxAge, err = strconv.Atoi(value)
+		default:
+			log.Warnf("Unknown sticky cookie option %s", label)
+		}
+		if err != nil {
+			log.Errorf("Unable to parse %s %s: %v", label, value, err)
+		}
+	}
+	return stickyCookie
+}
+
+// getTransport parses the <prefix>.<option>=value labels of the connections to the servers,
+// e.g. <prefix>.dialTimeout=5s or <prefix>.tls.serverName=backend.local, or returns nil if there are none.
+func getTransport(labels map[string]string, prefix string) *types.Transport {
+	var transport *types.Transport
+	for label, value := range labels {
+		if !strings.HasPrefix(label, prefix+".") {
+			continue
+		}
+		if transport == nil {
+			transport = &types.Transport{}
+		}
+		option := strings.TrimPrefix(label, prefix+".")
+		if strings.HasPrefix(option, "tls.") && transport.TLS == nil {
+			transport.TLS = &types.ClientTLS{}
 		}
+		var err error
+		switch option {
+		case "dialTimeout":
+			err = transport.DialTimeout.Set(value)
+		case "tlsHandshakeTimeout":
+			err = transport.TLSHandshakeTimeout.Set(value)
+		case "responseHeaderTimeout":
+			err = transport.ResponseHeaderTimeout.Set(value)
+		case "idleConnTimeout":
+			err = transport.IdleConnTimeout.Set(value)
+		case "maxIdleConnsPerHost":
+			transport.MaxIdleConnsPerHost, err = strconv.Atoi(value)
+		case "maxConnsPerHost":
+			transport.MaxConnsPerHost, err = strconv.Atoi(value)
+		case "tls.ca":
+			transport.TLS.CA = value
+		case "tls.cert":
+			transport.TLS.Cert = value
+		case "tls.key":
+			transport.TLS.Key = value
+		case "tls.serverName":
+			transport.TLS.ServerName = value
+		case "tls.insecureSkipVerify":
+			transport.TLS.InsecureSkipVerify, err = strconv.ParseBool(value)
+		default:
+			log.Warnf("Unknown transport option %s", label)
+		}
+		if err != nil {
+			log.Errorf("Unable to parse %s %s: %v", label, value, err)
+		}
+	}
+	return transport
+}
+
+// getUsersAuth parses the <prefix>.users=user1:hash1,user2:hash2 and <prefix>.usersFile=/path labels,
+// ok being false if there are none.
+func getUsersAuth(labels map[string]string, prefix string) (types.Users, string, bool) {
+	users, hasUsers := labels[prefix+".users"]
+	usersFile, hasUsersFile := labels[prefix+".usersFile"]
+	if !hasUsers && !hasUsersFile {
+		return nil, "", false
+	}
+	var usersList types.Users
+	if users != "" {
+		usersList = strings.Split(users, ",")
 	}
+	return usersList, usersFile, true
+}
 
-	TLSConfig := &tls.Config{
-		Certificates:       []tls.Certificate{cert},
-		RootCAs:            caPool,
-		InsecureSkipVerify: clientTLS.InsecureSkipVerify,
+// getBasicAuth parses the <prefix>.users and <prefix>.usersFile labels, or returns nil if there are none.
+func getBasicAuth(labels map[string]string, prefix string) *types.Basic {
+	if users, usersFile, ok := getUsersAuth(labels, prefix); ok {
+		return &types.Basic{Users: users, UsersFile: usersFile}
+	}
+	return nil
+}
+
+// getDigestAuth parses the <prefix>.users and <prefix>.usersFile labels, or returns nil if there are none.
+func getDigestAuth(labels map[string]string, prefix string) *types.Digest {
+	if users, usersFile, ok := getUsersAuth(labels, prefix); ok {
+		return &types.Digest{Users: users, UsersFile: usersFile}
+	}
+	return nil
+}
+
+// getJWTAuth parses the <prefix>.<option>=value labels, e.g. <prefix>.jwks=https://auth.example.com/jwks.json,
+// or returns nil if there is no JWKS. Lists are comma separated and claims are comma separated name:value pairs.
+func getJWTAuth(labels map[string]string, prefix string) *types.JWT {
+	jwks, ok := labels[prefix+".jwks"]
+	if !ok {
+		return nil
+	}
+	jwt := &types.JWT{JWKS: jwks}
+	for label, value := range labels {
+		if !strings.HasPrefix(label, prefix+".") {
+			continue
+		}
+		var err error
+		switch option := strings.TrimPrefix(label, prefix+"."); option {
+		case "jwks":
+		case "jwksRefreshInterval":
+			err = jwt.JWKSRefreshInterval.UnmarshalText([]byte(value))
+		case "issuer":
+			jwt.Issuer = value
+		case "audience":
+			jwt.Audience = value
+		case "requiredScopes":
+			jwt.RequiredScopes = strings.Split(value, ",")
+		case "requiredClaims":
+			jwt.RequiredClaims, err = parseKeyValues(value)
+		case "forwardClaims":
+			jwt.ForwardClaims, err = parseKeyValues(value)
+		default:
+			log.Warnf("Unknown JWT option %s", label)
+		}
+		if err != nil {
+			log.Errorf("Unable to parse %s %s: %v", label, value, err)
+		}
 	}
-	return TLSConfig, nil
+	return jwt
+}
+
+// parseKeyValues parses comma separated key:value pairs, keeping the valid ones.
+func parseKeyValues(value string) (map[string]string, error) {
+	var err error
+	keyValues := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		keyValue := strings.SplitN(pair, ":", 2)
+		if len(keyValue) != 2 {
+			err = fmt.Errorf("missing value of %s", pair)
+			continue
+		}
+		keyValues[strings.TrimSpace(keyValue[0])] = strings.TrimSpace(keyValue[1])
+	}
+	return keyValues, err
+}
+
+func reverseStringSlice(slice *[]string) {
+	for i, j := 0, len(*slice)-1; i < j; i, j = i+1, j-1 {
+		(*slice)[i], (*slice)[j] = (*slice)[j], (*slice)[i]
+	}
+}
+
+// ClientTLS holds TLS specific configurations as client
+// CA, Cert and Key can be either path or file contents
+type ClientTLS types.ClientTLS
+
+// CreateTLSConfig creates a TLS config from ClientTLS structures
+func (clientTLS *ClientTLS) CreateTLSConfig() (*tls.Config, error) {
+	return (*types.ClientTLS)(clientTLS).CreateTLSConfig()
 }
@@ -3,9 +3,11 @@ package provider
 import (
 	"io/ioutil"
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 	"text/template"
+	"time"
 
 	"github.com/containous/traefik/types"
 )
@@ -380,3 +382,627 @@ func TestDefaultFuncMap(t *testing.T) {
 		t.Fatalf("Frontend frontend-1 should exists, but it not")
 	}
 }
+
+func TestGetHeaderOperations(t *testing.T) {
+	cases := []struct {
+		labels   map[string]string
+		expected *types.HeaderOperations
+	}{
+		{
+			labels: map[string]string{
+				"traefik.frontend.rule": "Host:foo",
+			},
+			expected: nil,
+		},
+		{
+			labels: map[string]string{
+				"traefik.frontend.headers.response.remove":              "Server,X-Powered-By",
+				"traefik.frontend.headers.response.set.X-Frame-Options": "DENY",
+				"traefik.frontend.headers.response.add.Via":             "traefik",
+				"traefik.frontend.headers.request.set.X-Client":         "{client.ip}",
+			},
+			expected: &types.HeaderOperations{
+				Remove: []string{"Server", "X-Powered-By"},
+				Set:    map[string]string{"X-Frame-Options": "DENY"},
+				Add:    map[string]string{"Via": "traefik"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		actual := getHeaderOperations(c.labels, "traefik.frontend.headers.response")
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Fatalf("expected %+v, got %+v", c.expected, actual)
+		}
+	}
+}
+
+func TestGetSecurityHeaders(t *testing.T) {
+	cases := []struct {
+		labels   map[string]string
+		expected *types.SecurityHeaders
+	}{
+		{
+			labels: map[string]string{
+				"traefik.frontend.rule": "Host:foo",
+			},
+			expected: nil,
+		},
+		{
+			labels: map[string]string{
+				"traefik.frontend.securityHeaders.sslRedirect":           "true",
+				"traefik.frontend.securityHeaders.sslProxyHeaders":       "X-Forwarded-Proto:https, X-Forwarded-Ssl:on",
+				"traefik.frontend.securityHeaders.stsSeconds":            "315360000",
+				"traefik.frontend.securityHeaders.frameDeny":             "true",
+				"traefik.frontend.securityHeaders.contentSecurityPolicy": "default-src 'self'",
+			},
+			expected: &types.SecurityHeaders{
+				SSLRedirect:           true,
+				SSLProxyHeaders:       map[string]string{"X-Forwarded-Proto": "https", "X-Forwarded-Ssl": "on"},
+				STSSeconds:            315360000,
+				FrameDeny:             true,
+				ContentSecurityPolicy: "default-src 'self'",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		actual := getSecurityHeaders(c.labels, "traefik.frontend.securityHeaders")
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Fatalf("expected %+v, got %+v", c.expected, actual)
+		}
+	}
+}
+
+func TestGetCORS(t *testing.T) {
+	cases := []struct {
+		labels   map[string]string
+		expected *types.CORS
+	}{
+		{
+			labels:   map[string]string{},
+			expected: nil,
+		},
+		{
+			labels: map[string]string{
+				"traefik.frontend.cors.allowedOrigins":   "https://app.example.com,https://*.example.org",
+				"traefik.frontend.cors.allowedMethods":   "GET,PUT",
+				"traefik.frontend.cors.allowCredentials": "true",
+				"traefik.frontend.cors.maxAge":           "600",
+			},
+			expected: &types.CORS{
+				AllowedOrigins:   []string{"https://app.example.com", "https://*.example.org"},
+				AllowedMethods:   []string{"GET", "PUT"},
+				AllowCredentials: true,
+				MaxAge:           600,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		actual := getCORS(c.labels, "traefik.frontend.cors")
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Fatalf("expected %+v, got %+v", c.expected, actual)
+		}
+	}
+}
+
+func TestGetForwardAuth(t *testing.T) {
+	cases := []struct {
+		labels   map[string]string
+		expected *types.Forward
+	}{
+		{
+			labels: map[string]string{
+				"traefik.frontend.auth.forward.timeout": "5s",
+			},
+			expected: nil,
+		},
+		{
+			labels: map[string]string{
+				"traefik.frontend.auth.forward.address":             "http://auth.example.com/verify",
+				"traefik.frontend.auth.forward.authResponseHeaders": "X-User-Id,X-User-Roles",
+				"traefik.frontend.auth.forward.timeout":             "5s",
+			},
+			expected: &types.Forward{
+				Address:             "http://auth.example.com/verify",
+				AuthResponseHeaders: []string{"X-User-Id", "X-User-Roles"},
+				Timeout:             types.Duration(5 * time.Second),
+			},
+		},
+	}
+
+	for _, c := range cases {
+		actual := getForwardAuth(c.labels, "traefik.frontend.auth.forward")
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Fatalf("expected %+v, got %+v", c.expected, actual)
+		}
+	}
+}
+
+func TestGetJWTAuth(t *testing.T) {
+	cases := []struct {
+		labels   map[string]string
+		expected *types.JWT
+	}{
+		{
+			labels: map[string]string{
+				"traefik.frontend.auth.jwt.issuer": "https://issuer.example.com",
+			},
+			expected: nil,
+		},
+		{
+			labels: map[string]string{
+				"traefik.frontend.auth.jwt.jwks":                "https://issuer.example.com/jwks.json",
+				"traefik.frontend.auth.jwt.jwksRefreshInterval": "10m",
+				"traefik.frontend.auth.jwt.audience":            "api",
+				"traefik.frontend.auth.jwt.requiredScopes":      "read,write",
+				"traefik.frontend.auth.jwt.requiredClaims":      "tenant:acme",
+				"traefik.frontend.auth.jwt.forwardClaims":       "sub:X-User-Id, roles:X-User-Roles",
+			},
+			expected: &types.JWT{
+				JWKS:                "https://issuer.example.com/jwks.json",
+				JWKSRefreshInterval: types.Duration(10 * time.Minute),
+				Audience:            "api",
+				RequiredScopes:      []string{"read", "write"},
+				RequiredClaims:      map[string]string{"tenant": "acme"},
+				ForwardClaims:       map[string]string{"sub": "X-User-Id", "roles": "X-User-Roles"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		actual := getJWTAuth(c.labels, "traefik.frontend.auth.jwt")
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Fatalf("expected %+v, got %+v", c.expected, actual)
+		}
+	}
+}
+
+func TestGetBasicAuth(t *testing.T) {
+	cases := []struct {
+		labels   map[string]string
+		expected *types.Basic
+	}{
+		{
+			labels:   map[string]string{},
+			expected: nil,
+		},
+		{
+			labels: map[string]string{
+				"traefik.frontend.auth.basic.users": "test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/,test2:{SHA}z0jT3TdveclVlHs5WCpg5cPeIe8=",
+			},
+			expected: &types.Basic{
+				Users: types.Users{"test:$apr1$H6uskkkW$IgXLP6ewTrSuBkTrqE8wj/", "test2:{SHA}z0jT3TdveclVlHs5WCpg5cPeIe8="},
+			},
+		},
+		{
+			labels: map[string]string{
+				"traefik.frontend.auth.basic.usersFile": "/etc/traefik/.htpasswd",
+			},
+			expected: &types.Basic{
+				UsersFile: "/etc/traefik/.htpasswd",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		actual := getBasicAuth(c.labels, "traefik.frontend.auth.basic")
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Fatalf("expected %+v, got %+v", c.expected, actual)
+		}
+	}
+}
+
+func TestGetBuffering(t *testing.T) {
+	cases := []struct {
+		labels   map[string]string
+		expected *types.Buffering
+	}{
+		{
+			labels: map[string]string{
+				"traefik.backend.maxconn.amount": "10",
+			},
+			expected: nil,
+		},
+		{
+			labels: map[string]string{
+				"traefik.backend.buffering.maxRequestBodyBytes": "10485760",
+				"traefik.backend.buffering.memRequestBodyBytes": "2097152",
+				"traefik.backend.buffering.bufferResponses":     "true",
+			},
+			expected: &types.Buffering{
+				MaxRequestBodyBytes: 10485760,
+				MemRequestBodyBytes: 2097152,
+				BufferResponses:     true,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		actual := getBuffering(c.labels, "traefik.backend.buffering")
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Fatalf("expected %+v, got %+v", c.expected, actual)
+		}
+	}
+}
+
+func TestGetMirror(t *testing.T) {
+	ten, zero := 10, 0
+	cases := []struct {
+		labels   map[string]string
+		expected *types.Mirror
+	}{
+		{
+			labels: map[string]string{
+				"traefik.frontend.mirror.percent": "10",
+			},
+			expected: nil,
+		},
+		{
+			labels: map[string]string{
+				"traefik.frontend.mirror.backend": "shadow",
+			},
+			expected: &types.Mirror{Backend: "shadow"},
+		},
+		{
+			labels: map[string]string{
+				"traefik.frontend.mirror.backend":      "shadow",
+				"traefik.frontend.mirror.percent":      "10",
+				"traefik.frontend.mirror.maxBodyBytes": "65536",
+			},
+			expected: &types.Mirror{Backend: "shadow", Percent: &ten, MaxBodyBytes: 65536},
+		},
+		{
+			labels: map[string]string{
+				"traefik.frontend.mirror.backend": "shadow",
+				"traefik.frontend.mirror.percent": "0",
+			},
+			expected: &types.Mirror{Backend: "shadow", Percent: &zero},
+		},
+	}
+
+	for _, c := range cases {
+		actual := getMirror(c.labels, "traefik.frontend.mirror")
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Fatalf("expected %+v, got %+v", c.expected, actual)
+		}
+	}
+}
+
+func TestGetSplit(t *testing.T) {
+	cases := []struct {
+		labels   map[string]string
+		expected *types.Split
+	}{
+		{
+			labels: map[string]string{
+				"traefik.frontend.split.cookie": "_canary",
+			},
+			expected: nil,
+		},
+		{
+			labels: map[string]string{
+				"traefik.frontend.split.backends": "stable:90, canary:10",
+				"traefik.frontend.split.cookie":   "_canary",
+			},
+			expected: &types.Split{
+				Backends: map[string]int{"stable": 90, "canary": 10},
+				Cookie:   "_canary",
+			},
+		},
+		{
+			labels: map[string]string{
+				"traefik.frontend.split.backends": "stable:100,canary",
+				"traefik.frontend.split.header":   "X-User-Id",
+			},
+			expected: &types.Split{
+				Backends: map[string]int{"stable": 100},
+				Header:   "X-User-Id",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		actual := getSplit(c.labels, "traefik.frontend.split")
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Fatalf("expected %+v, got %+v", c.expected, actual)
+		}
+	}
+}
+
+func TestGetErrorPages(t *testing.T) {
+	cases := []struct {
+		labels   map[string]string
+		expected map[string]*types.ErrorPage
+	}{
+		{
+			labels: map[string]string{
+				"traefik.frontend.split.backends": "stable:100",
+			},
+			expected: nil,
+		},
+		{
+			labels: map[string]string{
+				"traefik.frontend.errors.server.status":  "500-599",
+				"traefik.frontend.errors.server.backend": "errors",
+				"traefik.frontend.errors.server.query":   "/{status}.html",
+				"traefik.frontend.errors.client.status":  "401,403",
+				"traefik.frontend.errors.client.backend": "errors",
+			},
+			expected: map[string]*types.ErrorPage{
+				"server": {Status: []string{"500-599"}, Backend: "errors", Query: "/{status}.html"},
+				"client": {Status: []string{"401", "403"}, Backend: "errors"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		actual := getErrorPages(c.labels, "traefik.frontend.errors")
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Fatalf("expected %+v, got %+v", c.expected, actual)
+		}
+	}
+}
+
+func TestGetFallback(t *testing.T) {
+	cases := []struct {
+		labels   map[string]string
+		expected *types.Fallback
+	}{
+		{
+			labels: map[string]string{
+				"traefik.backend.circuitbreaker.expression": "NetworkErrorRatio() > 0.5",
+			},
+			expected: nil,
+		},
+		{
+			labels: map[string]string{
+				"traefik.backend.circuitbreaker.fallback.statusCode":  "200",
+				"traefik.backend.circuitbreaker.fallback.contentType": "application/json",
+				"traefik.backend.circuitbreaker.fallback.body":        `{"items": []}`,
+			},
+			expected: &types.Fallback{StatusCode: 200, ContentType: "application/json", Body: `{"items": []}`},
+		},
+		{
+			labels: map[string]string{
+				"traefik.backend.circuitbreaker.fallback.backend": "static",
+			},
+			expected: &types.Fallback{Backend: "static"},
+		},
+	}
+
+	for _, c := range cases {
+		actual := getFallback(c.labels, "traefik.backend.circuitbreaker.fallback")
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Fatalf("expected %+v, got %+v", c.expected, actual)
+		}
+	}
+}
+
+func TestGetHealthCheck(t *testing.T) {
+	cases := []struct {
+		labels   map[string]string
+		expected *types.HealthCheck
+	}{
+		{
+			labels: map[string]string{
+				"traefik.backend.buffering.maxRequestBodyBytes": "10485760",
+			},
+			expected: nil,
+		},
+		{
+			labels: map[string]string{
+				"traefik.backend.healthcheck.url":             "/health",
+				"traefik.backend.healthcheck.interval":        "10s",
+				"traefik.backend.healthcheck.timeout":         "2",
+				"traefik.backend.healthcheck.status":          "200-299,401",
+				"traefik.backend.healthcheck.headers.X-Check": "traefik",
+				"traefik.backend.healthcheck.hostname":        "app.example.com",
+				"traefik.backend.healthcheck.port":            "8081",
+				"traefik.backend.healthcheck.scheme":          "https",
+				"traefik.backend.healthcheck.rise":            "2",
+				"traefik.backend.healthcheck.fall":            "3",
+			},
+			expected: &types.HealthCheck{
+				URL:      "/health",
+				Interval: types.Duration(10 * time.Second),
+				Timeout:  types.Duration(2 * time.Second),
+				Status:   []string{"200-299", "401"},
+				Headers:  map[string]string{"X-Check": "traefik"},
+				Hostname: "app.example.com",
+				Port:     8081,
+				Scheme:   "https",
+				Rise:     2,
+				Fall:     3,
+			},
+		},
+		{
+			labels: map[string]string{
+				"traefik.backend.healthcheck.type":    "grpc",
+				"traefik.backend.healthcheck.service": "app.Foo",
+			},
+			expected: &types.HealthCheck{
+				Type:    "grpc",
+				Service: "app.Foo",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		actual := getHealthCheck(c.labels, "traefik.backend.healthcheck")
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Fatalf("expected %+v, got %+v", c.expected, actual)
+		}
+	}
+}
+
+func TestGetOutlierDetection(t *testing.T) {
+	cases := []struct {
+		labels   map[string]string
+		expected *types.OutlierDetection
+	}{
+		{
+			labels: map[string]string{
+				"traefik.backend.healthcheck.url": "/health",
+			},
+			expected: nil,
+		},
+		{
+			labels: map[string]string{
+				"traefik.backend.outlierdetection.consecutiveErrors":  "3",
+				"traefik.backend.outlierdetection.baseEjectionTime":   "10s",
+				"traefik.backend.outlierdetection.maxEjectionTime":    "2m",
+				"traefik.backend.outlierdetection.maxEjectionPercent": "30",
+			},
+			expected: &types.OutlierDetection{
+				ConsecutiveErrors:  3,
+				BaseEjectionTime:   types.Duration(10 * time.Second),
+				MaxEjectionTime:    types.Duration(2 * time.Minute),
+				MaxEjectionPercent: 30,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		actual := getOutlierDetection(c.labels, "traefik.backend.outlierdetection")
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Fatalf("expected %+v, got %+v", c.expected, actual)
+		}
+	}
+}
+
+func TestGetHashKey(t *testing.T) {
+	cases := []struct {
+		labels   map[string]string
+		expected *types.HashKey
+	}{
+		{
+			labels: map[string]string{
+				"traefik.backend.loadbalancer.method": "hash",
+			},
+			expected: nil,
+		},
+		{
+			labels: map[string]string{
+				"traefik.backend.loadbalancer.hash.header":       "X-User",
+				"traefik.backend.loadbalancer.hash.cookie":       "session",
+				"traefik.backend.loadbalancer.hash.query":        "tenant",
+				"traefik.backend.loadbalancer.hash.path":         "true",
+				"traefik.backend.loadbalancer.hash.virtualNodes": "50",
+			},
+			expected: &types.HashKey{
+				Header:       "X-User",
+				Cookie:       "session",
+				Query:        "tenant",
+				Path:         true,
+				VirtualNodes: 50,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		actual := getHashKey(c.labels, "traefik.backend.loadbalancer.hash")
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Fatalf("expected %+v, got %+v", c.expected, actual)
+		}
+	}
+}
+
+func TestGetStickyCookie(t *testing.T) {
+	cases := []struct {
+		labels   map[string]string
+		expected *types.StickyCookie
+	}{
+		{
+			labels: map[string]string{
+				"traefik.backend.loadbalancer.sticky": "true",
+			},
+			expected: nil,
+		},
+		{
+			labels: map[string]string{
+				"traefik.backend.loadbalancer.stickycookie.name":     "affinity",
+				"traefik.backend.loadbalancer.stickycookie.secure":   "true",
+				"traefik.backend.loadbalancer.stickycookie.httpOnly": "true",
+				"traefik.backend.loadbalancer.stickycookie.sameSite": "strict",
+				"traefik.backend.loadbalancer.stickycookie.path":     "/app",
+				"traefik.backend.loadbalancer.stickycookie.domain":   "example.com",
+				"traefik.backend.loadbalancer.stickycookie.maxAge":   "3600",
+			},
+			expected: &types.StickyCookie{
+				Name:     "affinity",
+				Secure:   true,
+				HTTPOnly: true,
+				SameSite: "strict",
+				Path:     "/app",
+				Domain:   "example.com",
+				MaxAge:   3600,
+			},
+		},
+	}
+
+	for _, c := range cases {
+		actual := getStickyCookie(c.labels, "traefik.backend.loadbalancer.stickycookie")
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Fatalf("expected %+v, got %+v", c.expected, actual)
+		}
+	}
+}
+
+func TestGetTransport(t *testing.T) {
+	cases := []struct {
+		labels   map[string]string
+		expected *types.Transport
+	}{
+		{
+			labels: map[string]string{
+				"traefik.backend.loadbalancer.method": "drr",
+			},
+			expected: nil,
+		},
+		{
+			labels: map[string]string{
+				"traefik.backend.transport.dialTimeout":           "5s",
+				"traefik.backend.transport.responseHeaderTimeout": "1m",
+				"traefik.backend.transport.maxConnsPerHost":       "50",
+			},
+			expected: &types.Transport{
+				DialTimeout:           types.Duration(5 * time.Second),
+				ResponseHeaderTimeout: types.Duration(time.Minute),
+				MaxConnsPerHost:       50,
+			},
+		},
+		{
+			labels: map[string]string{
+				"traefik.backend.transport.tlsHandshakeTimeout":    "3s",
+				"traefik.backend.transport.idleConnTimeout":        "30s",
+				"traefik.backend.transport.maxIdleConnsPerHost":    "10",
+				"traefik.backend.transport.tls.ca":                 "/certs/ca.pem",
+				"traefik.backend.transport.tls.cert":               "/certs/client.pem",
+				"traefik.backend.transport.tls.key":                "/certs/client.key",
+				"traefik.backend.transport.tls.serverName":         "backend.local",
+				"traefik.backend.transport.tls.insecureSkipVerify": "true",
+			},
+			expected: &types.Transport{
+				TLSHandshakeTimeout: types.Duration(3 * time.Second),
+				IdleConnTimeout:     types.Duration(30 * time.Second),
+				MaxIdleConnsPerHost: 10,
+				TLS: &types.ClientTLS{
+					CA:                 "/certs/ca.pem",
+					Cert:               "/certs/client.pem",
+					Key:                "/certs/client.key",
+					ServerName:         "backend.local",
+					InsecureSkipVerify: true,
+				},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		actual := getTransport(c.labels, "traefik.backend.transport")
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Fatalf("expected %+v, got %+v", c.expected, actual)
+		}
+	}
+}
@@ -80,7 +80,35 @@ func (provider *Rancher) getFrontendName(service rancherData) string {
 	return normalize(provider.getFrontendRule(service))
 }
 
+func (provider *Rancher) getFrontendSecurityHeaders(service rancherData) *types.SecurityHeaders {
+	return getSecurityHeaders(service.Labels, "traefik.frontend.securityHeaders")
+}
+
+func (provider *Rancher) getSplit(service rancherData) *types.Split {
+	return normalizeSplit(getSplit(service.Labels, "traefik.frontend.split"))
+}
+
 // Backend Labels
+
+func (provider *Rancher) getHealthCheck(service rancherData) *types.HealthCheck {
+	return getHealthCheck(service.Labels, "traefik.backend.healthcheck")
+}
+
+func (provider *Rancher) getOutlierDetection(service rancherData) *types.OutlierDetection {
+	return getOutlierDetection(service.Labels, "traefik.backend.outlierdetection")
+}
+
+func (provider *Rancher) getTransport(service rancherData) *types.Transport {
+	return getTransport(service.Labels, "traefik.backend.transport")
+}
+
+func (provider *Rancher) getHashKey(service rancherData) *types.HashKey {
+	return getHashKey(service.Labels, "traefik.backend.loadbalancer.hash")
+}
+
+func (provider *Rancher) getStickyCookie(service rancherData) *types.StickyCookie {
+	return getStickyCookie(service.Labels, "traefik.backend.loadbalancer.stickycookie")
+}
 func (provider *Rancher) getLoadBalancerMethod(service rancherData) string {
 	if label, err := getServiceLabel(service, "traefik.backend.loadbalancer.method"); err == nil {
 		return label
@@ -91,7 +119,8 @@ func (provider *Rancher) getLoadBalancerMethod(service rancherData) string {
 func (provider *Rancher) hasLoadBalancerLabel(service rancherData) bool {
 	_, errMethod := getServiceLabel(service, "traefik.backend.loadbalancer.method")
 	_, errSticky := getServiceLabel(service, "traefik.backend.loadbalancer.sticky")
-	if errMethod != nil && errSticky != nil {
+	_, errSlowStart := getServiceLabel(service, "traefik.backend.loadbalancer.slowstart")
+	if errMethod != nil && errSticky != nil && errSlowStart != nil && provider.getStickyCookie(service) == nil && provider.getHashKey(service) == nil {
 		return false
 	}
 	return true
@@ -118,6 +147,13 @@ func (provider *Rancher) getSticky(service rancherData) string {
 	return "false"
 }
 
+func (provider *Rancher) getSlowStart(service rancherData) string {
+	if label, err := getServiceLabel(service, "traefik.backend.loadbalancer.slowstart"); err == nil {
+		return label
+	}
+	return "0s"
+}
+
 func (provider *Rancher) getBackend(service rancherData) string {
 	if label, err := getServiceLabel(service, "traefik.backend"); err == nil {
 		return normalize(label)
@@ -393,6 +429,14 @@ func (provider *Rancher) loadRancherConfig(services []rancherData) *types.Config
 		"getMaxConnAmount":            provider.getMaxConnAmount,
 		"getMaxConnExtractorFunc":     provider.getMaxConnExtractorFunc,
 		"getSticky":                   provider.getSticky,
+		"getSlowStart":                provider.getSlowStart,
+		"getSplit":                    provider.getSplit,
+		"getFrontendSecurityHeaders":  provider.getFrontendSecurityHeaders,
+		"getHealthCheck":              provider.getHealthCheck,
+		"getOutlierDetection":         provider.getOutlierDetection,
+		"getTransport":                provider.getTransport,
+		"getHashKey":                  provider.getHashKey,
+		"getStickyCookie":             provider.getStickyCookie,
 	}
 
 	// filter services
@@ -9,6 +9,7 @@ import (
 	"crypto/x509"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
@@ -50,6 +51,7 @@ type Server struct {
 	stopChan                   chan bool
 	providers                  []provider.Provider
 	currentConfigurations      safe.Safe
+	backendStates              safe.Safe
 	globalConfiguration        GlobalConfiguration
 	loggerMiddleware           *middlewares.Logger
 	routinesPool               *safe.Pool
@@ -69,6 +71,105 @@ type serverRoute struct {
 	addPrefix     string
 }
 
+// backendLoadBalancer is the load balancer of the servers of a backend
+type backendLoadBalancer interface {
+	http.Handler
+	RemoveServer(u *url.URL) error
+	UpsertServer(u *url.URL, options ...roundrobin.ServerOption) error
+	Servers() []*url.URL
+}
+
+// backendStates holds the stateful middlewares of the backends of a configuration, whose state is published by the API,
+// and the rate limiters and authenticators of its frontends. Each configuration reload builds its own, swapped with the handlers.
+type backendStates struct {
+	circuitBreakers  map[backendKey]*middlewares.CircuitBreaker
+	outlierDetectors map[backendKey]*middlewares.OutlierDetector
+	slowStarts       map[backendKey]*middlewares.SlowStart
+	transports       map[backendKey]*backendTransport
+	drainers         map[backendKey]*middlewares.Drainer
+	rateLimiters     map[frontendKey]*frontendRateLimiter
+	authenticators   []*middlewares.Authenticator
+}
+
+// backendTransport is the transport of a backend with its own transport configuration,
+// kept by the reloads which do not change its configuration
+type backendTransport struct {
+	config    *types.Transport
+	transport *http.Transport
+}
+
+// frontendRateLimiter is the rate limiter of a frontend on an entry point, kept with the rates of its clients
+// by the reloads which do not change its configuration
+type frontendRateLimiter struct {
+	config      *types.RateLimit
+	rateLimiter *middlewares.RateLimiter
+}
+
+// frontendKey identifies a frontend on an entry point, whose name is only unique within its provider
+type frontendKey struct {
+	provider   string
+	frontend   string
+	entryPoint string
+}
+
+// backendKey identifies a backend, whose name is only unique within its provider
+type backendKey struct {
+	provider string
+	backend  string
+}
+
+func newBackendStates() *backendStates {
+	return &backendStates{
+		circuitBreakers:  make(map[backendKey]*middlewares.CircuitBreaker),
+		outlierDetectors: make(map[backendKey]*middlewares.OutlierDetector),
+		slowStarts:       make(map[backendKey]*middlewares.SlowStart),
+		transports:       make(map[backendKey]*backendTransport),
+		drainers:         make(map[backendKey]*middlewares.Drainer),
+		rateLimiters:     make(map[frontendKey]*frontendRateLimiter),
+	}
+}
+
+// release releases the middlewares of the previous configuration once states replaces it,
+// the drainers of states taking over the drained servers
+func (states *backendStates) release(previous *backendStates) {
+	for _, drainer := range states.drainers {
+		drainer.Register()
+	}
+	backends := make(map[string]bool)
+	for key := range states.circuitBreakers {
+		backends[key.backend] = true
+	}
+	for key := range states.outlierDetectors {
+		backends[key.backend] = true
+	}
+	for key, cb := range previous.circuitBreakers {
+		// the metrics are partitioned by backend name only
+		if !backends[key.backend] {
+			cb.Close()
+		}
+	}
+	for key, o := range previous.outlierDetectors {
+		// the load balancer of the detector is discarded with the previous configuration
+		o.Stop()
+		if !backends[key.backend] {
+			o.Close()
+		}
+	}
+	for _, slowStart := range previous.slowStarts {
+		slowStart.Stop()
+	}
+	for key, previousTransport := range previous.transports {
+		// the connections of the requests in progress are closed once idle for IdleConnTimeout
+		if transport, ok := states.transports[key]; !ok || transport.transport != previousTransport.transport {
+			previousTransport.transport.CloseIdleConnections()
+		}
+	}
+	for _, authenticator := range previous.authenticators {
+		// the users files still used by the new authenticators stay watched
+		authenticator.Close()
+	}
+}
+
 // NewServer returns an initialized Server.
 func NewServer(globalConfiguration GlobalConfiguration) *Server {
 	server := new(Server)
@@ -82,6 +183,7 @@ func NewServer(globalConfiguration GlobalConfiguration) *Server {
 	signal.Notify(server.signals, syscall.SIGINT, syscall.SIGTERM)
 	currentConfigurations := make(configs)
 	server.currentConfigurations.Set(currentConfigurations)
+	server.backendStates.Set(newBackendStates())
 	server.globalConfiguration = globalConfiguration
 	server.loggerMiddleware = middlewares.NewLogger(globalConfiguration.AccessLogsFile, globalConfiguration.AccessLogsFormat)
 	server.routinesPool = safe.NewPool(context.Background())
@@ -182,6 +284,13 @@ func (server *Server) startHTTPServers() {
 			statsRecorder = middlewares.NewStatsRecorder(server.globalConfiguration.Web.Statistics.RecentErrors)
 			serverMiddlewares = append(serverMiddlewares, statsRecorder)
 		}
+		if server.globalConfiguration.EntryPoints[newServerEntryPointName].IPFilter != nil {
+			ipFilter, err := middlewares.NewIPFilter(newServerEntryPointName, server.globalConfiguration.EntryPoints[newServerEntryPointName].IPFilter)
+			if err != nil {
+				log.Fatal("Error starting server: ", err)
+			}
+			serverMiddlewares = append(serverMiddlewares, ipFilter)
+		}
 		if server.globalConfiguration.EntryPoints[newServerEntryPointName].Auth != nil {
 			authMiddleware, err := middlewares.NewAuthenticator(server.globalConfiguration.EntryPoints[newServerEntryPointName].Auth)
 			if err != nil {
@@ -261,7 +370,11 @@ func (server *Server) defaultConfigurationValues(configuration *types.Configurat
 		_, err := types.NewLoadBalancerMethod(backend.LoadBalancer)
 		if err != nil {
 			log.Debugf("Load balancer method '%+v' for backend %s: %v. Using default wrr.", backend.LoadBalancer, backendName, err)
-			backend.LoadBalancer = &types.LoadBalancer{Method: "wrr"}
+			// keeping the other load balancer settings, e.g. set without method by the tags of a consul catalog service
+			if backend.LoadBalancer == nil {
+				backend.LoadBalancer = &types.LoadBalancer{}
+			}
+			backend.LoadBalancer.Method = "wrr"
 		}
 	}
 }
@@ -284,13 +397,16 @@ func (server *Server) listenConfigurations(stop chan bool) {
 			}
 			newConfigurations[configMsg.ProviderName] = configMsg.Configuration
 
-			newServerEntryPoints, err := server.loadConfig(newConfigurations, server.globalConfiguration)
+			newServerEntryPoints, states, err := server.loadConfig(newConfigurations, server.globalConfiguration)
 			if err == nil {
 				for newServerEntryPointName, newServerEntryPoint := range newServerEntryPoints {
 					server.serverEntryPoints[newServerEntryPointName].httpRouter.UpdateHandler(newServerEntryPoint.httpRouter.GetHandler())
 					log.Infof("Server configuration reloaded on %s", server.serverEntryPoints[newServerEntryPointName].httpServer.Addr)
 				}
+				previousStates := server.backendStates.Get().(*backendStates)
+				server.backendStates.Set(states)
 				server.currentConfigurations.Set(newConfigurations)
+				states.release(previousStates)
 				server.postLoadConfig()
 			} else {
 				log.Error("Error loading new configuration, aborted ", err)
@@ -551,17 +667,19 @@ func (server *Server) buildEntryPoints(globalConfiguration GlobalConfiguration)
 
 // TODO server middleware needs a shutdown loop that calls Close() on io.Closer middlewares
 // LoadConfig returns a new gorilla.mux Route from the specified global configuration and the dynamic
-// provider configurations.
-func (server *Server) loadConfig(configurations configs, globalConfiguration GlobalConfiguration) (map[string]*serverEntryPoint, error) {
+// provider configurations, with the states of their backends.
+func (server *Server) loadConfig(configurations configs, globalConfiguration GlobalConfiguration) (map[string]*serverEntryPoint, *backendStates, error) {
 	serverEntryPoints := server.buildEntryPoints(globalConfiguration)
 	redirectHandlers := make(map[string]http.Handler)
 
 	backends := map[string]http.Handler{}
+	backendAuditTaps := map[string]*audittap.AuditTap{}
 
 	backendsHealthcheck := map[string]*healthcheck.BackendHealthCheck{}
+	states := newBackendStates()
 
 	backend2FrontendMap := map[string]string{}
-	for _, configuration := range configurations {
+	for providerName, configuration := range configurations {
 		frontendNames := sortedFrontendNamesForConfig(configuration)
 		frontend:
 		for _, frontendName := range frontendNames {
@@ -569,7 +687,7 @@ func (server *Server) loadConfig(configurations configs, globalConfiguration Glo
 
 			log.Debugf("Creating frontend %s", frontendName)
 
-			fwd, err := forward.New(forward.Logger(oxyLogger), forward.PassHostHeader(frontend.PassHostHeader))
+			fwd, err := forward.New(forward.Logger(oxyLogger), forward.ErrorHandler(middlewares.NetworkErrorHandler), forward.PassHostHeader(frontend.PassHostHeader))
 			if err != nil {
 				log.Errorf("Error creating forwarder for frontend %s: %v", frontendName, err)
 				log.Errorf("Skipping frontend %s...", frontendName)
@@ -611,151 +729,163 @@ func (server *Server) loadConfig(configurations configs, globalConfiguration Glo
 						redirectHandlers[entryPointName] = handler
 					}
 				} else {
-					if backends[frontend.Backend] == nil {
-						log.Debugf("Creating backend %s", frontend.Backend)
-						var lb http.Handler
-						rr, _ := roundrobin.New(saveBackend)
-						if configuration.Backends[frontend.Backend] == nil {
-							log.Errorf("Undefined backend '%s' for frontend %s", frontend.Backend, frontendName)
+					// getBackend returns the handler of a backend of the frontend, creating it on first use
+					var getBackend func(backendName string) (http.Handler, error)
+					loadingBackends := map[string]bool{}
+					getBackend = func(backendName string) (http.Handler, error) {
+						if backends[backendName] != nil {
+							log.Debugf("Reusing backend %s", backendName)
+							return backends[backendName], nil
+						}
+						// a backend can fall back to another one, which must not fall back to the first
+						if loadingBackends[backendName] {
+							return nil, fmt.Errorf("Circular fallback of backend '%s'", backendName)
+						}
+						loadingBackends[backendName] = true
+						defer delete(loadingBackends, backendName)
+						log.Debugf("Creating backend %s", backendName)
+						handler, err := server.loadBackend(configuration, providerName, backendName, frontendName, saveBackend, globalConfiguration, backendsHealthcheck, backend2FrontendMap, backendAuditTaps, states, getBackend)
+						if err != nil {
+							return nil, err
+						}
+						backends[backendName] = handler
+						return handler, nil
+					}
+					backend, err := getBackend(frontend.Backend)
+					if err != nil {
+						log.Errorf("Error creating backend %s for frontend %s: %v", frontend.Backend, frontendName, err)
+						log.Errorf("Skipping frontend %s...", frontendName)
+						continue frontend
+					}
+					if frontend.Priority > 0 {
+						newServerRoute.route.Priority(frontend.Priority)
+					}
+					var frontendNegroni = negroni.New()
+					requestIDConfig := frontend.RequestID
+					if requestIDConfig == nil && frontend.RequestHeader {
+						requestIDConfig = &types.RequestID{}
+					}
+					if requestIDConfig != nil {
+						log.Debugf("Creating request ID handler for frontend %s", frontendName)
+						requestID, err := middlewares.NewRequestID(requestIDConfig)
+						if err != nil {
+							log.Errorf("Error creating request ID handler for frontend %s: %v", frontendName, err)
 							log.Errorf("Skipping frontend %s...", frontendName)
 							continue frontend
 						}
-
-						lbMethod, err := types.NewLoadBalancerMethod(configuration.Backends[frontend.Backend].LoadBalancer)
+						frontendNegroni.Use(requestID)
+					}
+					if frontend.SecurityHeaders != nil {
+						log.Debugf("Creating security headers handler for frontend %s", frontendName)
+						frontendNegroni.Use(middlewares.NewSecurityHeaders(frontend.SecurityHeaders))
+					}
+					if frontend.Headers != nil {
+						log.Debugf("Creating headers handler for frontend %s", frontendName)
+						frontendNegroni.Use(middlewares.NewHeaders(frontend.Headers))
+					}
+					if frontend.IPFilter != nil {
+						log.Debugf("Creating IP filter for frontend %s", frontendName)
+						ipFilter, err := middlewares.NewIPFilter(frontendName, frontend.IPFilter)
 						if err != nil {
-							log.Errorf("Error loading load balancer method '%+v' for frontend %s: %v", configuration.Backends[frontend.Backend].LoadBalancer, frontendName, err)
+							log.Errorf("Error creating IP filter for frontend %s: %v", frontendName, err)
 							log.Errorf("Skipping frontend %s...", frontendName)
 							continue frontend
 						}
-
-						stickysession := configuration.Backends[frontend.Backend].LoadBalancer.Sticky
-						cookiename := "_TRAEFIK_BACKEND"
-						var sticky *roundrobin.StickySession
-
-						if stickysession {
-							sticky = roundrobin.NewStickySession(cookiename)
+						// denied requests never reach the backend, audit them with its tap
+						if probe, ok := backendAuditTaps[frontend.Backend]; ok {
+							ipFilter.DeniedTap = probe
 						}
-
-						switch lbMethod {
-						case types.Drr:
-							log.Debugf("Creating load-balancer drr")
-							rebalancer, _ := roundrobin.NewRebalancer(rr, roundrobin.RebalancerLogger(oxyLogger))
-							if stickysession {
-								log.Debugf("Sticky session with cookie %v", cookiename)
-								rebalancer, _ = roundrobin.NewRebalancer(rr, roundrobin.RebalancerLogger(oxyLogger), roundrobin.RebalancerStickySession(sticky))
-							}
-							lb = rebalancer
-							for serverName, server := range configuration.Backends[frontend.Backend].Servers {
-								url, err := url.Parse(server.URL)
-								if err != nil {
-									log.Errorf("Error parsing server URL %s: %v", server.URL, err)
-									log.Errorf("Skipping frontend %s...", frontendName)
-									continue frontend
-								}
-								backend2FrontendMap[url.String()] = frontendName
-								log.Debugf("Creating server %s at %s with weight %d", serverName, url.String(), server.Weight)
-								if err := rebalancer.UpsertServer(url, roundrobin.Weight(server.Weight)); err != nil {
-									log.Errorf("Error adding server %s to load balancer: %v", server.URL, err)
-									log.Errorf("Skipping frontend %s...", frontendName)
-									continue frontend
-								}
-								if configuration.Backends[frontend.Backend].HealthCheck != nil {
-									backendsHealthcheck[frontend.Backend] = healthcheck.NewBackendHealthCheck(configuration.Backends[frontend.Backend].HealthCheck.URL, rebalancer)
-								}
-							}
-						case types.Wrr:
-							log.Debugf("Creating load-balancer wrr")
-							if stickysession {
-								log.Debugf("Sticky session with cookie %v", cookiename)
-								rr, _ = roundrobin.New(saveBackend, roundrobin.EnableStickySession(sticky))
-							}
-							lb = rr
-							for serverName, server := range configuration.Backends[frontend.Backend].Servers {
-								url, err := url.Parse(server.URL)
-								if err != nil {
-									log.Errorf("Error parsing server URL %s: %v", server.URL, err)
-									log.Errorf("Skipping frontend %s...", frontendName)
-									continue frontend
-								}
-								backend2FrontendMap[url.String()] = frontendName
-								log.Debugf("Creating server %s at %s with weight %d", serverName, url.String(), server.Weight)
-								if err := rr.UpsertServer(url, roundrobin.Weight(server.Weight)); err != nil {
-									log.Errorf("Error adding server %s to load balancer: %v", server.URL, err)
-									log.Errorf("Skipping frontend %s...", frontendName)
-									continue frontend
-								}
-							}
-							if configuration.Backends[frontend.Backend].HealthCheck != nil {
-								backendsHealthcheck[frontend.Backend] = healthcheck.NewBackendHealthCheck(configuration.Backends[frontend.Backend].HealthCheck.URL, rr)
-							}
+						frontendNegroni.Use(ipFilter)
+					}
+					if frontend.CORS != nil {
+						log.Debugf("Creating CORS handler for frontend %s", frontendName)
+						cors, err := middlewares.NewCORS(frontend.CORS)
+						if err != nil {
+							log.Errorf("Error creating CORS handler for frontend %s: %v", frontendName, err)
+							log.Errorf("Skipping frontend %s...", frontendName)
+							continue frontend
 						}
-						maxConns := configuration.Backends[frontend.Backend].MaxConn
-						if maxConns != nil && maxConns.Amount != 0 {
-							extractFunc, err := utils.NewExtractor(maxConns.ExtractorFunc)
-							if err != nil {
-								log.Errorf("Error creating connlimit: %v", err)
-								log.Errorf("Skipping frontend %s...", frontendName)
-								continue frontend
-							}
-							log.Debugf("Creating loadd-balancer connlimit")
-							lb, err = connlimit.New(lb, extractFunc, maxConns.Amount, connlimit.Logger(oxyLogger))
+						frontendNegroni.Use(cors)
+					}
+					// the error pages are wrapped by the security, custom and CORS headers, which they keep
+					if len(frontend.Errors) > 0 {
+						log.Debugf("Creating error pages for frontend %s", frontendName)
+						errorPageBackends := make(map[string]http.Handler)
+						for _, errorPage := range frontend.Errors {
+							errorPageBackend, err := getBackend(errorPage.Backend)
 							if err != nil {
-								log.Errorf("Error creating connlimit: %v", err)
+								log.Errorf("Error creating error page backend %s for frontend %s: %v", errorPage.Backend, frontendName, err)
 								log.Errorf("Skipping frontend %s...", frontendName)
 								continue frontend
 							}
+							errorPageBackends[errorPage.Backend] = errorPageBackend
 						}
-						// retry ?
-						if globalConfiguration.Retry != nil {
-							retries := len(configuration.Backends[frontend.Backend].Servers)
-							if globalConfiguration.Retry.Attempts > 0 {
-								retries = globalConfiguration.Retry.Attempts
-							}
-							lb = middlewares.NewRetry(retries, lb)
-							log.Debugf("Creating retries max attempts %d", retries)
+						errorPages, err := middlewares.NewErrorPages(frontend.Errors, errorPageBackends)
+						if err != nil {
+							log.Errorf("Error creating error pages for frontend %s: %v", frontendName, err)
+							log.Errorf("Skipping frontend %s...", frontendName)
+							continue frontend
 						}
-
-						// TODO make this configurable
-						var negroni = negroni.New()
-						if configuration.Backends[frontend.Backend].AuditTap != nil {
-							auditTapConfig := configuration.Backends[frontend.Backend].AuditTap
-							probe, err := audittap.NewAuditTap(auditTapConfig, frontend.Backend)
-							if err == nil {
-								negroni.Use(probe)
-							}
+						frontendNegroni.Use(errorPages)
+					}
+					if frontend.Auth != nil {
+						log.Debugf("Creating authenticator for frontend %s", frontendName)
+						authMiddleware, err := middlewares.NewAuthenticator(frontend.Auth)
+						if err != nil {
+							log.Errorf("Error creating authenticator for frontend %s: %v", frontendName, err)
+							log.Errorf("Skipping frontend %s...", frontendName)
+							continue frontend
 						}
-						if server.globalConfiguration.Web != nil && server.globalConfiguration.Web.Metrics != nil {
-							if server.globalConfiguration.Web.Metrics.Prometheus != nil {
-								metricsMiddlewareBackend := middlewares.NewMetricsWrapper(middlewares.NewPrometheus(frontend.Backend, server.globalConfiguration.Web.Metrics.Prometheus))
-								negroni.Use(metricsMiddlewareBackend)
-							}
+						states.authenticators = append(states.authenticators, authMiddleware)
+						frontendNegroni.Use(authMiddleware)
+					}
+					if frontend.RateLimit != nil {
+						rateLimiter, err := server.getRateLimiter(states, frontendKey{providerName, frontendName, entryPointName}, frontend.RateLimit)
+						if err != nil {
+							log.Errorf("Error creating rate limiter for frontend %s: %v", frontendName, err)
+							log.Errorf("Skipping frontend %s...", frontendName)
+							continue frontend
+						}
+						frontendNegroni.Use(rateLimiter)
+					}
+					if frontend.Mirror != nil {
+						log.Debugf("Creating mirror to backend %s for frontend %s", frontend.Mirror.Backend, frontendName)
+						mirrorBackend, err := getBackend(frontend.Mirror.Backend)
+						if err != nil {
+							log.Errorf("Error creating mirror backend %s for frontend %s: %v", frontend.Mirror.Backend, frontendName, err)
+							log.Errorf("Skipping frontend %s...", frontendName)
+							continue frontend
 						}
-						if configuration.Backends[frontend.Backend].CircuitBreaker != nil {
-							log.Debugf("Creating circuit breaker %s", configuration.Backends[frontend.Backend].CircuitBreaker.Expression)
-							cbreaker, err := middlewares.NewCircuitBreaker(lb, configuration.Backends[frontend.Backend].CircuitBreaker.Expression, cbreaker.Logger(oxyLogger))
+						mirror, err := middlewares.NewMirror(frontendName, frontend.Mirror, mirrorBackend)
+						if err != nil {
+							log.Errorf("Error creating mirror for frontend %s: %v", frontendName, err)
+							log.Errorf("Skipping frontend %s...", frontendName)
+							continue frontend
+						}
+						frontendNegroni.Use(mirror)
+					}
+					if frontend.Split != nil {
+						log.Debugf("Creating split for frontend %s", frontendName)
+						splitBackends := make(map[string]http.Handler)
+						for backendName := range frontend.Split.Backends {
+							splitBackend, err := getBackend(backendName)
 							if err != nil {
-								log.Errorf("Error creating circuit breaker: %v", err)
+								log.Errorf("Error creating split backend %s for frontend %s: %v", backendName, frontendName, err)
 								log.Errorf("Skipping frontend %s...", frontendName)
 								continue frontend
 							}
-							negroni.Use(cbreaker)
-						} else {
-							negroni.UseHandler(lb)
+							splitBackends[backendName] = splitBackend
 						}
-						backends[frontend.Backend] = negroni
-					} else {
-						log.Debugf("Reusing backend %s", frontend.Backend)
-					}
-					if frontend.Priority > 0 {
-						newServerRoute.route.Priority(frontend.Priority)
-					}
-					if frontend.RequestHeader {
-						log.Debugf("Adding requestHeader handler to %s", frontend.Backend)
-						backends[frontend.Backend] = &middlewares.AddRequestHeader{
-							Handler: backends[frontend.Backend],
+						split, err := middlewares.NewSplit(frontend.Split, splitBackends)
+						if err != nil {
+							log.Errorf("Error creating split for frontend %s: %v", frontendName, err)
+							log.Errorf("Skipping frontend %s...", frontendName)
+							continue frontend
 						}
+						frontendNegroni.Use(split)
 					}
-					server.wireFrontendBackend(newServerRoute, backends[frontend.Backend])
+					frontendNegroni.UseHandler(backend)
+					server.wireFrontendBackend(newServerRoute, frontendNegroni)
 				}
 				err := newServerRoute.route.GetError()
 				if err != nil {
@@ -764,13 +894,253 @@ func (server *Server) loadConfig(configurations configs, globalConfiguration Glo
 			}
 		}
 	}
+	for entryPointName, serverEntryPoint := range serverEntryPoints {
+		if pages := globalConfiguration.EntryPoints[entryPointName].Errors; len(pages) > 0 {
+			log.Debugf("Creating error pages for entrypoint %s", entryPointName)
+			if err := wireNotFoundErrorPages(serverEntryPoint.httpRouter.GetHandler(), pages, backends); err != nil {
+				log.Errorf("Error creating error pages for entrypoint %s, keeping the default 404 page: %v", entryPointName, err)
+			}
+		}
+	}
 	healthcheck.GetHealthCheck().SetBackendsConfiguration(server.routinesPool.Ctx(), backendsHealthcheck)
 	middlewares.SetBackend2FrontendMap(&backend2FrontendMap)
 	//sort routes
 	for _, serverEntryPoint := range serverEntryPoints {
 		serverEntryPoint.httpRouter.GetHandler().SortRoutes()
 	}
-	return serverEntryPoints, nil
+	return serverEntryPoints, states, nil
+}
+
+// getRateLimiter returns the rate limiter of a frontend, the one of the current configuration if its configuration is unchanged
+func (server *Server) getRateLimiter(states *backendStates, key frontendKey, config *types.RateLimit) (*middlewares.RateLimiter, error) {
+	if current, ok := server.backendStates.Get().(*backendStates).rateLimiters[key]; ok && reflect.DeepEqual(current.config, config) {
+		states.rateLimiters[key] = current
+		return current.rateLimiter, nil
+	}
+	log.Debugf("Creating rate limiter for frontend %s", key.frontend)
+	rateLimiter, err := middlewares.NewRateLimiter(key.frontend, config)
+	if err != nil {
+		return nil, err
+	}
+	states.rateLimiters[key] = &frontendRateLimiter{config: config, rateLimiter: rateLimiter}
+	return rateLimiter, nil
+}
+
+// getTransport returns the transport of a backend, the one of the current configuration if its configuration is unchanged
+func (server *Server) getTransport(states *backendStates, key backendKey, config *types.Transport, globalConfiguration GlobalConfiguration) (*http.Transport, error) {
+	if loaded, ok := states.transports[key]; ok && reflect.DeepEqual(loaded.config, config) {
+		return loaded.transport, nil
+	}
+	if current, ok := server.backendStates.Get().(*backendStates).transports[key]; ok && reflect.DeepEqual(current.config, config) {
+		states.transports[key] = current
+		return current.transport, nil
+	}
+	log.Debugf("Creating transport for backend %s", key.backend)
+	transport, err := middlewares.NewTransport(config, globalConfiguration.MaxIdleConnsPerHost, globalConfiguration.InsecureSkipVerify)
+	if err != nil {
+		return nil, err
+	}
+	states.transports[key] = &backendTransport{config: config, transport: transport}
+	return transport, nil
+}
+
+// loadBackend creates the handler of a backend: its load balancer sending the requests to the servers
+// with fwd, wrapped by the retries, audit tap, metrics, buffering and circuit breaker of the backend.
+// getBackend returns the handler of the backend a tripped circuit breaker falls back to.
+func (server *Server) loadBackend(configuration *types.Configuration, providerName string, backendName string, frontendName string, fwd http.Handler, globalConfiguration GlobalConfiguration, backendsHealthcheck map[string]*healthcheck.BackendHealthCheck, backend2FrontendMap map[string]string, backendAuditTaps map[string]*audittap.AuditTap, states *backendStates, getBackend func(backendName string) (http.Handler, error)) (http.Handler, error) {
+	if configuration.Backends[backendName] == nil {
+		return nil, fmt.Errorf("Undefined backend '%s'", backendName)
+	}
+	// the TLS configuration of the forwarder, the health check connecting to the servers the same way
+	var tlsConfig *tls.Config
+	if globalConfiguration.InsecureSkipVerify {
+		tlsConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	if config := configuration.Backends[backendName].Transport; config != nil {
+		transport, err := server.getTransport(states, backendKey{providerName, backendName}, config, globalConfiguration)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig = transport.TLSClientConfig
+		forwarder, err := forward.New(forward.Logger(oxyLogger), forward.ErrorHandler(middlewares.NetworkErrorHandler), forward.PassHostHeader(configuration.Frontends[frontendName].PassHostHeader), forward.RoundTripper(transport))
+		if err != nil {
+			return nil, fmt.Errorf("Error creating forwarder for backend %s: %v", backendName, err)
+		}
+		fwd = middlewares.NewSaveBackend(forwarder)
+	}
+	var lb http.Handler
+	var outlierDetector *middlewares.OutlierDetector
+	if configuration.Backends[backendName].OutlierDetection != nil {
+		log.Debugf("Creating outlier detection for backend %s", backendName)
+		detector, err := middlewares.NewOutlierDetector(backendName, configuration.Backends[backendName].OutlierDetection, fwd)
+		if err != nil {
+			return nil, err
+		}
+		outlierDetector = detector
+		states.outlierDetectors[backendKey{providerName, backendName}] = detector
+		fwd = detector
+	}
+	// the drainer keeps the drained servers out of the load balancer, and counts their requests in progress
+	drainer := middlewares.NewDrainer(providerName, backendName, fwd)
+	states.drainers[backendKey{providerName, backendName}] = drainer
+	fwd = drainer
+	lbMethod, err := types.NewLoadBalancerMethod(configuration.Backends[backendName].LoadBalancer)
+	if err != nil {
+		return nil, fmt.Errorf("Error loading load balancer method '%+v': %v", configuration.Backends[backendName].LoadBalancer, err)
+	}
+
+	var stickySession *middlewares.StickySession
+	if loadBalancer := configuration.Backends[backendName].LoadBalancer; loadBalancer != nil && (loadBalancer.Sticky || loadBalancer.StickyCookie != nil) {
+		if lbMethod == types.Hash {
+			log.Warnf("Sticky session of backend %s ignored by its hash load-balancer", backendName)
+		} else {
+			stickySession, err = middlewares.NewStickySession(backendName, loadBalancer.StickyCookie, fwd)
+			if err != nil {
+				return nil, err
+			}
+			log.Debugf("Sticky session of backend %s with its own cookie", backendName)
+			// the load balancer sets the cookie of the server it chooses
+			fwd = stickySession.Forwarder()
+		}
+	}
+	rr, _ := roundrobin.New(fwd)
+
+	slowStart := time.Duration(configuration.Backends[backendName].LoadBalancer.SlowStart)
+	if slowStart != 0 && lbMethod == types.Hash {
+		log.Warnf("Slow start of backend %s ignored by its hash load-balancer", backendName)
+		slowStart = 0
+	}
+	// the servers of the previous load balancer of the backend keep their weight, or go on with their slow start
+	previousSlowStart := server.backendStates.Get().(*backendStates).slowStarts[backendKey{providerName, backendName}]
+
+	// the configured weights of the servers, restored by the health check, the outlier detection and the drainer when they are up again
+	weights := make(map[string]int)
+	var balancer backendLoadBalancer
+	switch lbMethod {
+	case types.Drr:
+		log.Debugf("Creating load-balancer drr")
+		balancer, _ = roundrobin.NewRebalancer(rr, roundrobin.RebalancerLogger(oxyLogger))
+	case types.Wrr:
+		log.Debugf("Creating load-balancer wrr")
+		balancer = rr
+	case types.LeastConn:
+		log.Debugf("Creating load-balancer leastconn")
+		balancer = middlewares.NewLeastConnBalancer(fwd)
+	case types.P2C:
+		log.Debugf("Creating load-balancer p2c")
+		balancer = middlewares.NewP2CBalancer(fwd)
+	case types.Hash:
+		log.Debugf("Creating load-balancer hash")
+		balancer, err = middlewares.NewHashBalancer(fwd, configuration.Backends[backendName].LoadBalancer.Hash)
+		if err != nil {
+			return nil, err
+		}
+	}
+	lb = balancer
+	slowStarter := middlewares.NewSlowStart(backendName, slowStart, balancer, previousSlowStart)
+	states.slowStarts[backendKey{providerName, backendName}] = slowStarter
+	drainer.SetLoadBalancer(slowStarter, weights)
+	for serverName, server := range configuration.Backends[backendName].Servers {
+		url, err := url.Parse(server.URL)
+		if err != nil {
+			return nil, fmt.Errorf("Error parsing server URL %s: %v", server.URL, err)
+		}
+		backend2FrontendMap[url.String()] = frontendName
+		weights[url.String()] = server.Weight
+		log.Debugf("Creating server %s at %s with weight %d", serverName, url.String(), server.Weight)
+		if err := drainer.UpsertServer(url, roundrobin.Weight(server.Weight)); err != nil {
+			return nil, fmt.Errorf("Error adding server %s to load balancer: %v", server.URL, err)
+		}
+	}
+	if configuration.Backends[backendName].HealthCheck != nil {
+		backendHealthCheck, err := healthcheck.NewBackendHealthCheck(backendName, configuration.Backends[backendName].HealthCheck, tlsConfig, drainer, weights)
+		if err != nil {
+			return nil, fmt.Errorf("Error creating health check: %v", err)
+		}
+		backendsHealthcheck[backendName] = backendHealthCheck
+	}
+	if outlierDetector != nil {
+		outlierDetector.SetLoadBalancer(drainer, weights)
+	}
+	if stickySession != nil {
+		stickySession.SetLoadBalancer(balancer)
+		stickySession.SetDrainer(drainer)
+		lb = stickySession
+	}
+	maxConns := configuration.Backends[backendName].MaxConn
+	if maxConns != nil && maxConns.Amount != 0 {
+		extractFunc, err := utils.NewExtractor(maxConns.ExtractorFunc)
+		if err != nil {
+			return nil, fmt.Errorf("Error creating connlimit: %v", err)
+		}
+		log.Debugf("Creating loadd-balancer connlimit")
+		lb, err = connlimit.New(lb, extractFunc, maxConns.Amount, connlimit.Logger(oxyLogger))
+		if err != nil {
+			return nil, fmt.Errorf("Error creating connlimit: %v", err)
+		}
+	}
+	// retry ?
+	if globalConfiguration.Retry != nil {
+		retries := len(configuration.Backends[backendName].Servers)
+		if globalConfiguration.Retry.Attempts > 0 {
+			retries = globalConfiguration.Retry.Attempts
+		}
+		lb = middlewares.NewRetry(retries, &middlewares.RetryPolicy{
+			Methods:          globalConfiguration.Retry.Methods,
+			InitialInterval:  time.Duration(globalConfiguration.Retry.InitialInterval),
+			MaxInterval:      time.Duration(globalConfiguration.Retry.MaxInterval),
+			BudgetRatio:      globalConfiguration.Retry.BudgetRatio,
+			BudgetMinRetries: globalConfiguration.Retry.BudgetMinRetries,
+		}, lb)
+		log.Debugf("Creating retries max attempts %d", retries)
+	}
+
+	// TODO make this configurable
+	var negroni = negroni.New()
+	if configuration.Backends[backendName].AuditTap != nil {
+		auditTapConfig := configuration.Backends[backendName].AuditTap
+		probe, err := audittap.NewAuditTap(auditTapConfig, backendName)
+		if err == nil {
+			negroni.Use(probe)
+			backendAuditTaps[backendName] = probe
+		}
+	}
+	if server.globalConfiguration.Web != nil && server.globalConfiguration.Web.Metrics != nil {
+		if server.globalConfiguration.Web.Metrics.Prometheus != nil {
+			metricsMiddlewareBackend := middlewares.NewMetricsWrapper(middlewares.NewPrometheus(backendName, server.globalConfiguration.Web.Metrics.Prometheus))
+			negroni.Use(metricsMiddlewareBackend)
+		}
+	}
+	if configuration.Backends[backendName].Buffering != nil {
+		log.Debugf("Creating buffering for backend %s", backendName)
+		negroni.Use(middlewares.NewBuffering(configuration.Backends[backendName].Buffering))
+	}
+	if configuration.Backends[backendName].CircuitBreaker != nil {
+		circuitBreakerConfig := configuration.Backends[backendName].CircuitBreaker
+		log.Debugf("Creating circuit breaker %s", circuitBreakerConfig.Expression)
+		options := []cbreaker.CircuitBreakerOption{cbreaker.Logger(oxyLogger)}
+		if fallback := circuitBreakerConfig.Fallback; fallback != nil {
+			if len(fallback.Backend) > 0 {
+				log.Debugf("Creating fallback backend %s of circuit breaker", fallback.Backend)
+				fallbackBackend, err := getBackend(fallback.Backend)
+				if err != nil {
+					return nil, fmt.Errorf("Error creating circuit breaker fallback: %v", err)
+				}
+				options = append(options, cbreaker.Fallback(fallbackBackend))
+			} else {
+				options = append(options, cbreaker.Fallback(middlewares.NewFallback(fallback)))
+			}
+		}
+		cbreaker, err := middlewares.NewCircuitBreaker(backendName, lb, circuitBreakerConfig.Expression, options...)
+		if err != nil {
+			return nil, fmt.Errorf("Error creating circuit breaker: %v", err)
+		}
+		states.circuitBreakers[backendKey{providerName, backendName}] = cbreaker
+		negroni.Use(cbreaker)
+	} else {
+		negroni.UseHandler(lb)
+	}
+	return negroni, nil
 }
 
 func (server *Server) wireFrontendBackend(serverRoute *serverRoute, handler http.Handler) {
@@ -830,6 +1200,25 @@ func (server *Server) buildDefaultHTTPRouter() *mux.Router {
 	return router
 }
 
+// wireNotFoundErrorPages replaces the 404 of the requests matching no frontend of an entry point by its error pages,
+// served from the backends of the frontends
+func wireNotFoundErrorPages(router *mux.Router, pages map[string]*types.ErrorPage, backends map[string]http.Handler) error {
+	errorPageBackends := make(map[string]http.Handler)
+	for _, errorPage := range pages {
+		if backend, ok := backends[errorPage.Backend]; ok {
+			errorPageBackends[errorPage.Backend] = backend
+		}
+	}
+	errorPages, err := middlewares.NewErrorPages(pages, errorPageBackends)
+	if err != nil {
+		return err
+	}
+	notFound := negroni.New(errorPages)
+	notFound.UseHandler(http.HandlerFunc(notFoundHandler))
+	router.NotFoundHandler = notFound
+	return nil
+}
+
 func getRoute(serverRoute *serverRoute, route *types.Route) error {
 	rules := Rules{route: serverRoute}
 	newRoute, err := rules.Parse(route.Rule)
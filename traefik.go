@@ -107,6 +107,8 @@ Complete documentation is available at https://traefik.io`,
 	f.AddParser(reflect.TypeOf(k8s.Namespaces{}), &k8s.Namespaces{})
 	f.AddParser(reflect.TypeOf([]acme.Domain{}), &acme.Domains{})
 	f.AddParser(reflect.TypeOf(types.Buckets{}), &types.Buckets{})
+	f.AddParser(reflect.TypeOf(RetryMethods{}), &RetryMethods{})
+	f.AddParser(reflect.TypeOf(types.Duration(0)), new(types.Duration))
 
 	//add commands
 	f.AddCommand(cmd.NewVersionCmd())
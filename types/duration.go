@@ -0,0 +1,54 @@
+package types
+
+import (
+	"encoding"
+	"strconv"
+	"time"
+)
+
+// Duration is a time.Duration that is written as "10s" or "1m30s" in the configuration.
+// A bare integer is read as a number of seconds.
+type Duration time.Duration
+
+var _ encoding.TextUnmarshaler = (*Duration)(nil)
+
+// UnmarshalText define how unmarshal in TOML parsing
+func (d *Duration) UnmarshalText(text []byte) error {
+	if seconds, err := strconv.ParseInt(string(text), 10, 64); err == nil {
+		*d = Duration(time.Duration(seconds) * time.Second)
+		return nil
+	}
+	duration, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(duration)
+	return nil
+}
+
+var _ encoding.TextMarshaler = (*Duration)(nil)
+
+// MarshalText encodes the receiver into UTF-8-encoded text and returns the result.
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// String returns the duration formatted like "1m30s"
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
+
+// Set parses a duration given as a flag, as UnmarshalText does
+func (d *Duration) Set(value string) error {
+	return d.UnmarshalText([]byte(value))
+}
+
+// Get returns the duration
+func (d *Duration) Get() interface{} {
+	return Duration(*d)
+}
+
+// SetValue sets the duration
+func (d *Duration) SetValue(val interface{}) {
+	*d = Duration(val.(Duration))
+}
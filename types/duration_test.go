@@ -0,0 +1,27 @@
+package types
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestDuration_UnmarshalText(t *testing.T) {
+	cases := []struct {
+		in  string
+		exp time.Duration
+	}{
+		{"10", 10 * time.Second},
+		{"250ms", 250 * time.Millisecond},
+		{"1m30s", 90 * time.Second},
+	}
+
+	for _, c := range cases {
+		var d Duration
+		assert.NoError(t, d.UnmarshalText([]byte(c.in)))
+		assert.Equal(t, c.exp, time.Duration(d))
+	}
+
+	var d Duration
+	assert.Error(t, d.UnmarshalText([]byte("soon")))
+}
@@ -13,12 +13,15 @@ import (
 
 // Backend holds backend configuration.
 type Backend struct {
-	Servers        map[string]Server `json:"servers,omitempty"`
-	CircuitBreaker *CircuitBreaker   `json:"circuitBreaker,omitempty"`
-	LoadBalancer   *LoadBalancer     `json:"loadBalancer,omitempty"`
-	MaxConn        *MaxConn          `json:"maxConn,omitempty"`
-	HealthCheck    *HealthCheck      `json:"healthCheck,omitempty"`
-	AuditTap       *AuditTap         `json:"auditTap,omitempty"`
+	Servers          map[string]Server `json:"servers,omitempty"`
+	CircuitBreaker   *CircuitBreaker   `json:"circuitBreaker,omitempty"`
+	LoadBalancer     *LoadBalancer     `json:"loadBalancer,omitempty"`
+	MaxConn          *MaxConn          `json:"maxConn,omitempty"`
+	HealthCheck      *HealthCheck      `json:"healthCheck,omitempty"`
+	OutlierDetection *OutlierDetection `json:"outlierDetection,omitempty"`
+	AuditTap         *AuditTap         `json:"auditTap,omitempty"`
+	Buffering        *Buffering        `json:"buffering,omitempty"`
+	Transport        *Transport        `json:"transport,omitempty"`
 }
 
 // MaxConn holds maximum connection configuration
@@ -27,20 +30,113 @@ type MaxConn struct {
 	ExtractorFunc string `json:"extractorFunc,omitempty"`
 }
 
+// Transport holds the settings of the connections of a backend to its servers, instead of the global ones
+type Transport struct {
+	// timeout of the connection to a server, 30s if empty
+	DialTimeout Duration `json:"dialTimeout,omitempty"`
+	// timeout of the TLS handshake with a server, 10s if empty
+	TLSHandshakeTimeout Duration `json:"tlsHandshakeTimeout,omitempty"`
+	// time waiting for the response headers of a server once the request is sent, unlimited if empty
+	ResponseHeaderTimeout Duration `json:"responseHeaderTimeout,omitempty"`
+	// time an idle keep-alive connection to a server is kept, 90s if empty
+	IdleConnTimeout Duration `json:"idleConnTimeout,omitempty"`
+	// idle keep-alive connections kept to each server, the global MaxIdleConnsPerHost if 0
+	MaxIdleConnsPerHost int `json:"maxIdleConnsPerHost,omitempty"`
+	// connections opened at most to each server, the requests beyond waiting for one to close, unlimited if 0
+	MaxConnsPerHost int `json:"maxConnsPerHost,omitempty"`
+	// TLS of the connections to the https servers, e.g. a client certificate, the global InsecureSkipVerify if nil
+	TLS *ClientTLS `json:"tls,omitempty"`
+}
+
 // LoadBalancer holds load balancing configuration.
 type LoadBalancer struct {
-	Method string `json:"method,omitempty"`
-	Sticky bool   `json:"sticky,omitempty"`
+	Method       string        `json:"method,omitempty"`
+	Sticky       bool          `json:"sticky,omitempty"`
+	StickyCookie *StickyCookie `json:"stickyCookie,omitempty"`
+	Hash         *HashKey      `json:"hash,omitempty"`
+	// duration over which the weight of a new or recovered server ramps up linearly from a tenth of its weight, none if empty
+	SlowStart Duration `json:"slowStart,omitempty"`
+}
+
+// StickyCookie holds the cookie of the sticky sessions of a backend, whose value is an opaque hash of the server
+type StickyCookie struct {
+	// _TRAEFIK_BACKEND_ and a hash of the backend name if empty, so that the backends of a domain do not collide
+	Name     string `json:"name,omitempty"`
+	Secure   bool   `json:"secure,omitempty"`
+	HTTPOnly bool   `json:"httpOnly,omitempty"`
+	// Lax, Strict or None, not sent if empty
+	SameSite string `json:"sameSite,omitempty"`
+	// / if empty
+	Path   string `json:"path,omitempty"`
+	Domain string `json:"domain,omitempty"`
+	// lifetime of the cookie in seconds, a session cookie if 0
+	MaxAge int `json:"maxAge,omitempty"`
+}
+
+// HashKey holds the key of the hash load balancer method: the first set of a header, cookie, query parameter or the path.
+// The requests without it, or all of them if none is set, are balanced by client IP.
+type HashKey struct {
+	Header string `json:"header,omitempty"`
+	Cookie string `json:"cookie,omitempty"`
+	Query  string `json:"query,omitempty"`
+	Path   bool   `json:"path,omitempty"`
+	// points of a server on the hash ring by unit of weight, 100 if 0
+	VirtualNodes int `json:"virtualNodes,omitempty"`
 }
 
 // CircuitBreaker holds circuit breaker configuration.
 type CircuitBreaker struct {
-	Expression string `json:"expression,omitempty"`
+	Expression string    `json:"expression,omitempty"`
+	Fallback   *Fallback `json:"fallback,omitempty"`
+}
+
+// Fallback holds the response to the requests of a tripped circuit breaker:
+// a static response, or the response of another backend if Backend is set
+type Fallback struct {
+	StatusCode  int    `json:"statusCode,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	Body        string `json:"body,omitempty"`
+	Backend     string `json:"backend,omitempty"`
 }
 
 // HealthCheck holds HealthCheck configuration
 type HealthCheck struct {
+	// http, tcp (a connection to the server) or grpc (the grpc.health.v1 protocol), http if empty
+	Type string `json:"type,omitempty"`
+	// path requested on the servers, e.g. /health
 	URL string `json:"url,omitempty"`
+	// service checked with the grpc type, the whole server if empty
+	Service string `json:"service,omitempty"`
+	// time between two checks of a server, 30s if empty
+	Interval Duration `json:"interval,omitempty"`
+	// 5s if empty
+	Timeout Duration `json:"timeout,omitempty"`
+	// status codes or ranges of a healthy server, e.g. 200-399, 200 if empty. Redirects are not followed.
+	Status []string `json:"status,omitempty"`
+	// headers of the check requests
+	Headers map[string]string `json:"headers,omitempty"`
+	// Host header of the check requests, the host of the server if empty
+	Hostname string `json:"hostname,omitempty"`
+	// port and scheme of the check requests, those of the server if empty
+	Port   int    `json:"port,omitempty"`
+	Scheme string `json:"scheme,omitempty"`
+	// consecutive successful checks before a down server is up again, 1 if 0
+	Rise int `json:"rise,omitempty"`
+	// consecutive failed checks before an up server is down, 1 if 0
+	Fall int `json:"fall,omitempty"`
+}
+
+// OutlierDetection holds passive health check configuration: the servers failing live requests are ejected from
+// the load balancer of their backend for a while
+type OutlierDetection struct {
+	// consecutive 5xx responses, including connection errors, ejecting a server, 5 if 0
+	ConsecutiveErrors int `json:"consecutiveErrors,omitempty"`
+	// duration of the first ejection of a server, doubled at each new ejection, 30s if empty
+	BaseEjectionTime Duration `json:"baseEjectionTime,omitempty"`
+	// longest ejection, 5m if empty. A server not ejected for that long gets the base ejection time again.
+	MaxEjectionTime Duration `json:"maxEjectionTime,omitempty"`
+	// highest percentage of the servers of the backend ejected at once, 50 if 0
+	MaxEjectionPercent int `json:"maxEjectionPercent,omitempty"`
 }
 
 // AuditTap holds AuditTap configuration
@@ -59,6 +155,20 @@ type AuditTap struct {
 	SizeThreshold string `json:"sizeThreshold,omitempty"`
 }
 
+// Buffering holds request and response buffering configuration
+type Buffering struct {
+	// bigger request bodies are answered with a 413, unlimited if 0
+	MaxRequestBodyBytes int64 `json:"maxRequestBodyBytes,omitempty"`
+	// request bodies are written to a temporary file beyond this size, 1MB if 0
+	MemRequestBodyBytes int64 `json:"memRequestBodyBytes,omitempty"`
+	// bigger response bodies are answered with a 500, unlimited if 0
+	MaxResponseBodyBytes int64 `json:"maxResponseBodyBytes,omitempty"`
+	// response bodies are written to a temporary file beyond this size, 1MB if 0
+	MemResponseBodyBytes int64 `json:"memResponseBodyBytes,omitempty"`
+	// buffer the whole response before sending it to the client
+	BufferResponses bool `json:"bufferResponses,omitempty"`
+}
+
 // Server holds server configuration.
 type Server struct {
 	URL    string `json:"url,omitempty"`
@@ -72,12 +182,142 @@ type Route struct {
 
 // Frontend holds frontend configuration.
 type Frontend struct {
-	EntryPoints    []string         `json:"entryPoints,omitempty"`
-	Backend        string           `json:"backend,omitempty"`
-	Routes         map[string]Route `json:"routes,omitempty"`
-	PassHostHeader bool             `json:"passHostHeader,omitempty"`
-	Priority       int              `json:"priority"`
-	RequestHeader  bool             `json:"requestHeader,omitempty"`
+	EntryPoints     []string              `json:"entryPoints,omitempty"`
+	Backend         string                `json:"backend,omitempty"`
+	Routes          map[string]Route      `json:"routes,omitempty"`
+	PassHostHeader  bool                  `json:"passHostHeader,omitempty"`
+	Priority        int                   `json:"priority"`
+	RequestHeader   bool                  `json:"requestHeader,omitempty"`
+	RequestID       *RequestID            `json:"requestID,omitempty"`
+	RateLimit       *RateLimit            `json:"rateLimit,omitempty"`
+	IPFilter        *IPFilter             `json:"ipFilter,omitempty"`
+	Headers         *Headers              `json:"headers,omitempty"`
+	SecurityHeaders *SecurityHeaders      `json:"securityHeaders,omitempty"`
+	CORS            *CORS                 `json:"cors,omitempty"`
+	Auth            *Auth                 `json:"auth,omitempty"`
+	Mirror          *Mirror               `json:"mirror,omitempty"`
+	Split           *Split                `json:"split,omitempty"`
+	Errors          map[string]*ErrorPage `json:"errors,omitempty"`
+}
+
+// ErrorPage holds a custom error page of a frontend, served from a backend in place of the responses with a status code in one of the ranges
+type ErrorPage struct {
+	// status codes or ranges of status codes, e.g. 404 or 500-599
+	Status []string `json:"status,omitempty"`
+	// backend serving the error page
+	Backend string `json:"backend,omitempty"`
+	// path of the error page, {status} being replaced by the status code
+	Query string `json:"query,omitempty"`
+}
+
+// Split holds the weighted backends sharing the requests of a frontend, e.g. for canary releases
+type Split struct {
+	// weight of each backend, the backend of the frontend receiving the requests if they are all 0
+	Backends map[string]int `json:"backends,omitempty"`
+	// name of the cookie pinning a client to a backend
+	Cookie string `json:"cookie,omitempty"`
+	// name of the header whose value pins a client to a backend
+	Header string `json:"header,omitempty"`
+}
+
+// Mirror holds the traffic mirroring configuration of a frontend
+type Mirror struct {
+	// backend receiving a copy of the requests, its responses being discarded
+	Backend string `json:"backend,omitempty"`
+	// percentage of the requests mirrored, 100 if unset and none if 0
+	Percent *int `json:"percent,omitempty"`
+	// requests with a bigger body are not mirrored, 1MB if 0
+	MaxBodyBytes int64 `json:"maxBodyBytes,omitempty"`
+}
+
+// CORS holds the Cross-Origin Resource Sharing configuration of a frontend
+type CORS struct {
+	// exact origins or globs, e.g. https://*.example.com, "*" allowing any origin
+	AllowedOrigins []string `json:"allowedOrigins,omitempty"`
+	// GET, HEAD and POST if empty
+	AllowedMethods   []string `json:"allowedMethods,omitempty"`
+	AllowedHeaders   []string `json:"allowedHeaders,omitempty"`
+	ExposedHeaders   []string `json:"exposedHeaders,omitempty"`
+	AllowCredentials bool     `json:"allowCredentials,omitempty"`
+	// how long, in seconds, the result of a preflight request can be cached
+	MaxAge int `json:"maxAge,omitempty"`
+}
+
+// SecurityHeaders holds the security headers set on the responses of a frontend and its SSL redirection
+type SecurityHeaders struct {
+	// redirect HTTP requests to HTTPS, permanently unless SSLTemporaryRedirect is set
+	SSLRedirect          bool `json:"sslRedirect,omitempty"`
+	SSLTemporaryRedirect bool `json:"sslTemporaryRedirect,omitempty"`
+	// host to redirect to, the requested host being used if empty
+	SSLHost string `json:"sslHost,omitempty"`
+	// headers telling that a request was received as HTTPS by a proxy, e.g. X-Forwarded-Proto: https
+	SSLProxyHeaders      map[string]string `json:"sslProxyHeaders,omitempty"`
+	STSSeconds           int64             `json:"stsSeconds,omitempty"`
+	STSIncludeSubdomains bool              `json:"stsIncludeSubdomains,omitempty"`
+	STSPreload           bool              `json:"stsPreload,omitempty"`
+	// send the Strict-Transport-Security header on HTTP responses as well
+	ForceSTSHeader          bool   `json:"forceSTSHeader,omitempty"`
+	FrameDeny               bool   `json:"frameDeny,omitempty"`
+	CustomFrameOptionsValue string `json:"customFrameOptionsValue,omitempty"`
+	ContentTypeNosniff      bool   `json:"contentTypeNosniff,omitempty"`
+	ContentSecurityPolicy   string `json:"contentSecurityPolicy,omitempty"`
+	ReferrerPolicy          string `json:"referrerPolicy,omitempty"`
+	// disable all of the above, e.g. on a development environment
+	IsDevelopment bool `json:"isDevelopment,omitempty"`
+}
+
+// Headers holds the headers manipulated on the requests sent to the backend and on the responses returned to the client
+type Headers struct {
+	Request  *HeaderOperations `json:"request,omitempty"`
+	Response *HeaderOperations `json:"response,omitempty"`
+}
+
+// HeaderOperations holds the headers to remove, then to overwrite and to add.
+// Values can contain the {client.ip}, {request.id}, {request.host} and {request.scheme} placeholders.
+type HeaderOperations struct {
+	Remove []string          `json:"remove,omitempty"`
+	Set    map[string]string `json:"set,omitempty"`
+	Add    map[string]string `json:"add,omitempty"`
+}
+
+// IPFilter holds the source ranges (IPv4/IPv6 IPs or CIDRs) allowed or denied on an entrypoint or a frontend.
+// When both lists are set, a request must match the whitelist and not match the blacklist.
+type IPFilter struct {
+	Whitelist []string `json:"whitelist,omitempty"`
+	Blacklist []string `json:"blacklist,omitempty"`
+	// when positive, the client IP is the Nth X-Forwarded-For entry starting from the right
+	// instead of the IP of the peer, e.g. 1 behind a single trusted load balancer
+	ForwardedForDepth int `json:"forwardedForDepth,omitempty"`
+}
+
+// RateLimit holds rate limiting configuration for a frontend
+type RateLimit struct {
+	// what a client is identified by: "client.ip" (default), "request.host",
+	// "request.header.<name>" or "auth.user"
+	ExtractorFunc string `json:"extractorFunc,omitempty"`
+	// proxies (IPs or CIDRs) whose X-Forwarded-For entries are trusted
+	TrustedProxies []string `json:"trustedProxies,omitempty"`
+	// every rate of the set must allow a request for it to go through
+	RateSet map[string]*Rate `json:"rateSet,omitempty"`
+}
+
+// Rate holds a token bucket configuration: Average requests per Period, with bursts of up to Burst requests
+type Rate struct {
+	Period  Duration `json:"period,omitempty"`
+	Average int64    `json:"average,omitempty"`
+	Burst   int64    `json:"burst,omitempty"`
+}
+
+// RequestID holds request ID configuration
+type RequestID struct {
+	// request and response header carrying the ID (default: "X-Request-ID")
+	HeaderName string `json:"headerName,omitempty"`
+	// prepended to every generated ID (optional)
+	Prefix string `json:"prefix,omitempty"`
+	// ID generator: "uuid" (v4, default) or "ulid"
+	Generator string `json:"generator,omitempty"`
+	// keep an ID supplied by the client instead of generating a new one
+	TrustIncoming bool `json:"trustIncoming,omitempty"`
 }
 
 // LoadBalancerMethod holds the method of load balancing to use.
@@ -88,11 +328,20 @@ const (
 	Wrr LoadBalancerMethod = iota
 	// Drr = Dynamic Round Robin
 	Drr
+	// LeastConn = Least Connections
+	LeastConn
+	// P2C = Power of Two Choices, with the latency of the servers
+	P2C
+	// Hash = Consistent Hashing of a key of the requests
+	Hash
 )
 
 var loadBalancerMethodNames = []string{
 	"Wrr",
 	"Drr",
+	"LeastConn",
+	"P2C",
+	"Hash",
 }
 
 // NewLoadBalancerMethod create a new LoadBalancerMethod from a given LoadBalancer.
@@ -250,10 +499,12 @@ type Cluster struct {
 	Store *Store
 }
 
-// Auth holds authentication configuration (BASIC, DIGEST, users)
+// Auth holds authentication configuration (BASIC, DIGEST, users, forward, JWT)
 type Auth struct {
 	Basic       *Basic
 	Digest      *Digest
+	Forward     *Forward
+	JWT         *JWT
 	HeaderField string
 }
 
@@ -262,12 +513,43 @@ type Users []string
 
 // Basic HTTP basic authentication
 type Basic struct {
-	Users `mapstructure:","`
+	Users     `mapstructure:","`
+	UsersFile string
 }
 
 // Digest HTTP authentication
 type Digest struct {
-	Users `mapstructure:","`
+	Users     `mapstructure:","`
+	UsersFile string
+}
+
+// Forward authentication, delegated to an external service
+type Forward struct {
+	// URL of the auth service, e.g. http://auth.example.com/verify
+	Address string
+	// headers of the request copied to the auth request, all of them if empty
+	AuthRequestHeaders []string
+	// headers of a 2xx auth response copied to the request sent to the backend
+	AuthResponseHeaders []string
+	// 30s if empty
+	Timeout Duration
+	TLS     *ClientTLS
+}
+
+// JWT authentication, validating the bearer tokens of the requests
+type JWT struct {
+	// path or URL of the JSON Web Key Set holding the RS256, ES256 and HS256 keys
+	JWKS string
+	// how often the keys are loaded again, 1h if empty. An unknown key ID loads them again too.
+	JWKSRefreshInterval Duration
+	Issuer              string
+	Audience            string
+	// scopes the "scope" or "scp" claim must all contain
+	RequiredScopes []string
+	// claims that must have the given value, or contain it for lists
+	RequiredClaims map[string]string
+	// headers of the request sent to the backend set to a claim, claim name to header name
+	ForwardClaims map[string]string
 }
 
 // CanonicalDomain returns a lower case domain with trim space
@@ -6,11 +6,13 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"runtime"
 
 	"github.com/codegangsta/negroni"
 	"github.com/containous/mux"
 	"github.com/containous/traefik/autogen"
+	"github.com/containous/traefik/healthcheck"
 	"github.com/containous/traefik/log"
 	"github.com/containous/traefik/middlewares"
 	"github.com/containous/traefik/safe"
@@ -103,6 +105,8 @@ func (provider *WebProvider) Provide(configurationChan chan<- types.ConfigMessag
 	systemRouter.Methods("GET").Path("/api/providers/{provider}/backends/{backend}").HandlerFunc(provider.getBackendHandler)
 	systemRouter.Methods("GET").Path("/api/providers/{provider}/backends/{backend}/servers").HandlerFunc(provider.getServersHandler)
 	systemRouter.Methods("GET").Path("/api/providers/{provider}/backends/{backend}/servers/{server}").HandlerFunc(provider.getServerHandler)
+	systemRouter.Methods("PUT").Path("/api/providers/{provider}/backends/{backend}/servers/{server}/drain").HandlerFunc(provider.putServerDrainHandler(true))
+	systemRouter.Methods("PUT").Path("/api/providers/{provider}/backends/{backend}/servers/{server}/undrain").HandlerFunc(provider.putServerDrainHandler(false))
 	systemRouter.Methods("GET").Path("/api/providers/{provider}/frontends").HandlerFunc(provider.getFrontendsHandler)
 	systemRouter.Methods("GET").Path("/api/providers/{provider}/frontends/{frontend}").HandlerFunc(provider.getFrontendHandler)
 	systemRouter.Methods("GET").Path("/api/providers/{provider}/frontends/{frontend}/routes").HandlerFunc(provider.getRoutesHandler)
@@ -190,12 +194,66 @@ func (provider *WebProvider) getProviderHandler(response http.ResponseWriter, re
 	}
 }
 
+// backendRepresentation is the API representation of a backend, with the state of its circuit breaker and servers
+type backendRepresentation struct {
+	*types.Backend
+	Servers             map[string]*serverRepresentation `json:"servers,omitempty"`
+	CircuitBreakerState string                           `json:"circuitBreakerState,omitempty"`
+}
+
+func newBackendRepresentation(states *backendStates, providerID string, backendID string, backend *types.Backend) *backendRepresentation {
+	representation := &backendRepresentation{Backend: backend}
+	if cb, ok := states.circuitBreakers[backendKey{providerID, backendID}]; ok {
+		representation.CircuitBreakerState = cb.State()
+	}
+	if backend.Servers != nil {
+		representation.Servers = newServerRepresentations(states, providerID, backendID, backend.Servers)
+	}
+	return representation
+}
+
+// serverRepresentation is the API representation of a server, with its health state if its backend has a health check,
+// its ejection if it is ejected by the outlier detection of its backend, and its drain if it is drained
+type serverRepresentation struct {
+	types.Server
+	Health   *healthcheck.ServerHealth `json:"health,omitempty"`
+	Ejection *middlewares.Ejection     `json:"ejection,omitempty"`
+	Drain    *middlewares.Drain        `json:"drain,omitempty"`
+}
+
+func newServerRepresentation(states *backendStates, providerID string, backendID string, server types.Server) *serverRepresentation {
+	representation := &serverRepresentation{
+		Server: server,
+		Health: healthcheck.GetHealthCheck().GetServerHealth(backendID, server.URL),
+		Drain:  middlewares.GetServerDrain(providerID, backendID, server.URL),
+	}
+	if o, ok := states.outlierDetectors[backendKey{providerID, backendID}]; ok {
+		if u, err := url.Parse(server.URL); err == nil {
+			representation.Ejection = o.Ejection(u)
+		}
+	}
+	return representation
+}
+
+func newServerRepresentations(states *backendStates, providerID string, backendID string, servers map[string]types.Server) map[string]*serverRepresentation {
+	representations := make(map[string]*serverRepresentation)
+	for serverID, server := range servers {
+		representations[serverID] = newServerRepresentation(states, providerID, backendID, server)
+	}
+	return representations
+}
+
 func (provider *WebProvider) getBackendsHandler(response http.ResponseWriter, request *http.Request) {
 	vars := mux.Vars(request)
 	providerID := vars["provider"]
 	currentConfigurations := provider.server.currentConfigurations.Get().(configs)
+	states := provider.server.backendStates.Get().(*backendStates)
 	if provider, ok := currentConfigurations[providerID]; ok {
-		templatesRenderer.JSON(response, http.StatusOK, provider.Backends)
+		backends := make(map[string]*backendRepresentation)
+		for backendID, backend := range provider.Backends {
+			backends[backendID] = newBackendRepresentation(states, providerID, backendID, backend)
+		}
+		templatesRenderer.JSON(response, http.StatusOK, backends)
 	} else {
 		http.NotFound(response, request)
 	}
@@ -206,9 +264,10 @@ func (provider *WebProvider) getBackendHandler(response http.ResponseWriter, req
 	providerID := vars["provider"]
 	backendID := vars["backend"]
 	currentConfigurations := provider.server.currentConfigurations.Get().(configs)
+	states := provider.server.backendStates.Get().(*backendStates)
 	if provider, ok := currentConfigurations[providerID]; ok {
 		if backend, ok := provider.Backends[backendID]; ok {
-			templatesRenderer.JSON(response, http.StatusOK, backend)
+			templatesRenderer.JSON(response, http.StatusOK, newBackendRepresentation(states, providerID, backendID, backend))
 			return
 		}
 	}
@@ -220,9 +279,10 @@ func (provider *WebProvider) getServersHandler(response http.ResponseWriter, req
 	providerID := vars["provider"]
 	backendID := vars["backend"]
 	currentConfigurations := provider.server.currentConfigurations.Get().(configs)
+	states := provider.server.backendStates.Get().(*backendStates)
 	if provider, ok := currentConfigurations[providerID]; ok {
 		if backend, ok := provider.Backends[backendID]; ok {
-			templatesRenderer.JSON(response, http.StatusOK, backend.Servers)
+			templatesRenderer.JSON(response, http.StatusOK, newServerRepresentations(states, providerID, backendID, backend.Servers))
 			return
 		}
 	}
@@ -235,10 +295,11 @@ func (provider *WebProvider) getServerHandler(response http.ResponseWriter, requ
 	backendID := vars["backend"]
 	serverID := vars["server"]
 	currentConfigurations := provider.server.currentConfigurations.Get().(configs)
+	states := provider.server.backendStates.Get().(*backendStates)
 	if provider, ok := currentConfigurations[providerID]; ok {
 		if backend, ok := provider.Backends[backendID]; ok {
 			if server, ok := backend.Servers[serverID]; ok {
-				templatesRenderer.JSON(response, http.StatusOK, server)
+				templatesRenderer.JSON(response, http.StatusOK, newServerRepresentation(states, providerID, backendID, server))
 				return
 			}
 		}
@@ -246,6 +307,43 @@ func (provider *WebProvider) getServerHandler(response http.ResponseWriter, requ
 	http.NotFound(response, request)
 }
 
+// putServerDrainHandler returns the handler draining a server, or undraining it if drain is false.
+// The server stays drained across the configuration reloads of its provider.
+func (provider *WebProvider) putServerDrainHandler(drain bool) http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		if provider.ReadOnly {
+			response.WriteHeader(http.StatusForbidden)
+			fmt.Fprintf(response, "REST API is in read-only mode")
+			return
+		}
+		vars := mux.Vars(request)
+		providerID := vars["provider"]
+		backendID := vars["backend"]
+		serverID := vars["server"]
+		currentConfigurations := provider.server.currentConfigurations.Get().(configs)
+		states := provider.server.backendStates.Get().(*backendStates)
+		if provider, ok := currentConfigurations[providerID]; ok {
+			if backend, ok := provider.Backends[backendID]; ok {
+				if server, ok := backend.Servers[serverID]; ok {
+					var err error
+					if drain {
+						err = middlewares.DrainServer(providerID, backendID, server.URL)
+					} else {
+						err = middlewares.UndrainServer(providerID, backendID, server.URL)
+					}
+					if err != nil {
+						http.Error(response, err.Error(), http.StatusBadRequest)
+						return
+					}
+					templatesRenderer.JSON(response, http.StatusOK, newServerRepresentation(states, providerID, backendID, server))
+					return
+				}
+			}
+		}
+		http.NotFound(response, request)
+	}
+}
+
 func (provider *WebProvider) getFrontendsHandler(response http.ResponseWriter, request *http.Request) {
 	vars := mux.Vars(request)
 	providerID := vars["provider"]